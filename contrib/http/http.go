@@ -0,0 +1,44 @@
+// Package http registers describers for net/http types when imported:
+//
+//	import _ "github.com/kstenerud/go-describe/contrib/http"
+//
+// Requests are described by their method and URL, and responses by their
+// status, rather than by their (very large) internal structure:
+//
+//	*http.Request<GET http://example.com/path>
+//	*http.Response<200 OK>
+//	*http.Cookie<session=abc; Path=/>
+package http
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"reflect"
+
+	"github.com/kstenerud/go-describe"
+)
+
+func init() {
+	describe.SetCustomDescriber(reflect.TypeOf(nethttp.Request{}), describeRequest)
+	describe.SetCustomDescriber(reflect.TypeOf(nethttp.Response{}), describeResponse)
+	describe.SetCustomDescriber(reflect.TypeOf(nethttp.Cookie{}), describeCookie)
+}
+
+func describeRequest(v reflect.Value) string {
+	request := v.Interface().(nethttp.Request)
+	url := ""
+	if request.URL != nil {
+		url = request.URL.String()
+	}
+	return fmt.Sprintf("%v<%v %v>", v.Type(), request.Method, url)
+}
+
+func describeResponse(v reflect.Value) string {
+	response := v.Interface().(nethttp.Response)
+	return fmt.Sprintf("%v<%v>", v.Type(), response.Status)
+}
+
+func describeCookie(v reflect.Value) string {
+	cookie := v.Interface().(nethttp.Cookie)
+	return fmt.Sprintf("%v<%v>", v.Type(), cookie.String())
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	nethttp "net/http"
+	"net/url"
+	"testing"
+
+	"github.com/kstenerud/go-describe"
+)
+
+func TestRequest(t *testing.T) {
+	u, _ := url.Parse("http://example.com/path?q=1")
+	expected := `*http.Request<GET http://example.com/path?q=1>`
+	actual := describe.D(&nethttp.Request{Method: "GET", URL: u})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestResponse(t *testing.T) {
+	expected := `http.Response<404 Not Found>`
+	actual := describe.D(nethttp.Response{Status: "404 Not Found", StatusCode: 404})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCookie(t *testing.T) {
+	expected := `*http.Cookie<session=abc; Path=/>`
+	actual := describe.D(&nethttp.Cookie{Name: "session", Value: "abc", Path: "/"})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -0,0 +1,48 @@
+// Package sql registers describers for database/sql types when imported:
+//
+//	import _ "github.com/kstenerud/go-describe/contrib/sql"
+//
+// Nullable values are described by their value (or NULL), and connection pools
+// by their statistics, rather than by their internal structure:
+//
+//	sql.NullString<"abc">
+//	sql.NullInt64<NULL>
+//	*sql.DB<open=3 in_use=1 idle=2 wait_count=0>
+package sql
+
+import (
+	databasesql "database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/kstenerud/go-describe"
+)
+
+func init() {
+	registerNullTypes(
+		databasesql.NullString{},
+		databasesql.NullInt64{},
+		databasesql.NullFloat64{},
+		databasesql.NullBool{},
+	)
+}
+
+// Register describers for nullable types, which are structs made of a value
+// field followed by a Valid field.
+func registerNullTypes(examples ...interface{}) {
+	for _, example := range examples {
+		describe.SetContextDescriber(reflect.TypeOf(example), describeNullType)
+	}
+}
+
+// The value is described via the context, so that it gets the same options as
+// the rest of the description.
+func describeNullType(ctx *describe.DescribeContext, v reflect.Value) {
+	fmt.Fprintf(ctx, "%v<", v.Type())
+	if v.FieldByName("Valid").Bool() {
+		ctx.Describe(v.Field(0))
+	} else {
+		ctx.WriteString("NULL")
+	}
+	ctx.WriteString(">")
+}
@@ -0,0 +1,25 @@
+// +build go1.11
+
+package sql
+
+import (
+	databasesql "database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/kstenerud/go-describe"
+)
+
+func init() {
+	describe.SetCustomDescriber(reflect.TypeOf(databasesql.DB{}), describeDB)
+}
+
+func describeDB(v reflect.Value) string {
+	// DB contains locks, so it must not be copied.
+	if !v.CanAddr() {
+		return fmt.Sprintf("%v<>", v.Type())
+	}
+	stats := v.Addr().Interface().(*databasesql.DB).Stats()
+	return fmt.Sprintf("%v<open=%v in_use=%v idle=%v wait_count=%v>",
+		v.Type(), stats.OpenConnections, stats.InUse, stats.Idle, stats.WaitCount)
+}
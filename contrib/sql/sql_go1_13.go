@@ -0,0 +1,14 @@
+// +build go1.13
+
+package sql
+
+import (
+	databasesql "database/sql"
+)
+
+func init() {
+	registerNullTypes(
+		databasesql.NullInt32{},
+		databasesql.NullTime{},
+	)
+}
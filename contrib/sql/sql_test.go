@@ -0,0 +1,42 @@
+package sql
+
+import (
+	databasesql "database/sql"
+	"testing"
+
+	"github.com/kstenerud/go-describe"
+)
+
+func TestNullTypes(t *testing.T) {
+	expected := `sql.NullString<"abc">`
+	actual := describe.D(databasesql.NullString{String: "abc", Valid: true})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `sql.NullInt64<NULL>`
+	actual = describe.D(databasesql.NullInt64{Int64: 5})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `sql.NullString<"ab…"(len=6)>`
+	actual = describe.DescribeOpts(databasesql.NullString{String: "abcdef", Valid: true}, describe.WithMaxStringLength(2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `sql.NullBool[sql.NullBool<true> sql.NullBool<NULL>]`
+	actual = describe.D([]databasesql.NullBool{{Bool: true, Valid: true}, {}})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDB(t *testing.T) {
+	expected := `*sql.DB<open=0 in_use=0 idle=0 wait_count=0>`
+	actual := describe.D(&databasesql.DB{})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -17,6 +17,9 @@
 // * Slices and arrays of unsigned int types are printed as hex
 // * Maps begin with `key_type:value_type`, with elements enclosed in `{}`.
 //   Key-value pairs separated by `=`
+// * Maps used as sets (`map[T]struct{}`, or `map[T]bool` with all values true)
+//   are printed as `set[T]`, with keys enclosed in `{}`. Example:
+//   `set[string]{"a" "b"}`
 // * Structs are preceded by a type, with elements enclosed in `<>`.
 //   Field-value pairs are separated by `=`
 // * Functions begin with `func`, with in and out params enclosed in `()`.
@@ -24,29 +27,65 @@
 // * Nil functions begin with `nilfunc`. Example: `nilfunc(int)(string)`
 // * Unidirectional channels are printed as `<-chan type` and `chan<- type`
 // * Bidirectional channels are printed as `chan<sometype>`
+// * If `EnableChannelLengths` is set, buffered channels are followed by
+//   `(len=x cap=y)`
 // * Uintptr and UnsafePointer are printed as hex, in the width of the host system
+// * Numbers outside of the ranges set using `WithValidRange()` or
+//   `WithValidFieldRange()` are prefixed with `!`
 // * Invalid values are printed as `invalid`
+// * `reflect.Value` (including those within slices and maps) prints the value it
+//   holds within `reflect.Value<>`, and `reflect.Type` the type name within
+//   `reflect.Type<>`
+// * Non-zero values with a `String()` method (on either a value or pointer
+//   receiver) print a type name, then the result of `String()` within `<>`
+// * Errors that wrap multiple errors (`Unwrap() []error`, as produced by
+//   `errors.Join()`) print a type name, then the wrapped errors within `<>`
 // * Custom describers by convention print a type name, then a description within
 //   `<>`. Example: `url.URL<http://xyz.com>`
 // * Duplicate and cyclic data will be marked as follows:
 //   - The first instance is prefixed by a unique numeric reference ID, then `~`
 //   - Further instances are replaced by `$`, then the referenced ID
+//   - References to structs with a field tagged `describe:"id"` are followed
+//     by that field's value in `()`. Example: `$1("alice")`
+//   - Custom describers and `String()` methods that describe values leading
+//     back to the object they're describing get `$^` in place of that object
+// * In multiline mode, map keys that span multiple lines are followed by ` =`,
+//   with the value indented beneath them
+//
+// Describing never panics (unless DebugPanics is set) and never produces an
+// empty description, no matter what it's given: invalid reflect.Values, nil
+// pointers, maps, funcs and channels, unexported fields, unsafe pointers,
+// cyclic or very deep data, and user code (String() methods, custom
+// describers) that panics or returns nothing. This makes it safe to use in
+// panic handlers. Problems are described inline instead, for example
+// `panic(...)` for user code that panicked.
 //
 // Note: Only data is printed; type-specific things such as methods are not.
 //
+// Note: Output never depends on the system locale. Numbers always use `.` as
+//       the decimal separator, and times use English month and day names.
+//
 // Note: describe uses the `unsafe` package to expose unexported
-//       `reflect.Value` and `reflect.Type` objects. This functionality can
-//       be disabled by compiling with `-tags safe`, or by setting
-//       `describe.EnableUnsafeOperations` to `false`. It will be
+//       `reflect.Value` and `reflect.Type` objects, and to pass values in
+//       unexported fields to `String()` methods and custom describers. This
+//       functionality can be disabled by compiling with `-tags safe`, or by
+//       setting `describe.EnableUnsafeOperations` to `false` (or for a single
+//       Describer using `WithUnsafeOperations(false)`). It will be
 //       automatically disabled if compiling for GopherJS or AppEngine.
 package describe
 
 import (
 	"bytes"
 	"fmt"
+	"hash/crc32"
+	"io"
 	"math/big"
 	"reflect"
+	"runtime/debug"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/kstenerud/go-duplicates"
 )
@@ -66,10 +105,13 @@ func init() {
 // ----------------
 
 const maxIndentStep = 100
+const maxBaseIndent = 1000
 
 const (
 	tokOpenString             = `"`
 	tokCloseString            = `"`
+	tokSingleQuote            = `'`
+	tokBacktick               = "`"
 	tokOpenArray              = "["
 	tokCloseArray             = "]"
 	tokOpenMap                = "{"
@@ -87,11 +129,36 @@ const (
 	tokInterfacePrefix        = "@"
 	tokReferenceSeparator     = "~"
 	tokReferencePrefix        = "$"
+	tokEnclosingReference     = "^"
+	tokOutOfRange             = "!"
 	tokNilPointer             = "nil"
 	tokEmptyInterface         = "interface"
 	tokInvalid                = "invalid"
 	tokRecvChannel            = "<-chan"
 	tokSendChannel            = "chan<-"
+	tokAnnotationPrefix       = " "
+	tokOpenAnnotation         = "["
+	tokCloseAnnotation        = "]"
+	tokOpenAnnotationComment  = "/* "
+	tokCloseAnnotationComment = " */"
+	tokGroupPrefix            = "# "
+	tokOmittedField           = "-"
+	tokRedacted               = "***"
+	tokSetPrefix              = "set"
+	tokOpenReferenceLabel     = "("
+	tokCloseReferenceLabel    = ")"
+	tokElided                 = "…"
+	tokMoreEntries            = "entries"
+	tokMoreElements           = "elements"
+	tokHexDumpOffsetSeparator = ":"
+	tokDescriberTimeout       = "describer timeout"
+	tokTimeLocation           = "location"
+	tokTimeMonotonic          = "monotonic"
+	tokMonotonicPrefix        = " m="
+	tokNoMonotonic            = "none"
+	tokPoisoned               = "!POISONED"
+	tokOpenPoisonReason       = "("
+	tokClosePoisonReason      = ") "
 )
 
 const is64BitUint = uint64(^uint(0)) == ^uint64(0)
@@ -100,12 +167,15 @@ const is64BitUintptr = uint64(^uintptr(0)) == ^uint64(0)
 var reflectValueType = reflect.ValueOf(reflect.ValueOf(true)).Type()
 var reflectTypeType = reflect.TypeOf((*reflect.Type)(nil)).Elem()
 var emptyInterfaceType = reflect.ValueOf([]interface{}{}).Type().Elem()
+var timeType = reflect.TypeOf(time.Time{})
 
 // -----------
 // Global Data
 // -----------
 
 var customDescribers sync.Map
+var taggedUnions sync.Map
+var panicHandler atomic.Value
 
 // ---------
 // Utilities
@@ -117,9 +187,31 @@ var customDescribers sync.Map
 // Please send bug reports to https://github.com/kstenerud/go-describe/issues
 func notifyLibraryBug(format string, params ...interface{}) string {
 	description := fmt.Sprintf(format, params...)
+	if handler := LibraryBugHandler; handler != nil {
+		handler(description)
+	}
+	if PanicOnLibraryBug {
+		panic(libraryBug(description))
+	}
 	return fmt.Sprintf("go-describe.BUG(%v)", description)
 }
 
+// The panic raised by notifyLibraryBug() when PanicOnLibraryBug is set.
+type libraryBug string
+
+func (this libraryBug) Error() string {
+	return fmt.Sprintf("go-describe.BUG(%v)", string(this))
+}
+
+// Report a panic recovered from a description as a library bug. A panic that
+// notifyLibraryBug() raised has already been reported, so it's passed on as is.
+func notifyRecoveredLibraryBug(e interface{}) string {
+	if bug, ok := e.(libraryBug); ok {
+		panic(bug)
+	}
+	return notifyLibraryBug("%v", e)
+}
+
 func isNil(v reflect.Value) bool {
 	switch v.Kind() {
 	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
@@ -129,6 +221,31 @@ func isNil(v reflect.Value) bool {
 	return false
 }
 
+// Follow pointers and interfaces from v for as long as stop (if not nil)
+// returns false, and return the value reached. A pointer that was already
+// followed ends the chain, so that cycles of pointers and interfaces (such as
+// `x = &x`) can't loop forever.
+func followPointers(v reflect.Value, stop func(reflect.Value) bool) reflect.Value {
+	var followed map[duplicates.TypedPointer]bool
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		if stop != nil && stop(v) {
+			break
+		}
+		if v.Kind() == reflect.Ptr {
+			ptr := duplicates.TypedPointerOfRV(v)
+			if followed[ptr] {
+				break
+			}
+			if followed == nil {
+				followed = make(map[duplicates.TypedPointer]bool)
+			}
+			followed[ptr] = true
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
 func getTypeName(t reflect.Type) string {
 	if t == emptyInterfaceType {
 		return tokEmptyInterface
@@ -140,20 +257,22 @@ func getTypeName(t reflect.Type) string {
 		if index < 0 {
 			return notifyLibraryBug("could not parse chan type %v", string(nameBytes))
 		}
-		typeName := string(nameBytes[index+1:])
+		return getChanTypeName(t, string(nameBytes[index+1:]))
+	}
 
-		if t.ChanDir()&reflect.BothDir == reflect.BothDir {
-			return fmt.Sprintf("chan%v%v%v", tokOpenStruct, typeName, tokCloseStruct)
-		}
+	return fmt.Sprintf("%v", t)
+}
 
-		chanDir := tokRecvChannel
-		if t.ChanDir()&reflect.SendDir != 0 {
-			chanDir = tokSendChannel
-		}
-		return fmt.Sprintf("%v %v", chanDir, typeName)
+func getChanTypeName(t reflect.Type, elemTypeName string) string {
+	if t.ChanDir()&reflect.BothDir == reflect.BothDir {
+		return fmt.Sprintf("chan%v%v%v", tokOpenStruct, elemTypeName, tokCloseStruct)
 	}
 
-	return fmt.Sprintf("%v", t)
+	chanDir := tokRecvChannel
+	if t.ChanDir()&reflect.SendDir != 0 {
+		chanDir = tokSendChannel
+	}
+	return fmt.Sprintf("%v %v", chanDir, elemTypeName)
 }
 
 func stringifyUint(value uint64) string {
@@ -170,23 +289,36 @@ func stringifyAddress(address uint64) string {
 	return fmt.Sprintf("0x%08x", address)
 }
 
-func getInterfaceAsReflectValue(v reflect.Value) (value reflect.Value, ok bool) {
+func (this *describer) getInterface(v reflect.Value) (value interface{}, ok bool) {
+	if v.CanInterface() {
+		return v.Interface(), true
+	}
+	if this.canUseUnsafe() {
+		return exposeInterface(v), true
+	}
+	return nil, false
+}
+
+func (this *describer) getInterfaceAsReflectValue(v reflect.Value) (value reflect.Value, ok bool) {
 	if v.CanInterface() {
 		return v.Interface().(reflect.Value), true
 	}
-	if canExposeInterface() {
+	if this.canUseUnsafe() {
 		return exposeInterface(v).(reflect.Value), true
 	}
 	return v, false
 }
 
-func getInterfaceAsReflectType(v reflect.Value) (t reflect.Type, ok bool) {
+func (this *describer) getInterfaceAsReflectType(v reflect.Value) (t reflect.Type, ok bool) {
 	if v.CanInterface() {
 		return v.Interface().(reflect.Type), true
 	}
-	if canExposeInterface() {
+	if this.canUseUnsafe() {
 		return exposeInterface(v).(reflect.Type), true
 	}
+	if t, ok := lookupKnownType(v); ok {
+		return t, true
+	}
 	return v.Type(), false
 }
 
@@ -194,29 +326,119 @@ func getInterfaceAsReflectType(v reflect.Value) (t reflect.Type, ok bool) {
 // Custom Describers
 // -----------------
 
-func runCustomDescriber(v reflect.Value, describer CustomDescriber) (description string) {
+func (this *describer) runCustomDescriber(v reflect.Value, describer ContextDescriber) (description string) {
 	// A custom describer runs unknown user-supplied code that we don't control.
 	// If it panics, return the stringified contents of the panic instead.
 	defer func() {
 		// Allow panic to escape if debugging
 		if !DebugPanics {
 			if e := recover(); e != nil {
+				e = notifyRecoveredPanic(v.Type(), e)
 				description = fmt.Sprintf("panic(%v)", e)
 			}
 		}
 	}()
 
-	description = describer(v)
+	context := this.newDescribeContext()
+	description, ok := this.runUserCode(func() string {
+		return runUserDescription(v, func() string {
+			describer(context, v)
+			return context.String()
+		})
+	})
+	context.finish()
+	if !ok {
+		description = this.describeTimeout(v)
+	}
+	if description == "" {
+		// An empty description would be indistinguishable from no output.
+		description = this.typeName(v.Type()) + tokOpenStruct + tokCloseStruct
+	}
 	return
 }
 
-func describeStringer(v reflect.Value) string {
+// A panic that was recovered in another goroutine, and re-panicked in the
+// describing goroutine.
+type recoveredPanic struct {
+	value interface{}
+	stack []byte
+}
+
+func (this *recoveredPanic) Error() string {
+	return fmt.Sprintf("%v\n\nOriginal goroutine stack:\n%s", this.value, this.stack)
+}
+
+// Run user-supplied code, abandoning it if it takes longer than the describer
+// timeout (if any). Returns ok = false if the code timed out.
+//
+// Panics in the user code are re-panicked as *recoveredPanic.
+func (this *describer) runUserCode(code func() string) (result string, ok bool) {
+	if this.describerTimeout <= 0 {
+		return code(), true
+	}
+
+	type outcome struct {
+		result    string
+		recovered *recoveredPanic
+	}
+	// Buffered so that an abandoned goroutine can still finish and exit.
+	done := make(chan outcome, 1)
+	go func() {
+		var o outcome
+		defer func() {
+			if e := recover(); e != nil {
+				o.recovered = &recoveredPanic{value: e, stack: debug.Stack()}
+			}
+			done <- o
+		}()
+		o.result = code()
+	}()
+
+	timer := time.NewTimer(this.describerTimeout)
+	defer timer.Stop()
+	select {
+	case o := <-done:
+		if o.recovered != nil {
+			panic(o.recovered)
+		}
+		return o.result, true
+	case <-timer.C:
+		return "", false
+	}
+}
+
+func (this *describer) describeTimeout(v reflect.Value) string {
+	return fmt.Sprintf("%v%v%v%v", this.typeName(v.Type()), tokOpenStruct, tokDescriberTimeout, tokCloseStruct)
+}
+
+// Notify the panic handler (if any) of a recovered panic, returning the
+// original panic value.
+func notifyRecoveredPanic(t reflect.Type, recovered interface{}) (value interface{}) {
+	value = recovered
+	var stack []byte
+	if rp, ok := recovered.(*recoveredPanic); ok {
+		value = rp.value
+		stack = rp.stack
+	}
+
+	if handler, ok := panicHandler.Load().(PanicHandler); ok && handler != nil {
+		if stack == nil {
+			stack = debug.Stack()
+		}
+		handler(t, value, stack)
+	}
+	return
+}
+
+// Describe v using the String() method of stringer, which is either v itself or
+// a pointer to v.
+func (this *describer) describeStringer(v reflect.Value, stringer reflect.Value) string {
 	var asString fmt.Stringer
 
-	if v.CanInterface() {
-		asString = v.Interface().(fmt.Stringer)
-	} else if canExposeInterface() {
-		asString = exposeInterface(v).(fmt.Stringer)
+	if stringer.CanInterface() {
+		asString = stringer.Interface().(fmt.Stringer)
+	} else if this.canUseUnsafe() {
+		asString = exposeInterface(stringer).(fmt.Stringer)
 	}
 
 	if asString != nil {
@@ -225,6 +447,21 @@ func describeStringer(v reflect.Value) string {
 	return fmt.Sprintf(`%v%vunexported%v`, v.Type(), tokOpenStruct, tokCloseStruct)
 }
 
+// Get a pointer to v (or to a temporary copy of v if it's not addressable), so
+// that pointer receiver methods can be called on it.
+func (this *describer) getPointerTo(v reflect.Value) (ptr reflect.Value, ok bool) {
+	if v.CanAddr() {
+		return v.Addr(), true
+	}
+	value, ok := this.getInterface(v)
+	if !ok {
+		return
+	}
+	ptr = reflect.New(v.Type())
+	ptr.Elem().Set(reflect.ValueOf(value))
+	return ptr, true
+}
+
 var bitsToDigits = []int{0, 1, 1, 1, 1, 2, 2, 2, 3, 3}
 
 func describeBigFloat(v reflect.Value) string {
@@ -247,20 +484,10 @@ func describePBigFloat(v reflect.Value) string {
 // Duplicates Finder
 // -----------------
 
-func findDuplicates(v reflect.Value) map[duplicates.TypedPointer]int {
-	referenceNames := map[duplicates.TypedPointer]int{}
-	if !v.IsValid() {
-		return referenceNames
-	}
-	duplicatePtrs := duplicates.FindDuplicatePointers(v.Interface())
-	referenceName := 1
-	for pointer, isDuplicate := range duplicatePtrs {
-		if isDuplicate {
-			referenceNames[pointer] = referenceName
-			referenceName++
-		}
-	}
-	return referenceNames
+func (this *describer) assignReferenceName(ptr duplicates.TypedPointer) int {
+	this.lastReferenceName++
+	this.referenceNames[ptr] = this.lastReferenceName
+	return this.lastReferenceName
 }
 
 // ---------
@@ -276,7 +503,32 @@ func (this *describer) decreaseIndent() {
 }
 
 func (this *describer) writeString(value string) {
-	this.stringBuilder.WriteString(value)
+	switch {
+	case this.fixedOutput != nil:
+		this.fixedOutput.WriteString(value)
+	case this.streamOutput != nil:
+		this.streamOutput.WriteString(value)
+	default:
+		if this.outputLimit > 0 && this.stringBuilder.Len()+len(value) > this.outputLimit {
+			// Anything past the limit would be thrown away.
+			value = value[:this.outputLimit-this.stringBuilder.Len()]
+		}
+		this.stringBuilder.WriteString(value)
+	}
+	if this.indentStep > 0 && strings.Contains(value, tokItemSeparatorMultiline) {
+		this.lastNewlineEnd = this.outputLength()
+	}
+}
+
+func (this *describer) outputLength() int {
+	switch {
+	case this.fixedOutput != nil:
+		return this.fixedOutput.Len()
+	case this.streamOutput != nil:
+		return this.streamOutput.Len()
+	default:
+		return this.stringBuilder.Len()
+	}
 }
 
 func (this *describer) writeFmt(format string, args ...interface{}) {
@@ -303,61 +555,184 @@ func (this *describer) writeKeyValueSeparator() {
 }
 
 func (this *describer) describeArray(v reflect.Value) {
+	if this.tryDescribeHexDump(v) {
+		return
+	}
 	isInUnsignedArray := false
 	switch v.Type().Elem().Kind() {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		isInUnsignedArray = true
 	}
-	this.writeString(getTypeName(v.Type().Elem()))
+	this.writeTypeName(v.Type().Elem())
 	this.writeString(tokOpenArray)
 	this.increaseIndent()
+	order := this.getSortedElementOrder(v)
+	count := this.getElementCountToDescribe(v.Len())
 	isFirst := true
-	for i := 0; i < v.Len(); i++ {
+	for i := 0; i < count && !this.isOutputFull(); i++ {
 		this.writeItemSeparator(isFirst)
 		isFirst = false
-		this.describeReflectedValue(v.Index(i), isInUnsignedArray)
+		index := i
+		if order != nil {
+			index = order[i]
+		}
+		this.describeChild(indexSegment(index), v.Index(index), isInUnsignedArray)
 	}
+	this.writeOmittedElements(v.Len()-count, isFirst)
 	this.decreaseIndent()
 	this.writeItemSeparator(true)
 	this.writeString(tokCloseArray)
 }
 
 func (this *describer) describeMap(v reflect.Value) {
-	this.writeString(getTypeName(v.Type().Key()))
-	this.writeString(tokMapTypeSeparator)
-	this.writeString(getTypeName(v.Type().Elem()))
+	if isSetLikeMap(v) {
+		this.describeSet(v)
+		return
+	}
+	this.writeMapType(v)
+	this.describeMapEntries(this.iterateMap(v))
+}
+
+// Describe map entries, enclosed in `{}`, followed by a marker for the number
+// of entries that were left out (if any).
+func (this *describer) describeMapEntries(iter mapIterator, omittedCount int) {
 	this.writeString(tokOpenMap)
 	this.increaseIndent()
 	isFirst := true
-	for iter := mapRange(v); iter.Next(); {
+	for iter.Next() && !this.isOutputFull() {
 		this.writeItemSeparator(isFirst)
 		isFirst = false
-		this.describeReflectedValue(iter.Key(), false)
+		key := iter.Key()
+		switch this.mapContents {
+		case MapKeysOnly:
+			this.describeChild(keySegment(key), key, false)
+			continue
+		case MapValuesOnly:
+			this.describeMapValue(key, iter.Value())
+			continue
+		}
+		keyStart := this.outputLength()
+		if !this.tryDescribeTypeKey(key) {
+			this.describeReflectedValue(key, false)
+		}
+		if this.indentStep > 0 && this.lastNewlineEnd > keyStart {
+			// Keep a multiline key visually associated with its value by
+			// ending the key with the separator and indenting the value
+			// beneath it.
+			this.writeString(" ")
+			this.writeString(tokKeyValueSeparator)
+			this.increaseIndent()
+			this.writeItemSeparator(false)
+			this.describeMapValue(key, iter.Value())
+			this.decreaseIndent()
+			continue
+		}
 		this.writeKeyValueSeparator()
-		this.describeReflectedValue(iter.Value(), false)
+		this.describeMapValue(key, iter.Value())
 	}
+	this.writeOmittedEntries(omittedCount, isFirst)
 	this.decreaseIndent()
 	this.writeItemSeparator(true)
 	this.writeString(tokCloseMap)
 }
 
+// Describe the value of a map entry, or mask it if its key is redacted (see
+// WithRedaction()).
+func (this *describer) describeMapValue(key reflect.Value, value reflect.Value) {
+	if this.isRedactedKey(key) {
+		this.writeString(tokRedacted)
+		return
+	}
+	this.describeChild(keySegment(key), value, false)
+}
+
 func (this *describer) describeStruct(v reflect.Value) {
-	this.writeString(getTypeName(v.Type()))
+	this.writeTypeName(v.Type())
 	this.writeString(tokOpenStruct)
 	this.increaseIndent()
-	isFirst := true
-	for i := 0; i < v.NumField(); i++ {
-		this.writeItemSeparator(isFirst)
-		isFirst = false
-		this.writeString(v.Type().Field(i).Name)
-		this.writeKeyValueSeparator()
-		this.describeReflectedValue(v.Field(i), false)
+	indices := getVisibleFieldIndices(v)
+	if this.groupFields && this.indentStep > 0 {
+		this.describeGroupedFields(v, indices)
+	} else {
+		this.describeFields(v, indices, true)
 	}
 	this.decreaseIndent()
 	this.writeItemSeparator(true)
 	this.writeString(tokCloseStruct)
 }
 
+func (this *describer) describeFields(v reflect.Value, indices []int, isFirst bool) (isStillFirst bool) {
+	for _, i := range indices {
+		if this.isOutputFull() {
+			break
+		}
+		this.writeItemSeparator(isFirst)
+		isFirst = false
+		field := v.Type().Field(i)
+		this.writeColored(colorFieldName, getFieldName(v.Type(), i))
+		this.writeKeyValueSeparator()
+		if this.isRedactedField(v.Type(), i) {
+			this.writeString(tokRedacted)
+			continue
+		}
+		segment := fieldSegment(field.Name)
+		segment.owner = v.Type()
+		this.pendingFieldDescriber, _ = this.getFieldDescriber(v.Type(), field.Name)
+		this.describeChild(segment, v.Field(i), false)
+	}
+	return isFirst
+}
+
+// Describe fields that have a group tag under a labeled section per group,
+// after the ungrouped fields.
+func (this *describer) describeGroupedFields(v reflect.Value, indices []int) {
+	var ungrouped []int
+	var groupNames []string
+	groups := make(map[string][]int)
+	for _, i := range indices {
+		group := getDescribeTag(v.Type(), i).group
+		if group == "" {
+			ungrouped = append(ungrouped, i)
+			continue
+		}
+		if _, ok := groups[group]; !ok {
+			groupNames = append(groupNames, group)
+		}
+		groups[group] = append(groups[group], i)
+	}
+
+	isFirst := this.describeFields(v, ungrouped, true)
+	for _, group := range groupNames {
+		this.writeItemSeparator(isFirst)
+		isFirst = false
+		this.writeString(tokGroupPrefix)
+		this.writeString(group)
+		this.increaseIndent()
+		this.describeFields(v, groups[group], false)
+		this.decreaseIndent()
+	}
+}
+
+func getVisibleFieldIndices(v reflect.Value) []int {
+	var indices []int
+	if union, ok := getTaggedUnion(v.Type()); ok {
+		indices = union.getActiveFieldIndices(v)
+	} else {
+		indices = make([]int, v.NumField())
+		for i := range indices {
+			indices[i] = i
+		}
+	}
+
+	visible := indices[:0]
+	for _, i := range indices {
+		if !getDescribeTag(v.Type(), i).isOmitted {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
 func (this *describer) describeFunc(v reflect.Value) {
 	var t reflect.Type = v.Type()
 
@@ -369,7 +744,7 @@ func (this *describer) describeFunc(v reflect.Value) {
 	this.writeString(tokOpenFunc)
 	numIn := t.NumIn()
 	for i := 0; i < numIn; i++ {
-		this.writeString(getTypeName(t.In(i)))
+		this.writeTypeName(t.In(i))
 		if i < numIn-1 {
 			this.writeString(", ")
 		}
@@ -378,7 +753,7 @@ func (this *describer) describeFunc(v reflect.Value) {
 	this.writeString(tokOpenFunc)
 	numOut := t.NumOut()
 	for i := 0; i < numOut; i++ {
-		this.writeString(getTypeName(t.Out(i)))
+		this.writeTypeName(t.Out(i))
 		if i < numOut-1 {
 			this.writeString(", ")
 		}
@@ -386,43 +761,58 @@ func (this *describer) describeFunc(v reflect.Value) {
 	this.writeString(tokCloseFunc)
 }
 
+func (this *describer) describeChannel(v reflect.Value) {
+	this.writeTypeName(v.Type())
+	showLengths := EnableChannelLengths
+	if this.hasChannelLengths {
+		showLengths = this.showChannelLengths
+	}
+	if !showLengths || v.Cap() == 0 {
+		return
+	}
+
+	this.writeString(tokOpenFunc)
+	this.writeFmt("len=%v cap=%v", v.Len(), v.Cap())
+	this.writeString(tokCloseFunc)
+}
+
 func (this *describer) describeUint8(v uint8, isInUnsignedArray bool) {
 	if isInUnsignedArray {
-		this.writeFmt("0x%02x", v)
+		this.writeColored(colorNumber, fmt.Sprintf("0x%02x", v))
 	} else {
-		this.writeFmt("%v", v)
+		this.writeColored(colorNumber, fmt.Sprintf("%v", v))
 	}
 }
 
 func (this *describer) describeUint16(v uint16, isInUnsignedArray bool) {
 	if isInUnsignedArray {
-		this.writeFmt("0x%04x", v)
+		this.writeColored(colorNumber, fmt.Sprintf("0x%04x", v))
 	} else {
-		this.writeFmt("%v", v)
+		this.writeColored(colorNumber, fmt.Sprintf("%v", v))
 	}
 }
 
 func (this *describer) describeUint32(v uint32, isInUnsignedArray bool) {
 	if isInUnsignedArray {
-		this.writeFmt("0x%08x", v)
+		this.writeColored(colorNumber, fmt.Sprintf("0x%08x", v))
 	} else {
-		this.writeFmt("%v", v)
+		this.writeColored(colorNumber, fmt.Sprintf("%v", v))
 	}
 }
 
 func (this *describer) describeUint64(v uint64, isInUnsignedArray bool) {
 	if isInUnsignedArray {
-		this.writeFmt("0x%016x", v)
+		this.writeColored(colorNumber, fmt.Sprintf("0x%016x", v))
 	} else {
-		this.writeFmt("%v", v)
+		this.writeColored(colorNumber, fmt.Sprintf("%v", v))
 	}
 }
 
 func (this *describer) describeUint(v uint, isInUnsignedArray bool) {
 	if isInUnsignedArray {
-		this.writeString(stringifyUint(uint64(v)))
+		this.writeColored(colorNumber, stringifyUint(uint64(v)))
 	} else {
-		this.writeFmt("%v", v)
+		this.writeColored(colorNumber, fmt.Sprintf("%v", v))
 	}
 }
 
@@ -431,7 +821,7 @@ func (this *describer) tryDescribeNil(v reflect.Value) (didDescribeNil bool) {
 		if v.Kind() == reflect.Func {
 			this.describeFunc(v)
 		} else {
-			this.writeString(tokNilPointer)
+			this.writeColored(colorNil, tokNilPointer)
 		}
 		didDescribeNil = true
 		return
@@ -453,7 +843,7 @@ func (this *describer) tryDescribeReflectValueOrType(v reflect.Value) (didDescri
 	if v.Type() == reflectValueType {
 		this.writeString("reflect.Value")
 		this.writeString(tokOpenStruct)
-		if rValue, ok := getInterfaceAsReflectValue(v); ok {
+		if rValue, ok := this.getInterfaceAsReflectValue(v); ok {
 			this.describeReflectedValue(rValue, false)
 		} else {
 			this.writeFmt("%v", v)
@@ -466,8 +856,8 @@ func (this *describer) tryDescribeReflectValueOrType(v reflect.Value) (didDescri
 	if v.Type().Implements(reflectTypeType) {
 		this.writeString("reflect.Type")
 		this.writeString(tokOpenStruct)
-		if rValue, ok := getInterfaceAsReflectType(v); ok {
-			this.writeString(getTypeName(rValue))
+		if rValue, ok := this.getInterfaceAsReflectType(v); ok {
+			this.writeTypeName(rValue)
 		} else {
 			this.writeFmt("%v", v)
 		}
@@ -484,6 +874,14 @@ func (this *describer) tryDescribeReference(v reflect.Value) (didReplaceWithRefe
 	// Note: This method has the side effect of modifying this.seenReferences
 
 	switch v.Kind() {
+	case reflect.Ptr:
+		// Pointers to containers are referenced via what they point to, but
+		// chains of pointers and interfaces (such as `x = &x`) can only be
+		// caught here.
+		if kind := v.Type().Elem().Kind(); kind != reflect.Ptr && kind != reflect.Interface {
+			return
+		}
+		fallthrough
 	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct:
 		var ptr duplicates.TypedPointer
 		if v.Kind() == reflect.Struct || v.Kind() == reflect.Array {
@@ -499,8 +897,8 @@ func (this *describer) tryDescribeReference(v reflect.Value) (didReplaceWithRefe
 			if _, ok := this.seenReferences[ptr]; ok {
 				// The first instance of a repeated structure was described
 				// already, so we replace with a reference.
-				this.writeString(tokReferencePrefix)
-				this.writeFmt("%v", referenceName)
+				this.writeColored(colorReference, fmt.Sprintf("%v%v", tokReferencePrefix, referenceName))
+				this.writeReferenceLabel(v)
 				didReplaceWithReference = true
 				return
 			}
@@ -508,8 +906,9 @@ func (this *describer) tryDescribeReference(v reflect.Value) (didReplaceWithRefe
 			// We're only marking the first instance of a repeated structure
 			// rather than replacing it, so in this case we haven't replaced
 			// with a reference.
-			this.writeFmt("%v", referenceName)
-			this.writeString(tokReferenceSeparator)
+			referenceName = this.assignReferenceName(ptr)
+			this.indexReference(referenceName, this.outputLength())
+			this.writeColored(colorReference, fmt.Sprintf("%v%v", referenceName, tokReferenceSeparator))
 			this.seenReferences[ptr] = true
 			didReplaceWithReference = false
 			return
@@ -520,14 +919,134 @@ func (this *describer) tryDescribeReference(v reflect.Value) (didReplaceWithRefe
 	return
 }
 
+func (this *describer) tryDescribeTypeDepthExceeded(v reflect.Value) (didDescribe bool) {
+	if len(this.typeDepthLimits) == 0 || !v.IsValid() {
+		return
+	}
+	if limit, ok := this.typeDepthLimits[v.Type()]; ok && this.typeDepths[v.Type()] >= limit {
+		this.describeElided(v)
+		didDescribe = true
+	}
+	return
+}
+
+func (this *describer) tryDescribeDepthExceeded(v reflect.Value) (didDescribe bool) {
+	if !this.limitDepth || this.depth < this.maxDepth {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		this.describeElided(v)
+		this.didElideDepth = true
+		didDescribe = true
+	}
+	return
+}
+
+func (this *describer) enterTypeDepth(v reflect.Value) {
+	if len(this.typeDepthLimits) == 0 || !v.IsValid() {
+		return
+	}
+	if _, ok := this.typeDepthLimits[v.Type()]; ok {
+		if this.typeDepths == nil {
+			this.typeDepths = make(map[reflect.Type]int)
+		}
+		this.typeDepths[v.Type()]++
+	}
+}
+
+func (this *describer) exitTypeDepth(v reflect.Value) {
+	if len(this.typeDepthLimits) == 0 || !v.IsValid() {
+		return
+	}
+	if _, ok := this.typeDepthLimits[v.Type()]; ok {
+		this.typeDepths[v.Type()]--
+	}
+}
+
+// Describe a value that won't be expanded, keeping its type and enclosing
+// brackets so that it's clear what was left out.
+func (this *describer) describeElided(v reflect.Value) {
+	if this.tryDescribeElidedDescribable(v) {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		this.writeTypeName(v.Type())
+		this.writeString(tokOpenStruct)
+		this.writeString(tokElided)
+		this.writeString(tokCloseStruct)
+	case reflect.Slice, reflect.Array:
+		this.writeTypeName(v.Type().Elem())
+		this.writeString(tokOpenArray)
+		this.writeString(tokElided)
+		this.writeString(tokCloseArray)
+	case reflect.Map:
+		this.writeMapType(v)
+		this.writeString(tokOpenMap)
+		this.writeString(tokElided)
+		this.writeString(tokCloseMap)
+	default:
+		this.writeString(tokElided)
+	}
+}
+
+func (this *describer) tryDescribeTime(v reflect.Value) (didDescribeTime bool) {
+	if (this.timeLayout == "" && !this.timeDetails) || !v.IsValid() || v.Type() != timeType {
+		return
+	}
+	asInterface, ok := this.getInterface(v)
+	if !ok {
+		return
+	}
+	t := asInterface.(time.Time)
+	this.writeTypeName(v.Type())
+	this.writeString(tokOpenStruct)
+	if this.timeLayout != "" {
+		this.writeString(t.Format(this.timeLayout))
+	} else {
+		// Round(0) strips the monotonic clock reading.
+		this.writeString(t.Round(0).String())
+	}
+	if this.timeDetails {
+		this.writeFmt(" %v%v%v", tokTimeLocation, tokKeyValueSeparator, t.Location())
+		this.writeFmt(" %v%v%v", tokTimeMonotonic, tokKeyValueSeparator, getMonotonicReading(t))
+	}
+	this.writeString(tokCloseStruct)
+	didDescribeTime = true
+	return
+}
+
+// Get a time's monotonic clock reading as time.Time.String() shows it (such as
+// `+0.001384552`), or tokNoMonotonic if it doesn't have one.
+func getMonotonicReading(t time.Time) string {
+	description := t.String()
+	if index := strings.LastIndex(description, tokMonotonicPrefix); index >= 0 {
+		return description[index+len(tokMonotonicPrefix):]
+	}
+	return tokNoMonotonic
+}
+
 func (this *describer) tryUseCustomDescriber(v reflect.Value) (didUseCustomDescriber bool) {
-	if !v.IsValid() {
+	if !v.IsValid() || this.reflectionOnly {
 		didUseCustomDescriber = false
 		return
 	}
 
-	if customDescriber, ok := customDescribers.Load(v.Type()); ok && customDescriber != nil {
-		this.writeString(runCustomDescriber(v, customDescriber.(CustomDescriber)))
+	if customDescriber, ok := this.getCustomDescriber(v.Type()); ok {
+		if isInUserDescription(v) {
+			this.writeEnclosingReference(v)
+			didUseCustomDescriber = true
+			return
+		}
+		target, ok := this.getDescriberTarget(v)
+		if !ok {
+			didUseCustomDescriber = false
+			return
+		}
+		this.writeUserDescription(v, func() string {
+			return this.runCustomDescriber(target, customDescriber)
+		})
 		didUseCustomDescriber = true
 		return
 	}
@@ -536,29 +1055,211 @@ func (this *describer) tryUseCustomDescriber(v reflect.Value) (didUseCustomDescr
 	return
 }
 
+// Get the value to pass to a custom describer. Values in unexported fields are
+// exposed if unsafe operations are available, and are otherwise passed as is
+// (so the describer can't call Interface() on them). Returns ok = false if
+// they're not to be passed to describers (see WithUnexportedDescribers()).
+func (this *describer) getDescriberTarget(v reflect.Value) (target reflect.Value, ok bool) {
+	if v.CanInterface() {
+		return v, true
+	}
+	if this.hideUnexportedFromDescribers {
+		return v, false
+	}
+	if this.canUseUnsafe() {
+		return exposeValue(v), true
+	}
+	return v, true
+}
+
+// Describe a struct field's value using a describer set for that field. See
+// SetFieldDescriber().
+func (this *describer) tryUseFieldDescriber(v reflect.Value, describer ContextDescriber) (didUseFieldDescriber bool) {
+	if describer == nil || this.reflectionOnly {
+		return
+	}
+	if isInUserDescription(v) {
+		this.writeEnclosingReference(v)
+		return true
+	}
+	target, ok := this.getDescriberTarget(v)
+	if !ok {
+		return
+	}
+	// Not memoized, since the same value may be described differently when
+	// it's reached other than via this field.
+	this.writeString(this.runCustomDescriber(target, describer))
+	return true
+}
+
 func (this *describer) tryUseStringerDescriber(v reflect.Value) (didUseStringerDescriber bool) {
-	if !v.IsValid() || v.IsZero() {
+	if !v.IsValid() || this.reflectionOnly {
 		return
 	}
-	method := v.MethodByName("String")
-	if !method.IsValid() {
+	if v.IsZero() && !this.stringerFallback {
+		// Zero values are often only partially usable.
+		return
+	}
+	stringer := v
+	if !v.MethodByName("String").IsValid() {
+		// Most String() methods in the wild have pointer receivers.
+		if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			return
+		}
+		if _, ok := reflect.PtrTo(v.Type()).MethodByName("String"); !ok {
+			return
+		}
+		var ok bool
+		if stringer, ok = this.getPointerTo(v); !ok {
+			return
+		}
+	}
+	if isInUserDescription(v) {
+		this.writeEnclosingReference(v)
+		didUseStringerDescriber = true
 		return
 	}
 	defer func() {
 		// If a stringer panics somewhere, just abort.
-		recover()
+		if e := recover(); e != nil {
+			notifyRecoveredPanic(v.Type(), e)
+		}
 	}()
-	this.writeString(describeStringer(v))
+	this.writeUserDescription(v, func() string {
+		description, ok := this.runUserCode(func() string {
+			return runUserDescription(v, func() string {
+				return this.describeStringer(v, stringer)
+			})
+		})
+		if !ok {
+			description = this.describeTimeout(v)
+		}
+		return description
+	})
 	didUseStringerDescriber = true
 	return
 }
 
+// Check if v has a String() method that tryUseStringerDescriber() would call.
+func hasStringMethod(v reflect.Value) bool {
+	if v.MethodByName("String").IsValid() {
+		return true
+	}
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return false
+	}
+	_, ok := reflect.PtrTo(v.Type()).MethodByName("String")
+	return ok
+}
+
+var goStringerType = reflect.TypeOf((*fmt.GoStringer)(nil)).Elem()
+
+// Describe v using its GoString() method. Only done if enabled via
+// WithGoStringers().
+func (this *describer) tryUseGoStringerDescriber(v reflect.Value) (didDescribe bool) {
+	if !this.goStringers || this.reflectionOnly {
+		return
+	}
+	receiver, ok := this.getReceiverImplementing(v, goStringerType)
+	if !ok || (v.IsZero() && !this.stringerFallback) {
+		return
+	}
+	if isInUserDescription(v) {
+		this.writeEnclosingReference(v)
+		didDescribe = true
+		return
+	}
+	defer func() {
+		// If a GoString() method panics somewhere, just abort.
+		if e := recover(); e != nil {
+			notifyRecoveredPanic(v.Type(), e)
+		}
+	}()
+	this.writeUserDescription(v, func() string {
+		description, ok := this.runUserCode(func() string {
+			return runUserDescription(v, func() string {
+				return this.describeGoStringer(v, receiver)
+			})
+		})
+		if !ok {
+			description = this.describeTimeout(v)
+		}
+		return description
+	})
+	didDescribe = true
+	return
+}
+
+// Describe v using the GoString() method of receiver, which is either v itself
+// or a pointer to v.
+func (this *describer) describeGoStringer(v reflect.Value, receiver reflect.Value) string {
+	asInterface, ok := this.getInterface(receiver)
+	if !ok {
+		return fmt.Sprintf(`%v%vunexported%v`, this.typeName(v.Type()), tokOpenStruct, tokCloseStruct)
+	}
+	return this.typeName(v.Type()) + tokOpenStruct + asInterface.(fmt.GoStringer).GoString() + tokCloseStruct
+}
+
+// Get the key under which a user-supplied description of v is memoized, if v
+// has a stable identity.
+func getMemoKey(v reflect.Value) (key duplicates.TypedPointer, ok bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.UnsafePointer:
+		return duplicates.TypedPointerOfRV(v), true
+	}
+	if v.CanAddr() {
+		return duplicates.TypedPointer{Type: v.Type(), Pointer: v.UnsafeAddr()}, true
+	}
+	return
+}
+
+// Write a description produced by user-supplied code (which may be expensive),
+// memoizing it so that an object reached via multiple aliases is only
+// described once. Aliased pointers are marked using references like any other
+// duplicate data.
+//
+// Note: describe() must not write anything before it returns, since it might
+// panic.
+func (this *describer) writeUserDescription(v reflect.Value, describe func() string) {
+	key, hasKey := getMemoKey(v)
+	if !hasKey {
+		this.writeString(describe())
+		return
+	}
+
+	referenceName, isReferenced := this.referenceNames[key]
+	isReferenced = isReferenced && v.Kind() == reflect.Ptr
+	if isReferenced && this.seenReferences[key] {
+		this.writeColored(colorReference, fmt.Sprintf("%v%v", tokReferencePrefix, referenceName))
+		return
+	}
+
+	description, ok := this.memoizedDescriptions[key]
+	if !ok {
+		description = describe()
+		if this.memoizedDescriptions == nil {
+			this.memoizedDescriptions = make(map[duplicates.TypedPointer]string)
+		}
+		this.memoizedDescriptions[key] = description
+	}
+
+	if isReferenced {
+		referenceName = this.assignReferenceName(key)
+		this.writeColored(colorReference, fmt.Sprintf("%v%v", referenceName, tokReferenceSeparator))
+		this.seenReferences[key] = true
+	}
+	this.writeString(description)
+}
+
 func (this *describer) describeNormally(v reflect.Value, isInUnsignedArray bool) {
+	if this.isOutOfRange(v) {
+		this.writeColored(colorOutOfRange, tokOutOfRange)
+	}
 	switch v.Kind() {
 	case reflect.Bool, reflect.Float32, reflect.Float64,
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Complex64, reflect.Complex128:
-		this.writeFmt("%v", v)
+		this.writeColored(colorNumber, fmt.Sprintf("%v", v))
 	case reflect.Uint8:
 		this.describeUint8(uint8(v.Uint()), isInUnsignedArray)
 	case reflect.Uint16:
@@ -570,9 +1271,7 @@ func (this *describer) describeNormally(v reflect.Value, isInUnsignedArray bool)
 	case reflect.Uint:
 		this.describeUint(uint(v.Uint()), isInUnsignedArray)
 	case reflect.String:
-		this.writeString(tokOpenString)
-		this.writeString(v.String())
-		this.writeString(tokCloseString)
+		this.writeQuotedString(v.String())
 	case reflect.Slice, reflect.Array:
 		this.describeArray(v)
 	case reflect.Map:
@@ -589,19 +1288,28 @@ func (this *describer) describeNormally(v reflect.Value, isInUnsignedArray bool)
 		this.writeString(stringifyAddress(v.Uint()))
 	case reflect.UnsafePointer:
 		this.writeString(tokPointerPrefix)
-		this.writeString(stringifyAddress(uint64(v.UnsafeAddr())))
+		this.writeString(stringifyAddress(uint64(v.Pointer())))
 	case reflect.Invalid:
 		this.writeString(tokInvalid)
 	case reflect.Func:
 		this.describeFunc(v)
 	case reflect.Chan:
-		this.writeString(getTypeName(v.Type()))
+		this.describeChannel(v)
 	default:
 		this.writeString(notifyLibraryBug("unhandled type %v (kind %v): %v", v.Type(), v.Kind(), v))
 	}
 }
 
 func (this *describer) describeReflectedValue(v reflect.Value, isInsideUnsignedArray bool) {
+	// A field describer only applies to the field's value itself, and not to
+	// what it leads to.
+	fieldDescriber := this.pendingFieldDescriber
+	this.pendingFieldDescriber = nil
+
+	if this.isOutputFull() {
+		return
+	}
+
 	if this.tryDescribeNil(v) {
 		return
 	}
@@ -614,46 +1322,218 @@ func (this *describer) describeReflectedValue(v reflect.Value, isInsideUnsignedA
 		return
 	}
 
+	if this.tryUseFieldDescriber(v, fieldDescriber) {
+		return
+	}
+
+	if this.tryDescribeTypeDepthExceeded(v) {
+		return
+	}
+
+	this.markIfPoisoned(v)
+
+	if this.tryDescribeTime(v) {
+		return
+	}
+
 	if this.tryUseCustomDescriber(v) {
 		return
 	}
 
+	if this.tryDescribeDescribable(v) {
+		return
+	}
+
+	if this.tryDescribeErrorStack(v) {
+		return
+	}
+
+	if this.tryDescribeErrorChain(v) {
+		return
+	}
+
+	if this.tryDescribeMultiError(v) {
+		return
+	}
+
 	if this.tryUseStringerDescriber(v) {
 		return
 	}
 
+	if this.tryUseGoStringerDescriber(v) {
+		return
+	}
+
+	if this.tryDescribeDepthExceeded(v) {
+		return
+	}
+
+	this.enterTypeDepth(v)
 	this.describeNormally(v, isInsideUnsignedArray)
+	this.exitTypeDepth(v)
+}
+
+// Describe a value that lives at a sub-path of the current value (a struct
+// field, array element, or map value).
+func (this *describer) describeChild(segment pathSegment, v reflect.Value, isInsideUnsignedArray bool) {
+	this.path = append(this.path, segment)
+	this.depth++
+	start := this.outputLength()
+	this.describeReflectedValue(v, isInsideUnsignedArray)
+	this.indexPath(start)
+	this.annotate(v)
+	this.depth--
+	this.path = this.path[:len(this.path)-1]
+}
+
+func (this *describer) annotate(v reflect.Value) {
+	if this.annotator == nil && this.sizer == nil {
+		return
+	}
+	annotation := this.getEncodedSizeAnnotation(v)
+	if this.annotator != nil {
+		if userAnnotation := this.annotator(this.currentPath(), v); userAnnotation != "" {
+			if annotation != "" {
+				annotation += ", "
+			}
+			annotation += userAnnotation
+		}
+	}
+	if annotation != "" {
+		this.writeString(tokAnnotationPrefix)
+		if this.annotations == AnnotateComments {
+			this.writeString(tokOpenAnnotationComment)
+			// Don't let the annotation end the comment early.
+			this.writeString(strings.Replace(annotation, "*/", "* /", -1))
+			this.writeString(tokCloseAnnotationComment)
+		} else {
+			this.writeString(tokOpenAnnotation)
+			this.writeString(annotation)
+			this.writeString(tokCloseAnnotation)
+		}
+	}
 }
 
 func (this *describer) sanityCheck() {
 	if this.indentStep > maxIndentStep {
 		panic(fmt.Errorf("Sanity check fail: indent step %v > max of %v", this.indentStep, maxIndentStep))
 	}
+	if this.baseIndent > maxBaseIndent {
+		panic(fmt.Errorf("Sanity check fail: base indent %v > max of %v", this.baseIndent, maxBaseIndent))
+	}
 }
 
 func (this *describer) describe(v interface{}) (description string) {
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	this.captureCorpusValue(rv)
+	if description, ok = this.tryDescribeCompact(rv); !ok {
+		description = this.describeValue(rv)
+	}
+	if history := loadHistory(); history != nil && this.streamOutput == nil {
+		history.record(description)
+	}
+	return
+}
+
+func (this *describer) describeValue(rv reflect.Value) (description string) {
+	return this.describeRoots([]reflect.Value{rv}, func() string {
+		this.memoizedDescriptions = nil
+		this.rootOccurrences = nil
+		if this.rootType != nil {
+			this.rootOccurrences = findTypeOccurrences(rv, this.rootType)
+		}
+		if this.breadthFirstBudget > 0 {
+			return this.describeBreadthFirst(rv)
+		}
+		return this.describeOnce(rv)
+	})
+}
+
+// Run describeFn to describe roots, with the setup and finishing that all
+// descriptions share: sanity checks, finding duplicates (across all roots),
+// the output limit, and the checksum.
+func (this *describer) describeRoots(roots []reflect.Value, describeFn func() string) (description string) {
 	defer func() {
 		// Allow panic to escape if debugging
 		if !DebugPanics {
 			if e := recover(); e != nil {
-				description = notifyLibraryBug("%v", e)
+				description = notifyRecoveredLibraryBug(e)
 			}
 		}
 	}()
 
 	this.sanityCheck()
 
-	rv, ok := v.(reflect.Value)
-	if !ok {
-		rv = reflect.ValueOf(v)
+	this.referenceNames = this.findDuplicates(roots...)
+	if this.maxOutputBytes > 0 && this.streamOutput == nil {
+		// Describing stops just past the limit, so that we know it was
+		// exceeded. (Breadth-first descriptions set their own limit.)
+		this.outputLimit = this.maxOutputBytes + 1
+	}
+	description = describeFn()
+	if this.maxOutputBytes > 0 && len(description) > this.maxOutputBytes {
+		description = truncateDescription(description, this.maxOutputBytes)
+		if this.index != nil {
+			this.index.clip(len(description))
+		}
+	}
+	if this.appendChecksum && this.streamOutput == nil {
+		description += getChecksumTrailer(crc32.ChecksumIEEE([]byte(description)))
 	}
+	return
+}
 
-	this.referenceNames = findDuplicates(rv)
+func (this *describer) describeOnce(rv reflect.Value) string {
+	for ptr := range this.referenceNames {
+		this.referenceNames[ptr] = 0
+	}
+	this.lastReferenceName = 0
 	this.stringBuilder.Reset()
+	this.lastNewlineEnd = 0
 	this.seenReferences = make(map[duplicates.TypedPointer]bool)
-	this.describeReflectedValue(rv, false)
-	description = this.stringBuilder.String()
-	return
+	this.path = this.path[:0]
+	this.depth = 0
+	this.typeDepths = nil
+	if this.index != nil {
+		this.index.reset()
+	}
+	this.writeBaseIndent()
+	switch len(this.rootOccurrences) {
+	case 0:
+		this.describeReflectedValue(rv, false)
+		this.indexPath(0)
+		this.annotate(rv)
+	case 1:
+		this.describeReflectedValue(this.rootOccurrences[0], false)
+		this.indexPath(0)
+		this.annotate(this.rootOccurrences[0])
+	default:
+		this.describeRootOccurrences()
+		this.indexPath(0)
+	}
+	return this.stringBuilder.String()
+}
+
+// Start a top-level description at the base indent (see WithBaseIndent()).
+func (this *describer) writeBaseIndent() {
+	this.currentIndent = 0
+	if this.indentStep > 0 && this.baseIndent > 0 {
+		this.currentIndent = this.baseIndent
+		this.writeString(strings.Repeat(tokIndent, this.baseIndent))
+	}
+}
+
+func (this *describer) isOutputFull() bool {
+	if this.fixedOutput != nil {
+		return this.fixedOutput.IsFull()
+	}
+	if this.streamOutput != nil {
+		return this.streamOutput.IsFull()
+	}
+	return this.outputLimit > 0 && this.stringBuilder.Len() >= this.outputLimit
 }
 
 // ----------
@@ -664,31 +1544,123 @@ func (this *describer) describe(v interface{}) (description string) {
 // This is useful for tracing the cause of the panic.
 var DebugPanics bool = false
 
+// If enabled, panic when a bug in this library is detected (such as an
+// unhandled reflect.Kind) instead of embedding a `go-describe.BUG(...)`
+// message in the description. Unlike DebugPanics, other panics (such as from
+// custom describers) are still recovered.
+//
+// This is useful in tests, so that regressions (for example after a go
+// version or dependency bump) fail loudly rather than producing corrupted
+// descriptions.
+var PanicOnLibraryBug bool = false
+
+// If set, this is called with a description of any bug detected in this
+// library, before the `go-describe.BUG(...)` message is embedded in the
+// description (or before panicking if PanicOnLibraryBug is set).
+var LibraryBugHandler func(description string)
+
 // If disabled, nested reflect.Value structures cannot be examined.
 // This switch does nothing if compiled with `-tags safe` or if compiled for
 // GopherJS or AppEngine, whereby unsafe operations won't even be compiled in.
 var EnableUnsafeOperations = true
 
+// If enabled, buffered channels will also report their queued element count
+// and capacity, like `chan<int>(len=3 cap=10)`.
+//
+// The queued elements themselves are not described, since they can't be read
+// without holding the channel's lock (which is private to the runtime).
+//
+// This is meant for debugging producer/consumer imbalances; leave it disabled
+// otherwise.
+var EnableChannelLengths = false
+
 // Describes an object in a single line or multiple lines of text. See package
 // description for information about how data is represented.
 //
 // if indentStep > 0, it will print in multiline mode, indenting that number of
 // spaces when it enters a struct/map/array/slice.
+//
+// This is the same as `DescribeOpts(v, WithIndent(indentStep))`. Use
+// DescribeOpts() for access to the other options.
 func Describe(v interface{}, indentStep int) (description string) {
+	return DescribeOpts(v, WithIndent(indentStep))
+}
+
+// Describes an object using the supplied options. With no options, this is the
+// same as `Describe(v, 0)`.
+func DescribeOpts(v interface{}, opts ...Option) (description string) {
+	context := describer{}
+	context.applyOptions(opts)
+	description = context.describe(v)
+	return
+}
+
+// Describes a value that was already obtained through reflection, using the
+// supplied options. Unlike passing v.Interface() to DescribeOpts(), this keeps
+// v's addressability, so that structs and arrays referenced from elsewhere in
+// the object graph are detected as duplicates via their addresses.
+func DescribeValue(v reflect.Value, opts ...Option) (description string) {
 	context := describer{}
-	context.indentStep = indentStep
+	context.applyOptions(opts)
 	description = context.describe(v)
 	return
 }
 
+// Describes the value that ptr points to, using the supplied options.
+//
+// Describe(v) receives a copy of v, which isn't addressable, so references back
+// to v from within its own contents (such as a struct containing a pointer to
+// itself) aren't recognized as cycles until the second time around. Describe(&v)
+// shows the pointer, and DescribeAddressable(&v) describes v itself with full
+// addressability, so that such references are marked at the outermost level:
+//
+//     v.Self = &v
+//     Describe(v, 0)            // T<Self=*1~T<Self=*$1>>
+//     DescribeAddressable(&v)   // 1~T<Self=*$1>
+//
+// If ptr isn't a non-nil pointer, it's described as-is.
+func DescribeAddressable(ptr interface{}, opts ...Option) (description string) {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return DescribeValue(rv, opts...)
+}
+
+// Describes a value for internal purposes (such as sorting). These don't
+// count as described values for corpus capture.
+func describeInternal(v reflect.Value, opts ...Option) string {
+	context := describer{}
+	context.applyOptions(opts)
+	return context.describeValue(v)
+}
+
+// Writes the description of an object (using the supplied options) directly to
+// w, rather than building it in memory first. This is useful when describing
+// large object graphs for logs or debug endpoints.
+//
+// Returns the first error returned by w, after which describing stops.
+//
+// Note: With WithBreadthFirstBudget(), the description is built in memory
+//       regardless, since it takes multiple passes.
+func DescribeTo(w io.Writer, v interface{}, opts ...Option) error {
+	context := describer{}
+	context.applyOptions(opts)
+	return context.describeTo(w, v)
+}
+
 // Alias to `Describe(v, 0)`. Call `describe.D(myobject)` to get a one-line
 // description for logging, debugging, etc.
 func D(v interface{}) (description string) {
-	return Describe(v, 0)
+	return DescribeOpts(v)
 }
 
 // User-defined value describer. Pass to SetCustomDescriber() when you want
 // different behavior from the default.
+//
+// If it returns an empty string, the type name followed by `<>` is used
+// instead. To describe child values as part of the description, use a
+// ContextDescriber instead.
 type CustomDescriber func(reflect.Value) string
 
 // Add a custom describer for a data type.
@@ -702,12 +1674,79 @@ type CustomDescriber func(reflect.Value) string
 // The convention is to print the type name, followed by a description
 // enclosed in <>. Example: net.URL<http://example.com>
 //
-// Passing a nil describer will disable the custom describer for that type.
+// Passing a nil describer will disable the custom describer for that type (and
+// for its counterpart, if it was set using MatchPointersAndValues).
 //
 // Note: t should be a concrete type rather than a pointer or interface type.
+//       Use SetCustomDescriberForInterface() for interface types,
+//       SetCustomDescriberForKind() for all types of a kind, and
+//       SetCustomDescriberMatching() to match both t and *t.
 //
 // Note: url.URL and time.Time already have custom describers by default, but
 //       you can override or disable them if you wish.
 func SetCustomDescriber(t reflect.Type, describer CustomDescriber) {
-	customDescribers.Store(t, describer)
+	SetContextDescriber(t, contextDescriberOf(describer))
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// Register String()-based custom describers for the types of the given example
+// values in one call, so that applications with many domain types don't need
+// a SetCustomDescriber() call for each:
+//
+//     describe.RegisterStringersFromValues(user.ID(0), order.Status(0), &cart.Cart{})
+//
+// Pointers are followed to their element types, and String() may have either
+// a value or pointer receiver. Unlike the default handling of String() methods,
+// the describers also apply to zero values. Values whose String() method can't
+// be called (in unexported fields, when unsafe operations aren't available)
+// are described as usual.
+//
+// Panics if any of the types doesn't have a String() method.
+func RegisterStringersFromValues(values ...interface{}) {
+	for _, value := range values {
+		t := reflect.TypeOf(value)
+		// A pointer type can point to itself (`type P *P`).
+		for t != nil && t.Kind() == reflect.Ptr && t.Elem() != t {
+			t = t.Elem()
+		}
+		if t == nil || !(t.Implements(stringerType) || reflect.PtrTo(t).Implements(stringerType)) {
+			panic(fmt.Errorf("RegisterStringersFromValues: %v does not implement fmt.Stringer", t))
+		}
+		SetContextDescriber(t, describeUsingStringer)
+	}
+}
+
+// A context describer that uses v's String() method (on either a value or
+// pointer receiver).
+func describeUsingStringer(ctx *DescribeContext, v reflect.Value) {
+	if !v.CanInterface() {
+		ctx.describeDefault(v)
+		return
+	}
+	stringer := v
+	if !v.Type().Implements(stringerType) {
+		if v.CanAddr() {
+			stringer = v.Addr()
+		} else {
+			stringer = reflect.New(v.Type())
+			stringer.Elem().Set(v)
+		}
+	}
+	fmt.Fprintf(ctx, `%v%v%v%v`, v.Type(), tokOpenStruct, stringer.Interface().(fmt.Stringer).String(), tokCloseStruct)
+}
+
+// Callback for panics recovered from custom describers. See SetPanicHandler().
+type PanicHandler func(t reflect.Type, recovered interface{}, stack []byte)
+
+// Set a handler to be called whenever a panic from a custom describer (or from
+// a String() method) is recovered, so that applications can log or count them
+// rather than only seeing `panic(...)` in the description.
+//
+// The handler receives the type being described, the recovered value, and
+// the stack trace of the panic.
+//
+// Passing nil will remove the handler.
+func SetPanicHandler(handler PanicHandler) {
+	panicHandler.Store(handler)
 }
@@ -0,0 +1,208 @@
+package describe
+
+import (
+	"reflect"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// A flattened key/value pair, as produced by OTelAttributes().
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Limits on what OTelAttributes() produces. Zero means no limit.
+type AttributeLimits struct {
+	// The maximum number of attributes. Any more are dropped.
+	MaxAttributes int
+	// The maximum length of a value in bytes. Longer values are truncated to
+	// this length, including the `…` that they end with.
+	MaxValueLength int
+	// The maximum depth to flatten to. Containers at this depth are described
+	// as a single value.
+	MaxDepth int
+}
+
+// OTelAttributes flattens v into key/value pairs that are suitable for use as
+// OpenTelemetry span (or log record) attributes, so that rich object context
+// can be attached to traces:
+//
+//	for _, a := range describe.OTelAttributes(req, "request", limits) {
+//		span.SetAttributes(attribute.String(a.Key, a.Value))
+//	}
+//
+// Struct fields, array/slice elements and map values are flattened into one
+// attribute per value, keyed by prefix followed by the value's path (as with
+// WithAnnotator()): `request.Headers["Accept"]`
+//
+// Pointers and interfaces are followed. Strings are used as-is, and other
+// values (including values described by user code, nil values and empty
+// containers) are described using opts. Data that refers back to a container
+// that encloses it is given the value `^`, and containers that were already
+// flattened elsewhere are given the value `$` followed by the key prefix they
+// were flattened under (such as `$request.Session`). Map entries are
+// flattened in order of their keys.
+func OTelAttributes(v interface{}, prefix string, limits AttributeLimits, opts ...Option) []Attribute {
+	context := describer{}
+	context.applyOptions(opts)
+
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.flattenAttributes(rv, prefix, limits)
+}
+
+func (this *describer) flattenAttributes(v reflect.Value, prefix string, limits AttributeLimits) (attributes []Attribute) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				attributes = []Attribute{{Key: prefix, Value: notifyRecoveredLibraryBug(e)}}
+			}
+		}
+	}()
+
+	this.sanityCheck()
+	flattener := attributeFlattener{
+		context:   this,
+		prefix:    prefix,
+		limits:    limits,
+		onPath:    make(map[duplicates.TypedPointer]bool),
+		flattened: make(map[duplicates.TypedPointer]string),
+	}
+	flattener.flatten(v, 0)
+	return flattener.attributes
+}
+
+type attributeFlattener struct {
+	context *describer
+	prefix  string
+	limits  AttributeLimits
+	path    []pathSegment
+	onPath  map[duplicates.TypedPointer]bool
+	// The keys that containers were first flattened under.
+	flattened  map[duplicates.TypedPointer]string
+	attributes []Attribute
+}
+
+func (this *attributeFlattener) isFull() bool {
+	return this.limits.MaxAttributes > 0 && len(this.attributes) >= this.limits.MaxAttributes
+}
+
+func (this *attributeFlattener) add(value string) {
+	if maxLength := this.limits.MaxValueLength; maxLength > 0 && len(value) > maxLength {
+		if maxLength > len(tokElided) {
+			value = truncateDescription(value, maxLength-len(tokElided))
+		} else {
+			value = truncateDescription(value, maxLength)
+			value = value[:len(value)-len(tokElided)]
+		}
+	}
+	this.attributes = append(this.attributes, Attribute{Key: this.getKey(), Value: value})
+}
+
+// Get the key for the current path.
+func (this *attributeFlattener) getKey() string {
+	key := this.prefix
+	if path := buildPath(this.path, &this.context.options); path != "" {
+		if key != "" && path[0] != '[' {
+			key += "."
+		}
+		key += path
+	}
+	return key
+}
+
+func (this *attributeFlattener) flattenChild(segment pathSegment, v reflect.Value, depth int) {
+	this.path = append(this.path, segment)
+	this.flatten(v, depth)
+	this.path = this.path[:len(this.path)-1]
+}
+
+func (this *attributeFlattener) flatten(v reflect.Value, depth int) {
+	if this.isFull() {
+		return
+	}
+	v = followPointers(v, this.isLeaf)
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !this.isLeaf(v) {
+		// The pointer chain leads back to itself.
+		this.add(tokEnclosingReference)
+		return
+	}
+	if this.isLeaf(v) || (this.limits.MaxDepth > 0 && depth >= this.limits.MaxDepth) {
+		this.addLeaf(v)
+		return
+	}
+
+	if ptr, ok := getReferencePointer(v); ok {
+		if this.onPath[ptr] {
+			this.add(tokEnclosingReference)
+			return
+		}
+		if key, ok := this.flattened[ptr]; ok {
+			this.add(tokReferencePrefix + key)
+			return
+		}
+		this.flattened[ptr] = this.getKey()
+		this.onPath[ptr] = true
+		defer delete(this.onPath, ptr)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, i := range getVisibleFieldIndices(v) {
+			this.flattenChild(fieldSegment(v.Type().Field(i).Name), this.context.getDescribedField(v, i), depth+1)
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			this.flattenChild(indexSegment(i), v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		iter := sortedMapRange(v, WithReflectionOnly(this.context.reflectionOnly))
+		for iter.Next() {
+			this.flattenChild(keySegment(iter.Key()), this.context.getDescribedMapValue(iter.Key(), iter.Value()), depth+1)
+		}
+	}
+}
+
+// Returns true if v is described as a single value rather than flattened.
+func (this *attributeFlattener) isLeaf(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.NumField() == 0 || v.Type() == timeType {
+			return true
+		}
+	case reflect.Array, reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			return true
+		}
+		if v.Kind() != reflect.Map && v.Type().Elem().Kind() == reflect.Uint8 {
+			// Byte arrays read better as a whole.
+			return true
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+	default:
+		return true
+	}
+	if this.context.isDescribedByUserCode(v) {
+		return true
+	}
+	_, _, isDescribable := this.context.getDescribableProtocol(v)
+	return isDescribable
+}
+
+func (this *attributeFlattener) addLeaf(v reflect.Value) {
+	if v.IsValid() && v.Kind() == reflect.String && !this.context.isDescribedByUserCode(v) {
+		this.add(v.String())
+		return
+	}
+	this.add(this.context.describeWithUserCode(v))
+}
@@ -0,0 +1,111 @@
+package describe
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+type AttributeRequest struct {
+	Method  string
+	URL     *url.URL
+	Headers map[string][]string
+	Body    []byte
+	Retries int
+	Session *AttributeSession
+	Err     error
+}
+
+type AttributeSession struct {
+	User    string
+	Request *AttributeRequest
+}
+
+func describeAttributes(attributes []Attribute) string {
+	description := ""
+	for _, a := range attributes {
+		description += fmt.Sprintf("%v=%v\n", a.Key, a.Value)
+	}
+	return description
+}
+
+func newAttributeRequest() *AttributeRequest {
+	u, _ := url.Parse("http://example.com/path")
+	req := &AttributeRequest{
+		Method:  "GET",
+		URL:     u,
+		Headers: map[string][]string{"Accept": {"text/html", "text/plain"}, "Host": {"example.com"}},
+		Body:    []byte{1, 2},
+		Session: &AttributeSession{User: "bob"},
+	}
+	req.Session.Request = req
+	return req
+}
+
+func TestOTelAttributes(t *testing.T) {
+	expected := `request.Method=GET
+request.URL=*url.URL<http://example.com/path>
+request.Headers["Accept"][0]=text/html
+request.Headers["Accept"][1]=text/plain
+request.Headers["Host"][0]=example.com
+request.Body=uint8[0x01 0x02]
+request.Retries=0
+request.Session.User=bob
+request.Session.Request=^
+request.Err=nil
+`
+	actual := describeAttributes(OTelAttributes(newAttributeRequest(), "request", AttributeLimits{}))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestOTelAttributesLimits(t *testing.T) {
+	expected := `request.Method=GET
+request.URL=*url.URL<http…
+request.Headers=string:[]stri…
+request.Body=uint8[0x01 0x02]
+`
+	limits := AttributeLimits{MaxAttributes: 4, MaxValueLength: 16, MaxDepth: 1}
+	actual := describeAttributes(OTelAttributes(newAttributeRequest(), "request", limits, WithSortedMapKeys(true)))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "[\"a\"]=ab…\n[\"b\"]=ab\n"
+	actual = describeAttributes(OTelAttributes(map[string]string{"a": "abcdef", "b": "ab"}, "", AttributeLimits{MaxValueLength: 5}))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "=a\n"
+	actual = describeAttributes(OTelAttributes("abcdef", "", AttributeLimits{MaxValueLength: 1}))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestOTelAttributesScalar(t *testing.T) {
+	expected := "count=5\n"
+	actual := describeAttributes(OTelAttributes(5, "count", AttributeLimits{}))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "[0]=a\n[1]=nil\n"
+	actual = describeAttributes(OTelAttributes([]interface{}{"a", nil}, "", AttributeLimits{}))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestOTelAttributesShared(t *testing.T) {
+	session := &AttributeSession{User: "bob"}
+	v := []*AttributeSession{session, session}
+
+	expected := "sessions[0].User=bob\nsessions[0].Request=nil\nsessions[1]=$sessions[0]\n"
+	actual := describeAttributes(OTelAttributes(v, "sessions", AttributeLimits{}))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
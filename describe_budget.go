@@ -0,0 +1,59 @@
+package describe
+
+import (
+	"reflect"
+	"unicode/utf8"
+)
+
+// Describe rv at increasing depths until the description no longer fits
+// within the breadth-first budget, so that the budget is spent evenly across
+// each level rather than on the first subtree.
+func (this *describer) describeBreadthFirst(rv reflect.Value) string {
+	budget := this.breadthFirstBudget
+	// Don't go deeper than WithMaxDepth() allows (if set).
+	hasMaxDepth, maxDepth := this.limitDepth, this.maxDepth
+	this.limitDepth = true
+	// Describing stops just past the budget, so that we know it was exceeded.
+	this.outputLimit = budget + 1
+
+	previous := ""
+	var previousIndex DescriptionIndex
+	for depth := 0; ; depth++ {
+		this.maxDepth = depth
+		this.didElideDepth = false
+		description := this.describeOnce(rv)
+		if len(description) > budget {
+			if depth == 0 {
+				description = truncateDescription(description, budget)
+				if this.index != nil {
+					this.index.clip(len(description))
+				}
+				return description
+			}
+			if this.index != nil {
+				this.index.copyFrom(&previousIndex)
+			}
+			return previous
+		}
+		if !this.didElideDepth || (hasMaxDepth && depth >= maxDepth) {
+			return description
+		}
+		previous = description
+		if this.index != nil {
+			previousIndex.copyFrom(this.index)
+		}
+	}
+}
+
+// Truncate a description to at most maxBytes (not counting the truncation
+// marker), without splitting a UTF-8 character.
+func truncateDescription(description string, maxBytes int) string {
+	if len(description) <= maxBytes {
+		return description
+	}
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(description[end]) {
+		end--
+	}
+	return description[:end] + tokElided
+}
@@ -0,0 +1,29 @@
+package describe
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+const checksumTrailerPrefix = " #crc32:"
+
+// Get the checksum trailer for a description: ` #crc32:1a2b3c4d`
+func getChecksumTrailer(checksum uint32) string {
+	return fmt.Sprintf("%v%08x", checksumTrailerPrefix, checksum)
+}
+
+// Verify the checksum trailer of a description that was made using
+// WithChecksum(), returning the description without its trailer.
+//
+// ok is false if the trailer is missing (for example because the description
+// was truncated) or doesn't match the description (because it was modified).
+func VerifyChecksum(described string) (description string, ok bool) {
+	index := strings.LastIndex(described, checksumTrailerPrefix)
+	if index < 0 {
+		return described, false
+	}
+	description = described[:index]
+	ok = described[index:] == getChecksumTrailer(crc32.ChecksumIEEE([]byte(description)))
+	return
+}
@@ -0,0 +1,51 @@
+package describe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	expected := "int[1 2 3] #crc32:de28fa62"
+	actual := DescribeOpts([]int{1, 2, 3}, WithChecksum(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	description, ok := VerifyChecksum(actual)
+	if !ok || description != "int[1 2 3]" {
+		t.Errorf("Expected checksum of %v to verify", actual)
+	}
+
+	if _, ok := VerifyChecksum(strings.Replace(actual, "2", "4", 1)); ok {
+		t.Errorf("Expected modified description to fail verification")
+	}
+	if _, ok := VerifyChecksum(actual[:8]); ok {
+		t.Errorf("Expected truncated description to fail verification")
+	}
+}
+
+func TestChecksumStream(t *testing.T) {
+	builder := strings.Builder{}
+	if err := DescribeTo(&builder, []int{1, 2, 3}, WithChecksum(true)); err != nil {
+		t.Error(err)
+	}
+	expected := "int[1 2 3] #crc32:de28fa62"
+	actual := builder.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	builder.Reset()
+	if err := DescribeTo(&builder, []int{1, 2, 3}, WithChecksum(true), WithMaxOutputBytes(5)); err != nil {
+		t.Error(err)
+	}
+	if _, ok := VerifyChecksum(builder.String()); !ok {
+		t.Errorf("Expected checksum of %v to verify", builder.String())
+	}
+	expected = DescribeOpts([]int{1, 2, 3}, WithChecksum(true), WithMaxOutputBytes(5))
+	actual = builder.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -0,0 +1,84 @@
+package describe
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+)
+
+// When to color descriptions using ANSI escape codes. See WithColors().
+type ColorMode int
+
+const (
+	// Never color descriptions (default)
+	ColorsNever ColorMode = iota
+	// Always color descriptions
+	ColorsAlways
+	// Color descriptions written via DescribeTo() if the writer is a terminal
+	// (see CanColorize()). Descriptions returned as strings are never colored.
+	ColorsIfTerminal
+)
+
+const (
+	colorReset     = "\x1b[0m"
+	colorTypeName  = "\x1b[36m"
+	colorFieldName = "\x1b[34m"
+	colorString    = "\x1b[32m"
+	colorNumber    = "\x1b[33m"
+	colorNil       = "\x1b[35m"
+	colorReference = "\x1b[31m"
+	// Bold red
+	colorOutOfRange = "\x1b[1;31m"
+)
+
+// Returns true if colored (ANSI escape coded) output can be written to w:
+//
+//   - The NO_COLOR environment variable (https://no-color.org) is not set
+//   - TERM is not "dumb"
+//   - w is a terminal (rather than a file, pipe, or buffer)
+//   - On Windows, the console supports virtual terminal processing (which is
+//     enabled if necessary)
+func CanColorize(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return enableTerminalColors(file)
+}
+
+var ansiEscapePattern = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]")
+
+// Remove ANSI escape sequences (such as colors) from s, for writing colored
+// descriptions to destinations that don't support them.
+func StripColors(s string) string {
+	return ansiEscapePattern.ReplaceAllString(s, "")
+}
+
+// Resolve ColorsIfTerminal now that the destination is known.
+func (this *describer) resolveColors(w io.Writer) {
+	if this.colors == ColorsIfTerminal {
+		if CanColorize(w) {
+			this.colors = ColorsAlways
+		} else {
+			this.colors = ColorsNever
+		}
+	}
+}
+
+func (this *describer) writeColored(color string, s string) {
+	if this.colors != ColorsAlways {
+		this.writeString(s)
+		return
+	}
+	this.writeString(color)
+	this.writeString(s)
+	this.writeString(colorReset)
+}
+
+func (this *describer) writeTypeName(t reflect.Type) {
+	this.writeColored(colorTypeName, this.typeName(t))
+}
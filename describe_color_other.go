@@ -0,0 +1,12 @@
+// +build !windows
+
+package describe
+
+import (
+	"os"
+)
+
+func enableTerminalColors(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
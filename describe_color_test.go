@@ -0,0 +1,85 @@
+package describe
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestStripColors(t *testing.T) {
+	expected := `describe.InnerStruct<number=1>`
+	actual := StripColors("\x1b[36mdescribe.InnerStruct\x1b[0m<\x1b[1;34mnumber\x1b[0m=\x1b[33m1\x1b[m>")
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCanColorize(t *testing.T) {
+	if CanColorize(&bytes.Buffer{}) {
+		t.Errorf("Expected a buffer not to be colorized")
+	}
+
+	oldNoColor, hadNoColor := os.LookupEnv("NO_COLOR")
+	os.Setenv("NO_COLOR", "1")
+	defer func() {
+		if hadNoColor {
+			os.Setenv("NO_COLOR", oldNoColor)
+		} else {
+			os.Unsetenv("NO_COLOR")
+		}
+	}()
+	if CanColorize(os.Stdout) {
+		t.Errorf("Expected NO_COLOR to disable colors")
+	}
+}
+
+type coloredStruct struct {
+	Name  string
+	Count int
+	Next  *coloredStruct
+}
+
+func TestColors(t *testing.T) {
+	v := &coloredStruct{Name: "a", Count: 1}
+	v.Next = v
+
+	expected := "*\x1b[31m1~\x1b[0m\x1b[36mdescribe.coloredStruct\x1b[0m<" +
+		"\x1b[34mName\x1b[0m=\x1b[32m\"a\"\x1b[0m " +
+		"\x1b[34mCount\x1b[0m=\x1b[33m1\x1b[0m " +
+		"\x1b[34mNext\x1b[0m=*\x1b[31m$1\x1b[0m>"
+	actual := DescribeOpts(v, WithColors(ColorsAlways))
+	if actual != expected {
+		t.Errorf("Expected %q but got %q", expected, actual)
+	}
+
+	expected = DescribeOpts(v)
+	actual = StripColors(actual)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "\x1b[35mnil\x1b[0m"
+	actual = DescribeOpts((*int)(nil), WithColors(ColorsAlways))
+	if actual != expected {
+		t.Errorf("Expected %q but got %q", expected, actual)
+	}
+}
+
+func TestColorsIfTerminal(t *testing.T) {
+	v := coloredStruct{Name: "a"}
+	expected := DescribeOpts(v)
+
+	actual := DescribeOpts(v, WithColors(ColorsIfTerminal))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	buffer := bytes.Buffer{}
+	if err := DescribeTo(&buffer, v, WithColors(ColorsIfTerminal)); err != nil {
+		t.Error(err)
+	}
+	actual = buffer.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -0,0 +1,30 @@
+// +build windows
+
+package describe
+
+import (
+	"os"
+	"syscall"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// Consoles before Windows 10 don't understand ANSI escape codes, and newer
+// ones only do once virtual terminal processing is enabled.
+func enableTerminalColors(file *os.File) bool {
+	handle := syscall.Handle(file.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(handle, &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	if procSetConsoleMode.Find() != nil {
+		return false
+	}
+	result, _, _ := procSetConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing))
+	return result != 0
+}
@@ -0,0 +1,243 @@
+package describe
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// The largest threshold that WithCompactThreshold() allows, since this is the
+// size of the stack buffer that compact descriptions are built in.
+const maxCompactThreshold = 256
+
+// How to describe values of a type directly, for types whose descriptions are
+// small and never contain anything that needs tracking (such as references,
+// or the results of user code).
+type compactPlan struct {
+	kind       reflect.Kind
+	typeName   string
+	fieldNames []string
+	fields     []*compactPlan
+	// The plan's type and every type it contains, for checking against
+	// describers, detectors and tagged unions that may have been set since.
+	types []reflect.Type
+	// The maximum size of a description, not counting the contents of strings.
+	maxFixedSize int
+	hasStrings   bool
+}
+
+var compactPlans sync.Map // reflect.Type -> *compactPlan (nil if not compact)
+
+func getCompactPlan(t reflect.Type) *compactPlan {
+	if cached, ok := compactPlans.Load(t); ok {
+		return cached.(*compactPlan)
+	}
+	plan := newCompactPlan(t)
+	compactPlans.Store(t, plan)
+	return plan
+}
+
+// Make a plan for describing values of type t, or return nil if they can't be
+// described compactly.
+func newCompactPlan(t reflect.Type) *compactPlan {
+	if t.NumMethod() > 0 || reflect.PtrTo(t).NumMethod() > 0 {
+		// Methods such as String() or Describe() could change the description.
+		return nil
+	}
+
+	plan := &compactPlan{
+		kind:  t.Kind(),
+		types: []reflect.Type{t},
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		plan.maxFixedSize = len("false")
+	case reflect.Int8:
+		plan.maxFixedSize = len("-128")
+	case reflect.Int16:
+		plan.maxFixedSize = len("-32768")
+	case reflect.Int32:
+		plan.maxFixedSize = len("-2147483648")
+	case reflect.Int, reflect.Int64:
+		plan.maxFixedSize = len("-9223372036854775808")
+	case reflect.Uint8:
+		plan.maxFixedSize = len("255")
+	case reflect.Uint16:
+		plan.maxFixedSize = len("65535")
+	case reflect.Uint32:
+		plan.maxFixedSize = len("4294967295")
+	case reflect.Uint, reflect.Uint64:
+		plan.maxFixedSize = len("18446744073709551615")
+	case reflect.Float32:
+		plan.maxFixedSize = len("-1.1754944e-38")
+	case reflect.Float64:
+		plan.maxFixedSize = len("-2.2250738585072014e-308")
+	case reflect.String:
+		plan.maxFixedSize = len(tokOpenString) + len(tokCloseString)
+		plan.hasStrings = true
+	case reflect.Struct:
+		plan.typeName = getTypeName(t)
+		plan.maxFixedSize = len(plan.typeName) + len(tokOpenStruct) + len(tokCloseStruct)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if _, ok := field.Tag.Lookup(describeTagName); ok {
+				return nil
+			}
+			fieldPlan := newCompactPlan(field.Type)
+			if fieldPlan == nil {
+				return nil
+			}
+			if i > 0 {
+				plan.maxFixedSize += len(tokItemSeparator)
+			}
+			plan.maxFixedSize += len(field.Name) + len(tokKeyValueSeparator) + fieldPlan.maxFixedSize
+			plan.hasStrings = plan.hasStrings || fieldPlan.hasStrings
+			plan.fieldNames = append(plan.fieldNames, field.Name)
+			plan.fields = append(plan.fields, fieldPlan)
+			plan.types = append(plan.types, fieldPlan.types...)
+		}
+	default:
+		return nil
+	}
+	return plan
+}
+
+// Get the maximum size of v's description.
+func (this *compactPlan) measure(v reflect.Value) int {
+	return this.maxFixedSize + this.measureStrings(v)
+}
+
+func (this *compactPlan) measureStrings(v reflect.Value) (size int) {
+	if !this.hasStrings {
+		return 0
+	}
+	if this.kind == reflect.String {
+		return v.Len()
+	}
+	for i, field := range this.fields {
+		size += field.measureStrings(v.Field(i))
+	}
+	return
+}
+
+// Append v's description to b, the same way describeNormally() would.
+func (this *compactPlan) appendDescription(b []byte, v reflect.Value) []byte {
+	switch this.kind {
+	case reflect.Bool:
+		return strconv.AppendBool(b, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(b, v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint(b, v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.AppendFloat(b, v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.AppendFloat(b, v.Float(), 'g', -1, 64)
+	case reflect.String:
+		b = append(b, tokOpenString...)
+		b = append(b, v.String()...)
+		return append(b, tokCloseString...)
+	case reflect.Struct:
+		b = append(b, this.typeName...)
+		b = append(b, tokOpenStruct...)
+		for i, field := range this.fields {
+			if i > 0 {
+				b = append(b, tokItemSeparator...)
+			}
+			b = append(b, this.fieldNames[i]...)
+			b = append(b, tokKeyValueSeparator...)
+			b = field.appendDescription(b, v.Field(i))
+		}
+		return append(b, tokCloseStruct...)
+	}
+	return b
+}
+
+// Check that no option would make the description differ from what a compact
+// plan produces.
+func (this *options) canDescribeCompact() bool {
+	return this.compactThreshold > 0 &&
+		this.indentStep == 0 &&
+		this.annotator == nil &&
+		this.sizer == nil &&
+		this.typeNames == TypeNamesPackage &&
+		!this.typeHashes &&
+		len(this.validRanges) == 0 &&
+		len(this.validFieldRanges) == 0 &&
+		len(this.fieldDescribers) == 0 &&
+		len(this.redactionPatterns) == 0 &&
+		len(this.typeDepthLimits) == 0 &&
+		(!this.limitDepth || this.maxDepth > 0) &&
+		this.breadthFirstBudget == 0 &&
+		this.rootType == nil &&
+		this.index == nil &&
+		this.quoting == QuoteDouble &&
+		this.maxStringLength == 0 &&
+		this.maxOutputBytes == 0 &&
+		!this.appendChecksum &&
+		this.colors != ColorsAlways
+}
+
+// Check that nothing has been set up to describe or mark any of the plan's
+// types differently.
+func (this *options) canUseCompactPlan(plan *compactPlan) bool {
+	for _, t := range plan.types {
+		if _, ok := this.getCustomDescriber(t); ok {
+			return false
+		}
+		if _, ok := fieldDescribers.Load(t); ok && !this.ignoreGlobalDescribers {
+			return false
+		}
+		if !this.reflectionOnly {
+			if _, ok := poisonDetectors.Load(t); ok {
+				return false
+			}
+		}
+		if t.Kind() == reflect.Struct {
+			if _, ok := getTaggedUnion(t); ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Describe rv via its type's compact plan, if it has one and the description
+// is guaranteed to fit within the compact threshold. See
+// WithCompactThreshold().
+func (this *options) tryDescribeCompact(rv reflect.Value) (description string, ok bool) {
+	if !rv.IsValid() || !this.canDescribeCompact() {
+		return
+	}
+	plan := getCompactPlan(rv.Type())
+	if plan == nil || !this.canUseCompactPlan(plan) {
+		return
+	}
+	threshold := this.compactThreshold
+	if threshold > maxCompactThreshold {
+		threshold = maxCompactThreshold
+	}
+	if plan.measure(rv) > threshold {
+		return
+	}
+
+	var buffer [maxCompactThreshold]byte
+	return string(plan.appendDescription(buffer[:0], rv)), true
+}
+
+// Describe v compactly (as describe() would), without needing a describer
+// context. See WithCompactThreshold().
+func (this *options) describeCompact(v interface{}) (description string, ok bool) {
+	rv, isValue := v.(reflect.Value)
+	if !isValue {
+		rv = reflect.ValueOf(v)
+	}
+	if description, ok = this.tryDescribeCompact(rv); !ok {
+		return
+	}
+	this.captureCorpusValue(rv)
+	if history := loadHistory(); history != nil {
+		history.record(description)
+	}
+	return
+}
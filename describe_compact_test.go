@@ -0,0 +1,154 @@
+package describe
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type CompactCelsius float64
+
+type CompactPoint struct {
+	X     int16
+	Y     int16
+	label string
+}
+
+type CompactSpan struct {
+	Name     string
+	Start    CompactPoint
+	Duration uint32
+	Sampled  bool
+	Ratio    float32
+	Temp     CompactCelsius
+}
+
+type CompactEmpty struct{}
+
+var compactValues = []interface{}{
+	true,
+	false,
+	int8(math.MinInt8),
+	int16(math.MinInt16),
+	int32(math.MinInt32),
+	int64(math.MinInt64),
+	int(-42),
+	uint8(math.MaxUint8),
+	uint16(math.MaxUint16),
+	uint32(math.MaxUint32),
+	uint64(math.MaxUint64),
+	uint(7),
+	float32(1.1754944e-38),
+	float32(-3.4028235e+38),
+	float32(0.1),
+	-2.2250738585072014e-308,
+	1e20,
+	1e21,
+	0.000012,
+	123456789.0,
+	math.Inf(-1),
+	math.NaN(),
+	CompactCelsius(-40.5),
+	"",
+	"a string with \"quotes\"\nand a newline",
+	CompactEmpty{},
+	CompactPoint{X: -1, Y: 2, label: "origin"},
+	CompactSpan{Name: "db.query", Start: CompactPoint{X: 3}, Duration: 1500, Sampled: true, Ratio: 0.25, Temp: 21.5},
+}
+
+func TestCompactThreshold(t *testing.T) {
+	for _, v := range compactValues {
+		expected := D(v)
+		actual := DescribeOpts(v, WithCompactThreshold(256))
+		if actual != expected {
+			t.Errorf("Expected %v but got %v", expected, actual)
+		}
+	}
+}
+
+func TestCompactThresholdFallback(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	expected := `"` + long + `"`
+	actual := DescribeOpts(long, WithCompactThreshold(20))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `int[1 2]`
+	actual = DescribeOpts([]int{1, 2}, WithCompactThreshold(256))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.CompactPoint<X=1 Y=2 label="a">`
+	actual = DescribeOpts(CompactPoint{X: 1, Y: 2, label: "a"}, WithCompactThreshold(256), WithIndent(0), WithMaxStringLength(5))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCompactThresholdCustomDescribers(t *testing.T) {
+	describeInt16 := func(v reflect.Value) string { return "i16" }
+	expected := `describe.CompactPoint<X=i16 Y=i16 label="">`
+	actual := DescribeOpts(CompactPoint{}, WithCompactThreshold(256), WithCustomDescriberForKind(reflect.Int16, describeInt16))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	pointType := reflect.TypeOf(CompactPoint{})
+	SetCustomDescriber(pointType, func(v reflect.Value) string { return "point" })
+	expected = `point`
+	actual = DescribeOpts(CompactPoint{}, WithCompactThreshold(256))
+	SetCustomDescriber(pointType, nil)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetFieldDescriber(pointType, "X", describeInt16)
+	expected = `describe.CompactPoint<X=i16 Y=0 label="">`
+	actual = DescribeOpts(CompactPoint{}, WithCompactThreshold(256))
+	SetFieldDescriber(pointType, "X", nil)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// The plan is cached by now, so the union must be checked when it's used.
+	SetTaggedUnion(pointType, "Y")
+	expected = `describe.CompactPoint<Y=2 X=1 label="">`
+	actual = DescribeOpts(CompactPoint{X: 1, Y: 2}, WithCompactThreshold(256))
+	SetTaggedUnion(pointType, "")
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCompactThresholdAllocations(t *testing.T) {
+	var v interface{} = CompactSpan{Name: "db.query", Duration: 1500, Sampled: true}
+	describer := NewDescriber(WithCompactThreshold(256))
+	expected := 1.0
+	actual := testing.AllocsPerRun(100, func() {
+		describer.Describe(v)
+	})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func BenchmarkCompactThreshold(b *testing.B) {
+	var v interface{} = CompactSpan{Name: "db.query", Duration: 1500, Sampled: true}
+	describer := NewDescriber(WithCompactThreshold(256))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		describer.Describe(v)
+	}
+}
+
+func BenchmarkWithoutCompactThreshold(b *testing.B) {
+	var v interface{} = CompactSpan{Name: "db.query", Duration: 1500, Sampled: true}
+	describer := NewDescriber()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		describer.Describe(v)
+	}
+}
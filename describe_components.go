@@ -0,0 +1,14 @@
+package describe
+
+// Describes a set of named components (for example the contents of a
+// dependency injection container) as a single object graph. Objects shared
+// between components (such as singletons) are marked as duplicates, so it's
+// visible that they're shared rather than them being described in full each
+// time. Example:
+//
+//	string:interface{"db"=@*1~main.DB<Host="x"> "users"=@*main.UserService<DB=*$1>}
+//
+// Components are listed in name order.
+func DescribeComponents(components map[string]interface{}, opts ...Option) string {
+	return DescribeOpts(components, append([]Option{WithSortedMapKeys(true)}, opts...)...)
+}
@@ -0,0 +1,282 @@
+package describe
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// A custom describer that writes its description to ctx, and can describe
+// child values (such as the elements of a container type) as part of it.
+// Children are described as they would be anywhere else in the description:
+// with the same options, depth limits, indentation, and marking of duplicates
+// and cycles.
+//
+// As with CustomDescriber, writing nothing results in the type name followed
+// by `<>`.
+type ContextDescriber func(ctx *DescribeContext, v reflect.Value)
+
+// The context that a ContextDescriber writes its description to. It's only
+// valid until the describer returns.
+//
+// For example, to describe a set type as a list of its elements:
+//
+//	func describeSet(ctx *describe.DescribeContext, v reflect.Value) {
+//		ctx.WriteString("Set[")
+//		for i, element := range v.Interface().(Set).Elements() {
+//			ctx.WriteItemSeparator(i == 0)
+//			ctx.Describe(reflect.ValueOf(element))
+//		}
+//		ctx.WriteClosingSeparator()
+//		ctx.WriteString("]")
+//	}
+type DescribeContext struct {
+	mutex     sync.Mutex
+	parent    *describer
+	output    bytes.Buffer
+	abandoned bool
+	// Copied from the parent, since a describer that timed out may still be
+	// reading them after the parent has moved on.
+	depth      int
+	indent     int
+	indentStep int
+}
+
+// Set a context describer for a data type. It replaces any describer set for t
+// using SetCustomDescriber(), and is otherwise treated the same way.
+//
+// Passing a nil describer will disable the custom describer for that type (and
+// for its counterpart, if it was set using MatchPointersAndValues).
+func SetContextDescriber(t reflect.Type, describer ContextDescriber) {
+	// A describer matched as t's counterpart is adapted from t's describer,
+	// so it must follow any change to it.
+	counterpart, adapted := adaptToCounterpart(t, describer)
+	if describer == nil {
+		customDescribers.Delete(t)
+		counterpartDescribers.Delete(counterpart)
+		return
+	}
+	customDescribers.Store(t, describer)
+	if _, ok := counterpartDescribers.Load(counterpart); ok {
+		counterpartDescribers.Store(counterpart, adapted)
+	}
+}
+
+// Describers are all stored as context describers.
+func contextDescriberOf(describer CustomDescriber) ContextDescriber {
+	if describer == nil {
+		return nil
+	}
+	return func(ctx *DescribeContext, v reflect.Value) {
+		ctx.WriteString(describer(v))
+	}
+}
+
+func (this *describer) newDescribeContext() *DescribeContext {
+	return &DescribeContext{
+		parent:     this,
+		depth:      this.depth,
+		indent:     this.currentIndent,
+		indentStep: this.indentStep,
+	}
+}
+
+// Stop the context from being used any further. A describer that timed out
+// may still be running in another goroutine, and must not touch the parent's
+// state once it continues. A child that it's still describing is discarded
+// when done, so this doesn't wait for it.
+func (this *DescribeContext) finish() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.abandoned = true
+}
+
+func (this *DescribeContext) String() string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	return this.output.String()
+}
+
+// Write to the description.
+func (this *DescribeContext) Write(p []byte) (n int, err error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	return this.output.Write(p)
+}
+
+// Write a string to the description.
+func (this *DescribeContext) WriteString(s string) (n int, err error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	return this.output.WriteString(s)
+}
+
+// Describe a child value, writing its description. The child is treated as
+// being one level deeper (and one indent step further in) than the value
+// being described.
+func (this *DescribeContext) Describe(child reflect.Value) {
+	nested := this.startNested()
+	if nested == nil {
+		return
+	}
+	nested.describeReflectedValue(child, false)
+	this.finishNested(nested)
+}
+
+// Make a nested describer for describing a child, or return nil if the
+// context was abandoned.
+func (this *DescribeContext) startNested() *describer {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if this.abandoned {
+		return nil
+	}
+	nested := this.parent.newNestedDescriber()
+	if this.parent.describerTimeout > 0 {
+		// The describer may be abandoned while the child is being described,
+		// after which the parent carries on using its state.
+		nested.copySharedState()
+	}
+	return nested
+}
+
+// Take on what a nested describer described, unless the context was abandoned
+// in the meantime.
+func (this *DescribeContext) finishNested(nested *describer) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if this.abandoned {
+		return
+	}
+	this.parent.adoptNestedDescriber(nested)
+	this.output.WriteString(nested.stringBuilder.String())
+}
+
+// Describe v as if it had no custom describer, for adapted describers that
+// turn out not to be able to describe it.
+func (this *DescribeContext) describeDefault(v reflect.Value) {
+	nested := this.startNested()
+	if nested == nil {
+		return
+	}
+	nested.currentIndent = this.indent
+	nested.depth = this.depth
+	nested.describeNormally(v, false)
+	this.finishNested(nested)
+}
+
+// Write what separates the children: a newline followed by their indentation
+// in multiline mode, or otherwise a space before all but the first child.
+func (this *DescribeContext) WriteItemSeparator(isFirst bool) {
+	if this.indentStep > 0 {
+		this.writeNewline(this.indent + this.indentStep)
+	} else if !isFirst {
+		this.WriteString(tokItemSeparator)
+	}
+}
+
+// Write what comes before the closing bracket (if any) after the children: a
+// newline followed by the value's indentation in multiline mode, or otherwise
+// nothing.
+func (this *DescribeContext) WriteClosingSeparator() {
+	if this.indentStep > 0 {
+		this.writeNewline(this.indent)
+	}
+}
+
+func (this *DescribeContext) writeNewline(indent int) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.output.WriteString(tokItemSeparatorMultiline)
+	for i := 0; i < indent; i++ {
+		this.output.WriteString(tokIndent)
+	}
+}
+
+// The depth of the value being described (0 for the top-level value).
+func (this *DescribeContext) Depth() int {
+	return this.depth
+}
+
+// The number of spaces that the value being described is indented by in
+// multiline mode.
+func (this *DescribeContext) Indent() int {
+	return this.indent
+}
+
+// The number of spaces to indent by per level, or 0 if describing on a single
+// line (see WithIndent()).
+func (this *DescribeContext) IndentStep() int {
+	return this.indentStep
+}
+
+// Make a describer that describes a child value into its own output, sharing
+// the reference, cycle and depth tracking of this one.
+func (this *describer) newNestedDescriber() *describer {
+	nested := &describer{
+		options:              this.options,
+		currentIndent:        this.currentIndent + this.indentStep,
+		referenceNames:       this.referenceNames,
+		lastReferenceName:    this.lastReferenceName,
+		seenReferences:       this.seenReferences,
+		path:                 append([]pathSegment(nil), this.path...),
+		depth:                this.depth + 1,
+		typeDepths:           this.typeDepths,
+		memoizedDescriptions: this.memoizedDescriptions,
+	}
+	// Offsets into the nested output wouldn't match the final description.
+	nested.index = nil
+	return nested
+}
+
+// Replace the state shared with the describer that this was nested in with
+// copies, so that changes to it only take effect once adopted.
+func (this *describer) copySharedState() {
+	if this.referenceNames != nil {
+		referenceNames := make(map[duplicates.TypedPointer]int, len(this.referenceNames))
+		for k, v := range this.referenceNames {
+			referenceNames[k] = v
+		}
+		this.referenceNames = referenceNames
+	}
+	if this.seenReferences != nil {
+		seenReferences := make(map[duplicates.TypedPointer]bool, len(this.seenReferences))
+		for k, v := range this.seenReferences {
+			seenReferences[k] = v
+		}
+		this.seenReferences = seenReferences
+	}
+	if this.typeDepths != nil {
+		typeDepths := make(map[reflect.Type]int, len(this.typeDepths))
+		for k, v := range this.typeDepths {
+			typeDepths[k] = v
+		}
+		this.typeDepths = typeDepths
+	}
+	if this.memoizedDescriptions != nil {
+		memoizedDescriptions := make(map[duplicates.TypedPointer]string, len(this.memoizedDescriptions))
+		for k, v := range this.memoizedDescriptions {
+			memoizedDescriptions[k] = v
+		}
+		this.memoizedDescriptions = memoizedDescriptions
+	}
+}
+
+// Take on the state that a nested describer changed.
+func (this *describer) adoptNestedDescriber(nested *describer) {
+	this.referenceNames = nested.referenceNames
+	this.seenReferences = nested.seenReferences
+	this.lastReferenceName = nested.lastReferenceName
+	this.didElideDepth = this.didElideDepth || nested.didElideDepth
+	this.typeDepths = nested.typeDepths
+	this.memoizedDescriptions = nested.memoizedDescriptions
+}
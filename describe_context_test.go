@@ -0,0 +1,161 @@
+package describe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type ContextBag struct {
+	elements []interface{}
+}
+
+var contextBagType = reflect.TypeOf(ContextBag{})
+
+func describeContextBag(ctx *DescribeContext, v reflect.Value) {
+	ctx.WriteString("Bag[")
+	bag := v.Interface().(ContextBag)
+	for i, element := range bag.elements {
+		ctx.WriteItemSeparator(i == 0)
+		ctx.Describe(reflect.ValueOf(element))
+	}
+	ctx.WriteClosingSeparator()
+	ctx.WriteString("]")
+}
+
+func TestContextDescriber(t *testing.T) {
+	SetContextDescriber(contextBagType, describeContextBag)
+	defer SetContextDescriber(contextBagType, nil)
+
+	shared := &InnerStruct{number: 1}
+	v := []interface{}{ContextBag{[]interface{}{1, "a", shared}}, shared}
+	expected := `interface[@Bag[1 "a" *1~describe.InnerStruct<number=1>] @*$1]`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `interface[
+    @Bag[
+        1
+        "a"
+        *1~describe.InnerStruct<
+            number = 1
+        >
+    ]
+    @*$1
+]`
+	actual = Describe(v, 4)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestContextDescriberDepth(t *testing.T) {
+	depths := []int{}
+	SetContextDescriber(contextBagType, func(ctx *DescribeContext, v reflect.Value) {
+		depths = append(depths, ctx.Depth())
+		describeContextBag(ctx, v)
+	})
+	defer SetContextDescriber(contextBagType, nil)
+
+	v := ContextBag{[]interface{}{ContextBag{[]interface{}{[]int{1}}}}}
+	expected := `Bag[Bag[int[…]]]`
+	actual := DescribeOpts(v, WithMaxDepth(2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	if !reflect.DeepEqual(depths, []int{0, 1}) {
+		t.Errorf("Expected depths [0 1] but got %v", depths)
+	}
+}
+
+func TestContextDescriberCycle(t *testing.T) {
+	SetContextDescriber(contextBagType, describeContextBag)
+	defer SetContextDescriber(contextBagType, nil)
+
+	v := &ContextBag{}
+	v.elements = []interface{}{v}
+	expected := `*1~Bag[*$1]`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestContextDescriberPanic(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	SetContextDescriber(contextBagType, func(ctx *DescribeContext, v reflect.Value) {
+		ctx.WriteString("Bag[")
+		panic("oops")
+	})
+	defer SetContextDescriber(contextBagType, nil)
+
+	expected := `panic(oops)`
+	actual := D(ContextBag{})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestContextDescriberOption(t *testing.T) {
+	expected := `Bag[1]`
+	actual := DescribeOpts(ContextBag{[]interface{}{1}}, WithContextDescriber(contextBagType, describeContextBag))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestContextDescriberTimeout(t *testing.T) {
+	finished := make(chan bool)
+	SetContextDescriber(contextBagType, func(ctx *DescribeContext, v reflect.Value) {
+		time.Sleep(50 * time.Millisecond)
+		// Abandoned by now, so this must not touch the describer.
+		describeContextBag(ctx, v)
+		finished <- true
+	})
+	defer SetContextDescriber(contextBagType, nil)
+
+	expected := `describe.ContextBag<describer timeout>`
+	actual := DescribeOpts(ContextBag{[]interface{}{1}}, WithDescriberTimeout(time.Millisecond))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	<-finished
+}
+
+type ContextBlocker struct {
+	release chan bool
+}
+
+func (this ContextBlocker) String() string {
+	<-this.release
+	return "released"
+}
+
+func TestContextDescriberTimeoutWhileDescribing(t *testing.T) {
+	SetContextDescriber(contextBagType, describeContextBag)
+	defer SetContextDescriber(contextBagType, nil)
+
+	release := make(chan bool)
+	defer close(release)
+	// Each blocker times out in turn, so the bag's describer is still
+	// describing them long after it has timed out itself.
+	blockers := make([]ContextBlocker, 20)
+	for i := range blockers {
+		blockers[i] = ContextBlocker{release}
+	}
+
+	start := time.Now()
+	expected := `describe.ContextBag<describer timeout>`
+	actual := DescribeOpts(ContextBag{[]interface{}{blockers, 1}}, WithDescriberTimeout(10*time.Millisecond), WithIndent(2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected the timeout to not wait for the child being described, but took %v", elapsed)
+	}
+}
@@ -0,0 +1,277 @@
+package describe
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// The format that captured corpus values are serialized in.
+type CorpusFormat int
+
+const (
+	// Serialize using encoding/json, into files ending in `.json`.
+	CorpusJSON CorpusFormat = iota
+	// Serialize using encoding/gob, into files ending in `.gob`.
+	CorpusGob
+)
+
+func (this CorpusFormat) extension() string {
+	if this == CorpusGob {
+		return ".gob"
+	}
+	return ".json"
+}
+
+// Decides whether values of type t should be captured into the corpus. See
+// RegisterCorpusFilter().
+type CorpusFilter func(t reflect.Type) bool
+
+type corpusCapture struct {
+	directory string
+	format    CorpusFormat
+	filters   []CorpusFilter
+}
+
+var corpusMutex sync.Mutex
+var corpusSettings atomic.Value
+
+// The maximum number of captured values that can be waiting to be written.
+// Values captured while the queue is full are dropped.
+const corpusQueueSize = 64
+
+// A captured value waiting to be written, or (if flushed is set) a request to
+// be notified once everything queued before it has been written.
+type corpusWrite struct {
+	path    string
+	data    []byte
+	flushed chan struct{}
+}
+
+var corpusWrites chan corpusWrite
+var corpusWriterStarted sync.Once
+
+func startCorpusWriter() {
+	corpusWriterStarted.Do(func() {
+		corpusWrites = make(chan corpusWrite, corpusQueueSize)
+		go func() {
+			for write := range corpusWrites {
+				if write.flushed != nil {
+					close(write.flushed)
+					continue
+				}
+				writeCorpusFile(write.path, write.data)
+			}
+		}()
+	})
+}
+
+// Wait until everything captured so far has been written.
+func flushCorpusWrites() {
+	startCorpusWriter()
+	flushed := make(chan struct{})
+	corpusWrites <- corpusWrite{flushed: flushed}
+	<-flushed
+}
+
+func loadCorpusSettings() *corpusCapture {
+	settings, _ := corpusSettings.Load().(*corpusCapture)
+	return settings
+}
+
+// Start capturing described values into directory: every value passed to
+// Describe(), DescribeOpts(), DescribeValue(), D(), DescribeTo() or a
+// Describer whose type matches a registered filter (see
+// RegisterCorpusFilter()) is also serialized into its own file, so that
+// real-world inputs can later be replayed in tests using LoadCorpus().
+//
+// Files are named after the value's type and a hash of its contents
+// (`mypkg.Request-3f2a9c01d4e5b6a7.json`), so capturing the same value again
+// doesn't create a new file.
+//
+// Values that can't be serialized in the chosen format (such as those with
+// unexported fields only, or containing channels, functions or cycles) are
+// skipped. Nothing is captured in reflection-only mode (see
+// WithReflectionOnly()), since serializing can call user code.
+//
+// Values are serialized when they're described, but files are written in the
+// background. Values described faster than they can be written are dropped.
+func EnableCorpusCapture(directory string, format CorpusFormat) error {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	startCorpusWriter()
+	corpusMutex.Lock()
+	defer corpusMutex.Unlock()
+
+	settings := &corpusCapture{}
+	if existing := loadCorpusSettings(); existing != nil {
+		*settings = *existing
+	}
+	settings.directory = directory
+	settings.format = format
+	corpusSettings.Store(settings)
+	return nil
+}
+
+// Stop capturing described values, and wait for the files of values already
+// captured to be written. Registered filters are kept.
+func DisableCorpusCapture() {
+	corpusMutex.Lock()
+	settings := &corpusCapture{}
+	if existing := loadCorpusSettings(); existing != nil {
+		settings.filters = existing.filters
+	}
+	corpusSettings.Store(settings)
+	corpusMutex.Unlock()
+
+	flushCorpusWrites()
+}
+
+// Register a filter that selects which types to capture into the corpus (see
+// EnableCorpusCapture()). A value is captured if any filter matches its type.
+func RegisterCorpusFilter(filter CorpusFilter) {
+	corpusMutex.Lock()
+	defer corpusMutex.Unlock()
+
+	settings := &corpusCapture{}
+	if existing := loadCorpusSettings(); existing != nil {
+		*settings = *existing
+	}
+	settings.filters = append(append([]CorpusFilter{}, settings.filters...), filter)
+	corpusSettings.Store(settings)
+}
+
+// Capture values of type t (or pointers to t) into the corpus.
+func RegisterCorpusType(t reflect.Type) {
+	RegisterCorpusFilter(func(candidate reflect.Type) bool {
+		return candidate == t || (candidate.Kind() == reflect.Ptr && candidate.Elem() == t)
+	})
+}
+
+// Load every value of type t that was captured into directory in the given
+// format, in file name order.
+func LoadCorpus(directory string, format CorpusFormat, t reflect.Type) (values []reflect.Value, err error) {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return
+	}
+	prefix := getCorpusFilePrefix(t)
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, format.extension()) {
+			continue
+		}
+		var data []byte
+		if data, err = ioutil.ReadFile(filepath.Join(directory, name)); err != nil {
+			return
+		}
+		value := reflect.New(t)
+		if format == CorpusGob {
+			err = gob.NewDecoder(bytes.NewReader(data)).DecodeValue(value)
+		} else {
+			err = json.Unmarshal(data, value.Interface())
+		}
+		if err != nil {
+			err = fmt.Errorf("%v: %v", name, err)
+			return
+		}
+		values = append(values, value.Elem())
+	}
+	return
+}
+
+// Capture v into the corpus if capturing is enabled and v's type matches a
+// registered filter.
+func (this *options) captureCorpusValue(v reflect.Value) {
+	settings := loadCorpusSettings()
+	if settings == nil || settings.directory == "" || this.reflectionOnly {
+		return
+	}
+	if !v.IsValid() || !v.CanInterface() || isNil(v) || !matchesCorpusFilter(settings.filters, v.Type()) {
+		return
+	}
+	// Encoders recurse forever on cycles, and so would following a pointer
+	// that points to itself.
+	if hasCycle(v) {
+		return
+	}
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	data, ok := encodeCorpusValue(v, settings.format)
+	if !ok {
+		return
+	}
+	hash := sha256.Sum256(data)
+	name := getCorpusFilePrefix(v.Type()) + hex.EncodeToString(hash[:8]) + settings.format.extension()
+	select {
+	case corpusWrites <- corpusWrite{path: filepath.Join(settings.directory, name), data: data}:
+	default:
+	}
+}
+
+func writeCorpusFile(path string, data []byte) {
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+	// Write to a temporary file first so that a replaying test never sees a
+	// partially written value.
+	temp := path + ".tmp"
+	if err := ioutil.WriteFile(temp, data, 0644); err != nil {
+		return
+	}
+	if err := os.Rename(temp, path); err != nil {
+		os.Remove(temp)
+	}
+}
+
+func encodeCorpusValue(v reflect.Value, format CorpusFormat) (data []byte, ok bool) {
+	// Encoders call MarshalJSON() and GobEncode() methods, which may panic.
+	defer func() {
+		if e := recover(); e != nil {
+			ok = false
+		}
+	}()
+
+	if format == CorpusGob {
+		buffer := bytes.Buffer{}
+		if err := gob.NewEncoder(&buffer).EncodeValue(v); err != nil {
+			return
+		}
+		return buffer.Bytes(), true
+	}
+	data, err := json.Marshal(v.Interface())
+	return data, err == nil
+}
+
+func matchesCorpusFilter(filters []CorpusFilter, t reflect.Type) bool {
+	for _, filter := range filters {
+		if filter(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the file name prefix for corpus values of type t: `mypkg.Request-`
+func getCorpusFilePrefix(t reflect.Type) string {
+	name := []byte(getTypeName(t))
+	for i, ch := range name {
+		if !(ch == '.' || ch == '_' || (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')) {
+			name[i] = '_'
+		}
+	}
+	return string(name) + "-"
+}
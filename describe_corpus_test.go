@@ -0,0 +1,85 @@
+package describe
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type CorpusRequest struct {
+	Method string
+	Path   string
+}
+
+type CorpusIgnored struct {
+	Value int
+}
+
+type CorpusNode struct {
+	Name string
+	Next *CorpusNode
+}
+
+type CorpusSelfPointer *CorpusSelfPointer
+
+func testCorpusCapture(t *testing.T, format CorpusFormat) {
+	directory, err := ioutil.TempDir("", "describe-corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	RegisterCorpusType(reflect.TypeOf(CorpusRequest{}))
+	RegisterCorpusType(reflect.TypeOf(CorpusNode{}))
+	var selfPointer CorpusSelfPointer
+	selfPointer = &selfPointer
+	RegisterCorpusType(reflect.TypeOf(selfPointer))
+	if err := EnableCorpusCapture(directory, format); err != nil {
+		t.Fatal(err)
+	}
+	D(&CorpusRequest{Method: "GET", Path: "/a"})
+	D(CorpusRequest{Method: "GET", Path: "/a"})
+	DescribeOpts(CorpusRequest{Method: "POST", Path: "/b"})
+	DescribeOpts(CorpusRequest{Method: "PUT", Path: "/c"}, WithReflectionOnly(true))
+	D(CorpusIgnored{Value: 1})
+	cycle := &CorpusNode{Name: "a"}
+	cycle.Next = cycle
+	D(cycle)
+	D(selfPointer)
+	DisableCorpusCapture()
+	D(CorpusRequest{Method: "DELETE", Path: "/d"})
+
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCount := 2
+	if len(files) != expectedCount {
+		t.Errorf("Expected %v files but got %v", expectedCount, len(files))
+	}
+
+	values, err := LoadCorpus(directory, format, reflect.TypeOf(CorpusRequest{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[CorpusRequest]bool{}
+	for _, value := range values {
+		found[value.Interface().(CorpusRequest)] = true
+	}
+	expected := map[CorpusRequest]bool{
+		CorpusRequest{Method: "GET", Path: "/a"}:  true,
+		CorpusRequest{Method: "POST", Path: "/b"}: true,
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected %v but got %v", D(expected), D(found))
+	}
+}
+
+func TestCorpusCaptureJSON(t *testing.T) {
+	testCorpusCapture(t, CorpusJSON)
+}
+
+func TestCorpusCaptureGob(t *testing.T) {
+	testCorpusCapture(t, CorpusGob)
+}
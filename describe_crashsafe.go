@@ -0,0 +1,98 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// Output buffer of fixed capacity, used by CrashSafe(). Writes that don't fit
+// are truncated (without splitting a UTF-8 character) rather than growing the
+// buffer.
+type fixedBuffer struct {
+	bytes       []byte
+	limit       int
+	isTruncated bool
+}
+
+func newFixedBuffer(buf []byte) *fixedBuffer {
+	// Leave room for a truncation marker.
+	limit := cap(buf) - len(tokElided)
+	if limit < len(buf) {
+		limit = len(buf)
+	}
+	return &fixedBuffer{
+		bytes: buf,
+		limit: limit,
+	}
+}
+
+func (this *fixedBuffer) WriteString(value string) {
+	if this.isTruncated {
+		// Don't let smaller writes fill the gap left by a truncated one.
+		return
+	}
+	remaining := this.limit - len(this.bytes)
+	if len(value) > remaining {
+		this.isTruncated = true
+		for remaining > 0 && !utf8.RuneStart(value[remaining]) {
+			remaining--
+		}
+		value = value[:remaining]
+	}
+	this.bytes = append(this.bytes, value...)
+}
+
+func (this *fixedBuffer) Len() int {
+	return len(this.bytes)
+}
+
+func (this *fixedBuffer) IsFull() bool {
+	return this.isTruncated || len(this.bytes) >= this.limit
+}
+
+// Get the buffer contents, ending in a truncation marker if anything didn't
+// fit (and there's room for the marker).
+func (this *fixedBuffer) finish() []byte {
+	if this.isTruncated && cap(this.bytes)-len(this.bytes) >= len(tokElided) {
+		this.bytes = append(this.bytes, tokElided...)
+	}
+	return this.bytes
+}
+
+// Describe v by appending to buf without ever growing it beyond its capacity,
+// and return the extended slice. If the description doesn't fit, it's
+// truncated and ends with `…` (room for which is reserved at the end of buf).
+//
+// This is for describing objects inside signal or panic handlers, and in
+// out-of-memory situations:
+//
+//   - No user code is called (see WithReflectionOnly()).
+//   - Duplicates aren't searched for, which avoids allocating maps. Cyclic
+//     data is expanded until buf is full instead of being marked.
+//   - Panics are always recovered (even if DebugPanics is set), and described
+//     as a library bug within the output.
+//
+// Some allocation still happens (for example when formatting numbers), but
+// it's bounded by the size of the values being formatted.
+func CrashSafe(v interface{}, buf []byte) []byte {
+	context := describer{}
+	context.reflectionOnly = true
+	context.fixedOutput = newFixedBuffer(buf)
+	context.describeCrashSafe(v)
+	return context.fixedOutput.finish()
+}
+
+func (this *describer) describeCrashSafe(v interface{}) {
+	defer func() {
+		if e := recover(); e != nil {
+			this.fixedOutput.WriteString(fmt.Sprintf("go-describe.BUG(%v)", e))
+		}
+	}()
+
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	this.describeReflectedValue(rv, false)
+}
@@ -0,0 +1,62 @@
+package describe
+
+import (
+	"testing"
+)
+
+type CrashSafeNode struct {
+	Name string
+	Next *CrashSafeNode
+}
+
+func TestCrashSafe(t *testing.T) {
+	buf := make([]byte, 0, 100)
+	expected := `describe.CrashSafeNode<Name="a" Next=nil>`
+	actual := string(CrashSafe(CrashSafeNode{Name: "a"}, buf))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `prefix: int[1 2 3]`
+	actual = string(CrashSafe([]int{1, 2, 3}, append(buf, "prefix: "...)))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCrashSafeNoUserCode(t *testing.T) {
+	countedStringerCalls = 0
+	expected := `*describe.CountedStringer<Name="x">`
+	actual := string(CrashSafe(&CountedStringer{"x"}, make([]byte, 0, 100)))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	if countedStringerCalls != 0 {
+		t.Errorf("Expected String() not to be called but was called %v times", countedStringerCalls)
+	}
+}
+
+func TestCrashSafeTruncated(t *testing.T) {
+	buf := make([]byte, 0, 19)
+	result := CrashSafe([]string{"abc", "déf", "ghi"}, buf)
+	expected := `string["abc" "d…`
+	actual := string(result)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	if cap(result) != cap(buf) || &result[:1][0] != &buf[:1][0] {
+		t.Errorf("Expected the description to be written into the caller's buffer")
+	}
+}
+
+func TestCrashSafeCycle(t *testing.T) {
+	node := &CrashSafeNode{Name: "a"}
+	node.Next = node
+	buf := make([]byte, 0, 64)
+
+	expected := `*describe.CrashSafeNode<Name="a" Next=*describe.CrashSafeNode…`
+	actual := string(CrashSafe(node, buf))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
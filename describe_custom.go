@@ -0,0 +1,292 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+type interfaceDescriber struct {
+	iface     reflect.Type
+	describer ContextDescriber
+}
+
+// Interface describers, most recently registered first. Registries are never
+// modified once stored, so that lookups don't need to lock.
+type interfaceDescriberRegistry struct {
+	entries []interfaceDescriber
+	// Cache of concrete type -> *interfaceDescriber (nil if there's no match)
+	matches *sync.Map
+}
+
+var interfaceDescribers atomic.Value // *interfaceDescriberRegistry
+var interfaceDescribersMutex sync.Mutex
+
+func init() {
+	interfaceDescribers.Store(&interfaceDescriberRegistry{matches: &sync.Map{}})
+}
+
+// Add a custom describer for all types that implement an interface, such as
+// error, fmt.Stringer or an application's own `Redactable`. See
+// SetCustomDescriber() for how describers are called.
+//
+// Describers set for a concrete type using SetCustomDescriber() or
+// WithCustomDescriber() take precedence. If a type implements more than one
+// interface that has a describer, the most recently set describer is used.
+//
+// Only types that implement iface themselves are matched. For a String()
+// method with a pointer receiver, that means the pointer type, so that values
+// of type T (reached other than via a pointer) aren't matched.
+//
+// Passing a nil describer will remove the describer for iface.
+//
+// Panics if iface isn't an interface type.
+func SetCustomDescriberForInterface(iface reflect.Type, describer CustomDescriber) {
+	iface = getDescribedInterface("SetCustomDescriberForInterface", iface)
+
+	interfaceDescribersMutex.Lock()
+	defer interfaceDescribersMutex.Unlock()
+
+	registry := interfaceDescribers.Load().(*interfaceDescriberRegistry)
+	if describer == nil {
+		interfaceDescribers.Store(registry.without(iface))
+		return
+	}
+	interfaceDescribers.Store(registry.with(iface, contextDescriberOf(describer)))
+}
+
+// Get the interface type to register a describer for.
+func getDescribedInterface(caller string, iface reflect.Type) reflect.Type {
+	// Allow reflect.TypeOf((*io.Reader)(nil)) as a shorthand.
+	if iface.Kind() == reflect.Ptr && iface.Elem().Kind() == reflect.Interface {
+		iface = iface.Elem()
+	}
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Errorf("%v: %v is not an interface type", caller, iface))
+	}
+	return iface
+}
+
+// Make a copy of this registry with describer set for iface (a nil describer
+// disables describing types that implement iface).
+func (this *interfaceDescriberRegistry) with(iface reflect.Type, describer ContextDescriber) *interfaceDescriberRegistry {
+	registry := this.without(iface)
+	registry.entries = append([]interfaceDescriber{{iface, describer}}, registry.entries...)
+	return registry
+}
+
+// Make a copy of this registry without iface.
+func (this *interfaceDescriberRegistry) without(iface reflect.Type) *interfaceDescriberRegistry {
+	registry := &interfaceDescriberRegistry{matches: &sync.Map{}}
+	if this != nil {
+		for _, entry := range this.entries {
+			if entry.iface != iface {
+				registry.entries = append(registry.entries, entry)
+			}
+		}
+	}
+	return registry
+}
+
+// Get the entry for the most recently registered interface that concrete type
+// t implements, or nil if there's no match. The entry's describer is nil if
+// describing that interface was disabled.
+func (this *interfaceDescriberRegistry) find(t reflect.Type) *interfaceDescriber {
+	if this == nil || len(this.entries) == 0 || t.Kind() == reflect.Interface {
+		// Interface values are matched by what they contain.
+		return nil
+	}
+	cached, ok := this.matches.Load(t)
+	if !ok {
+		var match *interfaceDescriber
+		for i := range this.entries {
+			if t.Implements(this.entries[i].iface) {
+				match = &this.entries[i]
+				break
+			}
+		}
+		cached, _ = this.matches.LoadOrStore(t, match)
+	}
+	return cached.(*interfaceDescriber)
+}
+
+// Get the entry for the global interface describer for concrete type t, if
+// any.
+func findInterfaceDescriber(t reflect.Type) *interfaceDescriber {
+	registry := interfaceDescribers.Load().(*interfaceDescriberRegistry)
+	return registry.find(t)
+}
+
+var kindDescribers sync.Map
+
+// Set a custom describer for all types of a kind, such as every float, string
+// or channel type. See SetCustomDescriber() for how describers are called.
+//
+// Describers set for a concrete type or for an interface (see
+// SetCustomDescriberForInterface()) take precedence.
+//
+// Passing a nil describer will remove the describer for that kind.
+//
+// Panics if kind is reflect.Interface (interface values are matched by what
+// they contain) or reflect.Invalid.
+func SetCustomDescriberForKind(kind reflect.Kind, describer CustomDescriber) {
+	checkDescribedKind("SetCustomDescriberForKind", kind)
+	if describer == nil {
+		kindDescribers.Delete(kind)
+		return
+	}
+	kindDescribers.Store(kind, contextDescriberOf(describer))
+}
+
+func checkDescribedKind(caller string, kind reflect.Kind) {
+	if kind == reflect.Interface || kind == reflect.Invalid {
+		panic(fmt.Errorf("%v: cannot set a describer for kind %v", caller, kind))
+	}
+}
+
+// Get the kind describer for type t, if any.
+func getKindDescriber(t reflect.Type) (describer ContextDescriber, ok bool) {
+	if found, ok := kindDescribers.Load(t.Kind()); ok {
+		return found.(ContextDescriber), true
+	}
+	return nil, false
+}
+
+// How a custom describer matches types. See SetCustomDescriberMatching().
+type DescriberMatching int
+
+const (
+	// Match only the type that the describer was set for (default)
+	MatchExactType DescriberMatching = iota
+	// Also match the type's pointer/value counterpart: for a describer set for
+	// T, values of type *T are dereferenced and passed to it. For a describer
+	// set for *T, values of type T are passed to it by pointer.
+	MatchPointersAndValues
+)
+
+// Counterpart describers, adapted from describers set for the counterpart
+// types. See SetCustomDescriberMatching().
+var counterpartDescribers sync.Map
+
+// Set a custom describer for a data type, as with SetCustomDescriber(), but
+// with control over which types it matches.
+//
+// Describers normally only match the exact type they were set for, so a
+// describer set for *T isn't used for T values (such as struct fields of type
+// T), and one set for T is only used for *T values via the pointer (so that
+// the description is prefixed with `*`). With MatchPointersAndValues, the
+// describer is used for both:
+//
+//	describe.SetCustomDescriberMatching(reflect.TypeOf(&Conn{}), describeConn, describe.MatchPointersAndValues)
+//
+// Describers set for the exact type (using any of the SetCustomDescriber...()
+// functions) take precedence over describers matched as counterparts.
+//
+// Passing a nil describer disables the custom describer for that type (and for
+// its counterpart if matching is MatchPointersAndValues).
+func SetCustomDescriberMatching(t reflect.Type, describer CustomDescriber, matching DescriberMatching) {
+	SetCustomDescriber(t, describer)
+	counterpart, adapted := adaptToCounterpart(t, contextDescriberOf(describer))
+	if matching == MatchExactType || adapted == nil {
+		counterpartDescribers.Delete(counterpart)
+		return
+	}
+	counterpartDescribers.Store(counterpart, adapted)
+}
+
+// Get t's pointer/value counterpart: the type that t points to if it's a
+// pointer type, or otherwise pointers to t.
+func getCounterpartType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return reflect.PtrTo(t)
+}
+
+// Adapt a describer set for t to describe values of t's counterpart (see
+// getCounterpartType()). Values that can't be pointed to are described as if
+// there were no describer.
+func adaptToCounterpart(t reflect.Type, describer ContextDescriber) (counterpart reflect.Type, adapted ContextDescriber) {
+	counterpart = getCounterpartType(t)
+	if t.Kind() == reflect.Ptr {
+		if describer != nil {
+			adapted = func(ctx *DescribeContext, v reflect.Value) {
+				if ptr, ok := ctx.parent.getPointerTo(v); ok {
+					describer(ctx, ptr)
+					return
+				}
+				ctx.describeDefault(v)
+			}
+		}
+		return
+	}
+
+	if describer != nil {
+		adapted = func(ctx *DescribeContext, v reflect.Value) {
+			describer(ctx, v.Elem())
+		}
+	}
+	return
+}
+
+// Field describers, as a map of field name -> ContextDescriber per struct type.
+// The maps are never modified once stored, so that lookups don't need to lock.
+var fieldDescribers sync.Map
+var fieldDescribersMutex sync.Mutex
+
+// Set a custom describer for one field of a struct type, such as the password
+// hash in a User struct, without affecting how values of the field's type are
+// described elsewhere:
+//
+//	describe.SetFieldDescriber(reflect.TypeOf(User{}), "PasswordHash", describeHash)
+//
+// The describer is passed the field's value as is (without following pointers
+// or interfaces), and takes precedence over any other describer for it. Nil
+// values are described as usual. See SetCustomDescriber() for how describers
+// are called.
+//
+// Field describers are used in text descriptions (such as from Describe(),
+// D() or DescribeSplit()).
+//
+// Passing a nil describer will remove the describer for that field.
+//
+// Panics if structType isn't a struct type, or has no such field.
+func SetFieldDescriber(structType reflect.Type, field string, describer CustomDescriber) {
+	checkDescribedField("SetFieldDescriber", structType, field)
+
+	fieldDescribersMutex.Lock()
+	defer fieldDescribersMutex.Unlock()
+
+	describers := make(map[string]ContextDescriber)
+	if existing, ok := fieldDescribers.Load(structType); ok {
+		for k, v := range existing.(map[string]ContextDescriber) {
+			describers[k] = v
+		}
+	}
+	if describer == nil {
+		delete(describers, field)
+	} else {
+		describers[field] = contextDescriberOf(describer)
+	}
+	if len(describers) == 0 {
+		fieldDescribers.Delete(structType)
+		return
+	}
+	fieldDescribers.Store(structType, describers)
+}
+
+// Check that structType has a field with this name (not counting fields
+// promoted from embedded structs, since they're described as part of the
+// embedded struct).
+func checkDescribedField(caller string, structType reflect.Type, field string) {
+	if structType.Kind() != reflect.Struct {
+		panic(fmt.Errorf("%v: %v is not a struct type", caller, structType))
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		if structType.Field(i).Name == field {
+			return
+		}
+	}
+	panic(fmt.Errorf("%v: %v has no field %v", caller, structType, field))
+}
@@ -0,0 +1,360 @@
+package describe
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type Redactable interface {
+	Redacted() string
+}
+
+type RedactedPassword string
+
+func (this RedactedPassword) Redacted() string {
+	return "***"
+}
+
+type RedactedToken struct {
+	Value string
+}
+
+func (this *RedactedToken) Redacted() string {
+	return "token(***)"
+}
+
+type RedactedLogin struct {
+	User     string
+	Password RedactedPassword
+	Token    *RedactedToken
+	Err      error
+}
+
+var redactableType = reflect.TypeOf((*Redactable)(nil)).Elem()
+
+func describeRedactable(v reflect.Value) string {
+	return v.Interface().(Redactable).Redacted()
+}
+
+func TestCustomDescriberForInterface(t *testing.T) {
+	SetCustomDescriberForInterface(redactableType, describeRedactable)
+	defer SetCustomDescriberForInterface(redactableType, nil)
+
+	v := RedactedLogin{User: "a", Password: "hunter2", Token: &RedactedToken{"abc"}}
+	expected := `describe.RedactedLogin<User="a" Password=*** Token=token(***) Err=nil>`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Interface values are matched by what they contain.
+	errorType := reflect.TypeOf((*error)(nil))
+	SetCustomDescriberForInterface(errorType, func(v reflect.Value) string {
+		return "error(" + v.Interface().(error).Error() + ")"
+	})
+	defer SetCustomDescriberForInterface(errorType, nil)
+	v.Err = errors.New("failed")
+	expected = `describe.RedactedLogin<User="a" Password=*** Token=token(***) Err=@error(failed)>`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCustomDescriberForInterfacePrecedence(t *testing.T) {
+	SetCustomDescriberForInterface(redactableType, describeRedactable)
+	defer SetCustomDescriberForInterface(redactableType, nil)
+	v := []interface{}{RedactedPassword("a"), &RedactedToken{"b"}}
+
+	// Concrete types win over interfaces.
+	SetCustomDescriber(reflect.TypeOf(RedactedPassword("")), func(v reflect.Value) string {
+		return "password"
+	})
+	expected := `interface[@password @token(***)]`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	SetCustomDescriber(reflect.TypeOf(RedactedPassword("")), nil)
+
+	// The most recently set interface describer wins.
+	anonymousType := reflect.TypeOf((*interface{ Redacted() string })(nil)).Elem()
+	SetCustomDescriberForInterface(anonymousType, func(v reflect.Value) string {
+		return "anonymous"
+	})
+	defer SetCustomDescriberForInterface(anonymousType, nil)
+	expected = `interface[@anonymous @anonymous]`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Disabled for a Describer
+	expected = `interface[@"a" @*describe.RedactedToken<Value="b">]`
+	actual = DescribeOpts(v, WithGlobalDescribers(false))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCustomDescriberForInterfaceNotInterface(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("Expected a panic")
+		}
+	}()
+	SetCustomDescriberForInterface(reflect.TypeOf(1), describeRedactable)
+}
+
+func describeFloatRounded(v reflect.Value) string {
+	return fmt.Sprintf("%.2f", v.Float())
+}
+
+type RoundedMeasurement float64
+
+func TestCustomDescriberForKind(t *testing.T) {
+	SetCustomDescriberForKind(reflect.Float64, describeFloatRounded)
+	defer SetCustomDescriberForKind(reflect.Float64, nil)
+
+	v := []interface{}{1.23456, RoundedMeasurement(2.5), float32(1.5)}
+	expected := `interface[@1.23 @2.50 @1.5]`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `interface[@1.23456 @2.5 @1.5]`
+	actual = DescribeOpts(v, WithGlobalDescribers(false))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetCustomDescriberForKind(reflect.Float64, nil)
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCustomDescriberForKindPrecedence(t *testing.T) {
+	SetCustomDescriberForKind(reflect.String, func(v reflect.Value) string {
+		return "string"
+	})
+	defer SetCustomDescriberForKind(reflect.String, nil)
+	v := []interface{}{"a", RedactedPassword("b")}
+
+	expected := `interface[@string @string]`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Interfaces win over kinds.
+	SetCustomDescriberForInterface(redactableType, describeRedactable)
+	defer SetCustomDescriberForInterface(redactableType, nil)
+	expected = `interface[@string @***]`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Concrete types win over interfaces.
+	expected = `interface[@string @password]`
+	actual = DescribeOpts(v, WithCustomDescriber(reflect.TypeOf(RedactedPassword("")), func(v reflect.Value) string {
+		return "password"
+	}))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCustomDescriberForKindInterface(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("Expected a panic")
+		}
+	}()
+	SetCustomDescriberForKind(reflect.Interface, describeRedactable)
+}
+
+type MatchedConn struct {
+	fd int
+}
+
+type MatchedConns struct {
+	Value   MatchedConn
+	Pointer *MatchedConn
+	Nil     *MatchedConn
+}
+
+var matchedConnType = reflect.TypeOf(MatchedConn{})
+
+func describeMatchedConn(v reflect.Value) string {
+	return fmt.Sprintf("conn(%v)", v.FieldByName("fd").Int())
+}
+
+func describeMatchedConnPointer(v reflect.Value) string {
+	return fmt.Sprintf("connptr(%v)", v.Elem().FieldByName("fd").Int())
+}
+
+func TestCustomDescriberMatching(t *testing.T) {
+	v := MatchedConns{Value: MatchedConn{fd: 1}, Pointer: &MatchedConn{fd: 2}}
+
+	SetCustomDescriberMatching(reflect.PtrTo(matchedConnType), describeMatchedConnPointer, MatchExactType)
+	expected := `describe.MatchedConns<Value=describe.MatchedConn<fd=1> Pointer=connptr(2) Nil=nil>`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetCustomDescriberMatching(reflect.PtrTo(matchedConnType), describeMatchedConnPointer, MatchPointersAndValues)
+	expected = `describe.MatchedConns<Value=connptr(1) Pointer=connptr(2) Nil=nil>`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetCustomDescriberMatching(reflect.PtrTo(matchedConnType), nil, MatchPointersAndValues)
+	expected = `describe.MatchedConns<Value=describe.MatchedConn<fd=1> Pointer=*describe.MatchedConn<fd=2> Nil=nil>`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetCustomDescriberMatching(matchedConnType, describeMatchedConn, MatchPointersAndValues)
+	defer SetCustomDescriberMatching(matchedConnType, nil, MatchPointersAndValues)
+	expected = `describe.MatchedConns<Value=conn(1) Pointer=conn(2) Nil=nil>`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Describers set for the exact type take precedence.
+	expected = `describe.MatchedConns<Value=conn(1) Pointer=connptr(2) Nil=nil>`
+	actual = DescribeOpts(v, WithCustomDescriber(reflect.PtrTo(matchedConnType), describeMatchedConnPointer))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// The counterpart follows the describer it was adapted from.
+	SetCustomDescriber(matchedConnType, func(v reflect.Value) string { return "other" })
+	expected = `describe.MatchedConns<Value=other Pointer=other Nil=nil>`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetCustomDescriber(matchedConnType, nil)
+	expected = `describe.MatchedConns<Value=describe.MatchedConn<fd=1> Pointer=*describe.MatchedConn<fd=2> Nil=nil>`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCustomDescriberMatchingUnaddressable(t *testing.T) {
+	// Without unsafe operations, unexported values that can't be addressed
+	// can't be pointed to.
+	type holder struct {
+		conn MatchedConn
+	}
+	context := describer{}
+	context.disableUnsafe = true
+	_, adapted := adaptToCounterpart(reflect.PtrTo(matchedConnType), contextDescriberOf(describeMatchedConnPointer))
+	ctx := context.newDescribeContext()
+	adapted(ctx, reflect.ValueOf(holder{conn: MatchedConn{fd: 1}}).Field(0))
+
+	expected := `describe.MatchedConn<fd=1>`
+	actual := ctx.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestCustomDescriberMatchingOption(t *testing.T) {
+	v := MatchedConns{Value: MatchedConn{fd: 1}, Pointer: &MatchedConn{fd: 2}}
+	expected := `describe.MatchedConns<Value=conn(1) Pointer=conn(2) Nil=nil>`
+	actual := DescribeOpts(v, WithCustomDescriberMatching(matchedConnType, describeMatchedConn, MatchPointersAndValues))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.MatchedConns<Value=conn(1) Pointer=*conn(2) Nil=nil>`
+	actual = DescribeOpts(v, WithCustomDescriberMatching(matchedConnType, describeMatchedConn, MatchExactType))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetCustomDescriberMatching(matchedConnType, describeMatchedConn, MatchPointersAndValues)
+	defer SetCustomDescriberMatching(matchedConnType, nil, MatchPointersAndValues)
+	expected = `describe.MatchedConns<Value=describe.MatchedConn<fd=1> Pointer=*describe.MatchedConn<fd=2> Nil=nil>`
+	actual = DescribeOpts(v, WithCustomDescriberMatching(matchedConnType, nil, MatchPointersAndValues))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type FieldDescribedUser struct {
+	Name         string
+	PasswordHash []byte
+	Recovery     []byte
+	Manager      *FieldDescribedUser
+}
+
+var fieldDescribedUserType = reflect.TypeOf(FieldDescribedUser{})
+
+func describeHash(v reflect.Value) string {
+	return fmt.Sprintf("hash(%v bytes)", v.Len())
+}
+
+func TestFieldDescriber(t *testing.T) {
+	SetFieldDescriber(fieldDescribedUserType, "PasswordHash", describeHash)
+	defer SetFieldDescriber(fieldDescribedUserType, "PasswordHash", nil)
+
+	manager := &FieldDescribedUser{Name: "b", PasswordHash: []byte{1, 2, 3}}
+	v := FieldDescribedUser{Name: "a", PasswordHash: []byte{1}, Recovery: []byte{4}, Manager: manager}
+	expected := `describe.FieldDescribedUser<Name="a" PasswordHash=hash(1 bytes) Recovery=uint8[0x04] Manager=*describe.FieldDescribedUser<Name="b" PasswordHash=hash(3 bytes) Recovery=nil Manager=nil>>`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Only the field is affected, and not other uses of its type.
+	expected = `uint8[0x01]`
+	actual = D(v.PasswordHash)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.FieldDescribedUser<Name="a" PasswordHash=uint8[0x01] Recovery=hash(1 bytes) Manager=nil>`
+	actual = DescribeOpts(FieldDescribedUser{Name: "a", PasswordHash: []byte{1}, Recovery: []byte{4}},
+		WithFieldDescriber(fieldDescribedUserType, "PasswordHash", nil),
+		WithFieldDescriber(fieldDescribedUserType, "Recovery", describeHash))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestFieldDescriberReferences(t *testing.T) {
+	hash := []byte{1, 2}
+	v := struct {
+		User  FieldDescribedUser
+		Other []byte
+	}{FieldDescribedUser{PasswordHash: hash}, hash}
+	expected := `struct { User describe.FieldDescribedUser; Other []uint8 }<User=describe.FieldDescribedUser<Name="" PasswordHash=1~hash(2 bytes) Recovery=nil Manager=nil> Other=$1>`
+	actual := DescribeOpts(v, WithFieldDescriber(fieldDescribedUserType, "PasswordHash", describeHash))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestFieldDescriberNoSuchField(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("Expected a panic")
+		}
+	}()
+	SetFieldDescriber(fieldDescribedUserType, "Password", describeHash)
+}
@@ -0,0 +1,85 @@
+package describe
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sync"
+	"time"
+)
+
+// Returns a stable 64-bit fingerprint of a description. Identical
+// descriptions always produce identical fingerprints, across processes and
+// builds.
+func Fingerprint(description string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(description))
+	return hash.Sum64()
+}
+
+// Formats a fingerprint the way it's shown in output: `0x0123456789abcdef`
+func FormatFingerprint(fingerprint uint64) string {
+	return fmt.Sprintf("0x%016x", fingerprint)
+}
+
+// A writer that replaces descriptions identical to one written within the last
+// window with a short note, like
+// `(identical to dump 0x4f2a8c11b7e30d95 at 12:03:51)`
+//
+// Each call to Write is treated as one complete description. A trailing
+// newline is preserved in the replacement note.
+//
+// DedupeWriter is safe for concurrent use.
+type DedupeWriter struct {
+	writer io.Writer
+	window time.Duration
+	now    func() time.Time
+	mutex  sync.Mutex
+	seen   map[uint64]time.Time
+}
+
+// Wrap a writer such that repeated descriptions within window are replaced
+// with a reference to the first one.
+func Dedupe(w io.Writer, window time.Duration) *DedupeWriter {
+	return &DedupeWriter{
+		writer: w,
+		window: window,
+		now:    time.Now,
+		seen:   make(map[uint64]time.Time),
+	}
+}
+
+func (this *DedupeWriter) Write(p []byte) (n int, err error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	now := this.now()
+	this.expire(now)
+
+	fingerprint := Fingerprint(string(p))
+	if firstSeen, ok := this.seen[fingerprint]; ok {
+		note := fmt.Sprintf("(identical to dump %v at %v)",
+			FormatFingerprint(fingerprint), firstSeen.Format("15:04:05"))
+		if len(p) > 0 && p[len(p)-1] == '\n' {
+			note += "\n"
+		}
+		if _, err = io.WriteString(this.writer, note); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	if n, err = this.writer.Write(p); err == nil && n == len(p) {
+		// Only a dump that was written in full can be referred to.
+		this.seen[fingerprint] = now
+	}
+	return
+}
+
+func (this *DedupeWriter) expire(now time.Time) {
+	for fingerprint, firstSeen := range this.seen {
+		if now.Sub(firstSeen) >= this.window {
+			delete(this.seen, fingerprint)
+		}
+	}
+}
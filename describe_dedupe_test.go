@@ -0,0 +1,72 @@
+package describe
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	if Fingerprint(D(1)) != Fingerprint(D(1)) {
+		t.Errorf("Expected identical descriptions to have identical fingerprints")
+	}
+	if Fingerprint(D(1)) == Fingerprint(D(2)) {
+		t.Errorf("Expected different descriptions to have different fingerprints")
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	now := time.Date(2020, time.Month(1), 1, 12, 3, 51, 0, time.UTC)
+	buffer := &bytes.Buffer{}
+	writer := Dedupe(buffer, time.Minute)
+	writer.now = func() time.Time { return now }
+
+	v := []int{1, 2, 3}
+	fmt.Fprintln(writer, D(v))
+	now = now.Add(30 * time.Second)
+	fmt.Fprintln(writer, D(v))
+	fmt.Fprintln(writer, D(4))
+	now = now.Add(30 * time.Second)
+	fmt.Fprintln(writer, D(v))
+
+	fingerprint := FormatFingerprint(Fingerprint(D(v) + "\n"))
+	expected := "int[1 2 3]\n" +
+		"(identical to dump " + fingerprint + " at 12:03:51)\n" +
+		"4\n" +
+		"int[1 2 3]\n"
+	actual := buffer.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type FailingOnceWriter struct {
+	bytes.Buffer
+	didFail bool
+}
+
+func (this *FailingOnceWriter) Write(p []byte) (n int, err error) {
+	if !this.didFail {
+		this.didFail = true
+		return 0, errors.New("write failed")
+	}
+	return this.Buffer.Write(p)
+}
+
+func TestDedupeFailedWrite(t *testing.T) {
+	buffer := &FailingOnceWriter{}
+	writer := Dedupe(buffer, time.Minute)
+
+	if _, err := fmt.Fprintln(writer, D(1)); err == nil {
+		t.Errorf("Expected the first write to fail")
+	}
+	fmt.Fprintln(writer, D(1))
+
+	expected := "1\n"
+	actual := buffer.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
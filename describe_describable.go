@@ -0,0 +1,233 @@
+package describe
+
+import (
+	"reflect"
+)
+
+// Iterates over the entries of a DescribableAsMap.
+type MapIterator interface {
+	// Advance to the next entry, returning false if there are no more.
+	Next() bool
+	Key() interface{}
+	Value() interface{}
+}
+
+// Implemented by map-like types (such as ordered maps) to be described as a
+// map of their entries in iteration order, rather than as their internal
+// structure: `orderedmap.Map{"b"=2 "a"=1}`
+//
+// Entries are described like any other data, including multiline layout and
+// marking of duplicates.
+type DescribableAsMap interface {
+	// Return an iterator over the entries to describe.
+	DescribeMapEntries() MapIterator
+}
+
+// Implemented by list-like types (such as linked lists or ring buffers) to be
+// described as a list of their elements, rather than as their internal
+// structure: `list.Ring[1 2 3]`
+type DescribableAsList interface {
+	// Return the elements to describe, in order.
+	DescribeListElements() []interface{}
+}
+
+// Implemented by types that represent a single value (such as decimals or
+// identifiers) to be described as that value: `money.Amount<1.5>`
+type DescribableAsScalar interface {
+	// Return the value to describe.
+	DescribeScalar() interface{}
+}
+
+// Implemented by types that want to be described as some other value entirely
+// (for example an exported view of their unexported state). The substitute is
+// described in place of the original, including its type name.
+//
+// This is an alternative to SetCustomDescriber() that travels with the type
+// definition, and that supports multiline layout and marking of duplicates.
+type SelfDescriber interface {
+	// Return the value to describe in place of this one.
+	DescribeAs() interface{}
+}
+
+var (
+	describableAsMapType    = reflect.TypeOf((*DescribableAsMap)(nil)).Elem()
+	describableAsListType   = reflect.TypeOf((*DescribableAsList)(nil)).Elem()
+	describableAsScalarType = reflect.TypeOf((*DescribableAsScalar)(nil)).Elem()
+	selfDescriberType       = reflect.TypeOf((*SelfDescriber)(nil)).Elem()
+)
+
+// In order of precedence, for types that implement more than one.
+var describableProtocols = []reflect.Type{
+	describableAsMapType,
+	describableAsListType,
+	describableAsScalarType,
+	selfDescriberType,
+}
+
+// Iterates over entries collected from a DescribableAsMap.
+type describableMapIter struct {
+	keys   []reflect.Value
+	values []reflect.Value
+	index  int
+}
+
+func (this *describableMapIter) Next() bool {
+	this.index++
+	return this.index < len(this.keys)
+}
+
+func (this *describableMapIter) Key() reflect.Value {
+	return this.keys[this.index]
+}
+
+func (this *describableMapIter) Value() reflect.Value {
+	return this.values[this.index]
+}
+
+// Convert a value received from user code, such that a nil interface is
+// described as nil.
+func valueOfInterface(value interface{}) reflect.Value {
+	return unwrapInterface(reflect.ValueOf(&value).Elem())
+}
+
+// Get the protocol that v describes itself with (if any), and the receiver to
+// call it on.
+//
+// Pointers are checked once followed (like String() methods), so that the
+// pointer itself is marked as usual if it's a duplicate.
+func (this *describer) getDescribableProtocol(v reflect.Value) (protocol reflect.Type, receiver reflect.Value, ok bool) {
+	if this.reflectionOnly {
+		return
+	}
+	for _, protocol = range describableProtocols {
+		if receiver, ok = this.getReceiverImplementing(v, protocol); ok {
+			return
+		}
+	}
+	return
+}
+
+// What a self-describing value is to be described as. For maps, keys and
+// values hold the entries. For lists, values holds the elements. Otherwise,
+// values holds the scalar or substitute value.
+type describableContents struct {
+	protocol reflect.Type
+	keys     []reflect.Value
+	values   []reflect.Value
+}
+
+// Get the contents of v if it describes itself.
+func (this *describer) getDescribableContents(v reflect.Value) (contents describableContents, ok bool) {
+	protocol, receiver, ok := this.getDescribableProtocol(v)
+	if !ok {
+		return
+	}
+	asInterface, ok := this.getInterface(receiver)
+	if !ok {
+		return
+	}
+	contents.protocol = protocol
+	ok, _ = this.callUserCode(v.Type(), func() {
+		switch protocol {
+		case describableAsMapType:
+			entries := asInterface.(DescribableAsMap).DescribeMapEntries()
+			for entries.Next() {
+				contents.keys = append(contents.keys, valueOfInterface(entries.Key()))
+				contents.values = append(contents.values, valueOfInterface(entries.Value()))
+			}
+		case describableAsListType:
+			for _, element := range asInterface.(DescribableAsList).DescribeListElements() {
+				contents.values = append(contents.values, valueOfInterface(element))
+			}
+		case describableAsScalarType:
+			contents.values = []reflect.Value{valueOfInterface(asInterface.(DescribableAsScalar).DescribeScalar())}
+		case selfDescriberType:
+			contents.values = []reflect.Value{valueOfInterface(asInterface.(SelfDescriber).DescribeAs())}
+		}
+	})
+	if ok && protocol == selfDescriberType && isSameTypeAs(contents.values[0], v) {
+		// Describing the substitute would recurse forever.
+		ok = false
+	}
+	return
+}
+
+func isSameTypeAs(substitute reflect.Value, v reflect.Value) bool {
+	return substitute.IsValid() && (substitute.Type() == v.Type() || substitute.Type() == reflect.PtrTo(v.Type()))
+}
+
+func (this *describer) tryDescribeDescribable(v reflect.Value) (didDescribe bool) {
+	protocol, _, ok := this.getDescribableProtocol(v)
+	if !ok {
+		return
+	}
+	if protocol == describableAsMapType || protocol == describableAsListType {
+		if this.tryDescribeDepthExceeded(v) {
+			didDescribe = true
+			return
+		}
+	}
+	contents, ok := this.getDescribableContents(v)
+	if !ok {
+		return
+	}
+
+	switch contents.protocol {
+	case describableAsMapType:
+		this.writeTypeName(v.Type())
+		count := this.getElementCountToDescribe(len(contents.keys))
+		this.describeMapEntries(&describableMapIter{keys: contents.keys[:count], values: contents.values[:count], index: -1},
+			len(contents.keys)-count)
+	case describableAsListType:
+		this.writeTypeName(v.Type())
+		this.describeListElements(contents.values)
+	case describableAsScalarType:
+		this.writeTypeName(v.Type())
+		this.writeString(tokOpenStruct)
+		this.describeReflectedValue(contents.values[0], false)
+		this.writeString(tokCloseStruct)
+	case selfDescriberType:
+		this.describeReflectedValue(contents.values[0], false)
+	}
+	didDescribe = true
+	return
+}
+
+// Describe list elements, enclosed in `[]`.
+func (this *describer) describeListElements(elements []reflect.Value) {
+	this.writeString(tokOpenArray)
+	this.increaseIndent()
+	count := this.getElementCountToDescribe(len(elements))
+	for i := 0; i < count && !this.isOutputFull(); i++ {
+		this.writeItemSeparator(i == 0)
+		this.describeChild(indexSegment(i), elements[i], false)
+	}
+	this.writeOmittedElements(len(elements)-count, count == 0)
+	this.decreaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString(tokCloseArray)
+}
+
+// Write the summarized form of a self-describing container, returning false
+// if v isn't one.
+func (this *describer) tryDescribeElidedDescribable(v reflect.Value) bool {
+	protocol, _, ok := this.getDescribableProtocol(v)
+	if !ok {
+		return false
+	}
+	switch protocol {
+	case describableAsMapType:
+		this.writeTypeName(v.Type())
+		this.writeString(tokOpenMap)
+		this.writeString(tokElided)
+		this.writeString(tokCloseMap)
+	case describableAsListType:
+		this.writeTypeName(v.Type())
+		this.writeString(tokOpenArray)
+		this.writeString(tokElided)
+		this.writeString(tokCloseArray)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+package describe
+
+import (
+	"testing"
+)
+
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: make(map[string]interface{})}
+}
+
+func (this *OrderedMap) Set(key string, value interface{}) {
+	if _, ok := this.values[key]; !ok {
+		this.keys = append(this.keys, key)
+	}
+	this.values[key] = value
+}
+
+type orderedMapIterator struct {
+	orderedMap *OrderedMap
+	index      int
+}
+
+func (this *orderedMapIterator) Next() bool {
+	this.index++
+	return this.index < len(this.orderedMap.keys)
+}
+
+func (this *orderedMapIterator) Key() interface{} {
+	return this.orderedMap.keys[this.index]
+}
+
+func (this *orderedMapIterator) Value() interface{} {
+	return this.orderedMap.values[this.orderedMap.keys[this.index]]
+}
+
+func (this *OrderedMap) DescribeMapEntries() MapIterator {
+	return &orderedMapIterator{orderedMap: this, index: -1}
+}
+
+func TestDescribableAsMap(t *testing.T) {
+	m := NewOrderedMap()
+	m.Set("z", 1)
+	m.Set("a", "x")
+	m.Set("m", nil)
+
+	expected := `*describe.OrderedMap{"z"=1 "a"="x" "m"=nil}`
+	actual := Describe(m, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.OrderedMap{
+    "z" = 1
+    "a" = "x"
+    "m" = nil
+}`
+	actual = Describe(m, 4)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribableAsMapDuplicates(t *testing.T) {
+	shared := &InnerStruct{number: 1}
+	m := NewOrderedMap()
+	m.Set("a", shared)
+	m.Set("b", shared)
+	m.Set("self", m)
+
+	expected := `*1~describe.OrderedMap{"a"=*2~describe.InnerStruct<number=1> "b"=*$2 "self"=*$1}`
+	actual := Describe(m, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribableAsMapElided(t *testing.T) {
+	m := NewOrderedMap()
+	m.Set("a", 1)
+
+	expected := `interface[@*describe.OrderedMap{…}]`
+	actual := DescribeOpts([]interface{}{m}, WithBreadthFirstBudget(38))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type Ring struct {
+	elements []interface{}
+	start    int
+}
+
+func (this *Ring) DescribeListElements() []interface{} {
+	return append(append([]interface{}{}, this.elements[this.start:]...), this.elements[:this.start]...)
+}
+
+type Amount struct {
+	cents int
+}
+
+func (this Amount) DescribeScalar() interface{} {
+	return float64(this.cents) / 100
+}
+
+type Account struct {
+	owner   string
+	balance Amount
+	linked  *Account
+}
+
+type AccountView struct {
+	Owner  string
+	Linked *Account
+}
+
+func (this *Account) DescribeAs() interface{} {
+	return AccountView{Owner: this.owner, Linked: this.linked}
+}
+
+type Recursive struct {
+	a int
+}
+
+func (this Recursive) DescribeAs() interface{} {
+	return this
+}
+
+func TestDescribableAsList(t *testing.T) {
+	r := &Ring{elements: []interface{}{1, "b", nil}, start: 1}
+
+	expected := `*describe.Ring["b" nil 1]`
+	actual := Describe(r, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.Ring[
+    "b"
+    nil
+    1
+]`
+	actual = Describe(r, 4)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	r.elements = append(r.elements, r)
+	expected = `*1~describe.Ring["b" nil *$1 1]`
+	actual = Describe(r, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `interface[@*1~describe.Ring[…]]`
+	actual = DescribeOpts([]interface{}{r}, WithBreadthFirstBudget(33))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribableAsScalar(t *testing.T) {
+	expected := `describe.Amount<1.5>`
+	actual := Describe(Amount{cents: 150}, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestSelfDescriber(t *testing.T) {
+	a := &Account{owner: "alice", balance: Amount{cents: 100}}
+	b := &Account{owner: "bob", linked: a}
+	a.linked = b
+
+	expected := `*1~describe.AccountView<Owner="alice" Linked=*describe.AccountView<Owner="bob" Linked=*$1>>`
+	actual := Describe(a, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.Recursive<a=1>`
+	actual = Describe(Recursive{a: 1}, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
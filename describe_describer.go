@@ -0,0 +1,144 @@
+package describe
+
+import (
+	"io"
+	"reflect"
+)
+
+// A reusable describer with its own configuration. Options given to
+// NewDescriber() (such as WithCustomDescriber(), WithUnsafeOperations() and
+// WithGlobalDescribers()) only affect this Describer, so that libraries
+// sharing a binary can describe things differently without touching the
+// package-level settings. Custom describers given as options take precedence
+// over global ones, which are only used as a fallback.
+//
+// A Describer doesn't change once created, and is safe for concurrent use by
+// multiple goroutines (provided that any callbacks it was given are too).
+// Options that fill in results, such as WithIndex(), shouldn't be used with a
+// shared Describer. See Reset() for reuse via pools.
+type Describer struct {
+	options options
+}
+
+// Create a Describer that describes objects using opts.
+func NewDescriber(opts ...Option) *Describer {
+	this := &Describer{}
+	this.options.applyOptions(opts)
+	return this
+}
+
+// Reset prepares this Describer for reuse, keeping its options. It's safe to
+// keep Describers in pools (such as alongside other per-request objects), and to
+// call Reset() when they're returned.
+//
+// Reference IDs, cycle tracking and output buffers only live for the duration
+// of a single description, so nothing from one description (or from another
+// request) ever leaks into the next, and calling Reset() is never required.
+func (this *Describer) Reset() {
+	// All per-description state lives in the describer context, which is
+	// created anew for every call.
+}
+
+// Describe an object using this Describer's options.
+func (this *Describer) Describe(v interface{}) string {
+	if description, ok := this.options.describeCompact(v); ok {
+		return description
+	}
+	context := describer{options: this.options}
+	return context.describe(v)
+}
+
+func (this *describer) canUseUnsafe() bool {
+	return canExposeInterface() && !this.disableUnsafe
+}
+
+// What a custom describer was set for. See describerMatch.
+type describerLevel int
+
+const (
+	describerForType describerLevel = iota
+	describerForCounterpart
+	describerForInterface
+	describerForKind
+)
+
+// A custom describer, and where it was set.
+type describerMatch struct {
+	describer ContextDescriber
+	level     describerLevel
+	// The interface that the describer was set for, if any.
+	iface    reflect.Type
+	isGlobal bool
+}
+
+// Get the custom describer to use for type t, if any.
+func (this *options) getCustomDescriber(t reflect.Type) (describer ContextDescriber, ok bool) {
+	match, found := this.findCustomDescriber(t)
+	return match.describer, found && match.describer != nil
+}
+
+// Get the describer set for the named field of struct type structType, if any.
+func (this *options) getFieldDescriber(structType reflect.Type, field string) (describer ContextDescriber, ok bool) {
+	if describer, ok = this.fieldDescribers[fieldKey{structType: structType, field: field}]; ok {
+		return describer, describer != nil
+	}
+	if !this.ignoreGlobalDescribers {
+		if global, ok := fieldDescribers.Load(structType); ok {
+			describer, ok = global.(map[string]ContextDescriber)[field]
+			return describer, ok
+		}
+	}
+	return nil, false
+}
+
+// Find the custom describer for type t. Describers for the concrete type take
+// precedence over describers for its pointer/value counterpart, then interface
+// describers, then kind describers. At each level, describers given as
+// options take precedence over global describers.
+//
+// found is true if a describer was given as an option, even if it's nil
+// (disabled), since that stops the search.
+func (this *options) findCustomDescriber(t reflect.Type) (match describerMatch, found bool) {
+	if describer, ok := this.customDescribers[t]; ok {
+		return describerMatch{describer: describer, level: describerForType}, true
+	}
+	if !this.ignoreGlobalDescribers {
+		if global, ok := customDescribers.Load(t); ok && global != nil {
+			return describerMatch{describer: global.(ContextDescriber), level: describerForType, isGlobal: true}, true
+		}
+	}
+
+	if describer, ok := this.counterpartDescribers[t]; ok {
+		return describerMatch{describer: describer, level: describerForCounterpart}, true
+	}
+	if !this.ignoreGlobalDescribers {
+		if global, ok := counterpartDescribers.Load(t); ok {
+			return describerMatch{describer: global.(ContextDescriber), level: describerForCounterpart, isGlobal: true}, true
+		}
+	}
+
+	if entry := this.interfaceDescribers.find(t); entry != nil {
+		return describerMatch{describer: entry.describer, level: describerForInterface, iface: entry.iface}, true
+	}
+	if !this.ignoreGlobalDescribers {
+		if entry := findInterfaceDescriber(t); entry != nil {
+			return describerMatch{describer: entry.describer, level: describerForInterface, iface: entry.iface, isGlobal: true}, true
+		}
+	}
+
+	if describer, ok := this.kindDescribers[t.Kind()]; ok {
+		return describerMatch{describer: describer, level: describerForKind}, true
+	}
+	if !this.ignoreGlobalDescribers {
+		if describer, ok := getKindDescriber(t); ok {
+			return describerMatch{describer: describer, level: describerForKind, isGlobal: true}, true
+		}
+	}
+	return
+}
+
+// Write the description of an object directly to w. See DescribeTo().
+func (this *Describer) DescribeTo(w io.Writer, v interface{}) error {
+	context := describer{options: this.options}
+	return context.describeTo(w, v)
+}
@@ -0,0 +1,186 @@
+package describe
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type DescriberTest struct {
+	Value int
+}
+
+func TestDescriberIsolation(t *testing.T) {
+	describerType := reflect.TypeOf(DescriberTest{})
+	SetCustomDescriber(describerType, func(v reflect.Value) string { return "global" })
+	defer SetCustomDescriber(describerType, nil)
+
+	a := NewDescriber(WithCustomDescriber(describerType, func(v reflect.Value) string { return "a" }))
+	b := NewDescriber(WithCustomDescriber(describerType, nil))
+	c := NewDescriber(WithGlobalDescribers(false))
+	d := NewDescriber()
+
+	expected := `a`
+	actual := a.Describe(DescriberTest{1})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.DescriberTest<Value=1>`
+	actual = b.Describe(DescriberTest{1})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	actual = c.Describe(DescriberTest{1})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `global`
+	actual = d.Describe(DescriberTest{1})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	actual = D(DescriberTest{1})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type DescriberTestStringer interface {
+	DescribedAs() string
+}
+
+func (this DescriberTest) DescribedAs() string {
+	return "value"
+}
+
+func TestDescriberIsolationInterfacesAndKinds(t *testing.T) {
+	ifaceType := reflect.TypeOf((*DescriberTestStringer)(nil))
+	SetCustomDescriberForInterface(ifaceType, func(v reflect.Value) string { return "global iface" })
+	defer SetCustomDescriberForInterface(ifaceType, nil)
+	SetCustomDescriberForKind(reflect.Int, func(v reflect.Value) string { return "global int" })
+	defer SetCustomDescriberForKind(reflect.Int, nil)
+	v := []interface{}{DescriberTest{1}, 2}
+
+	expected := `interface[@global iface @global int]`
+	actual := NewDescriber().Describe(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `interface[@iface @int]`
+	actual = NewDescriber(
+		WithCustomDescriberForInterface(ifaceType, func(v reflect.Value) string { return "iface" }),
+		WithCustomDescriberForKind(reflect.Int, func(v reflect.Value) string { return "int" }),
+	).Describe(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Kind describers don't override interface describers
+	expected = `interface[@global iface @int]`
+	actual = NewDescriber(
+		WithCustomDescriberForKind(reflect.Struct, func(v reflect.Value) string { return "struct" }),
+		WithCustomDescriberForKind(reflect.Int, func(v reflect.Value) string { return "int" }),
+	).Describe(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `interface[@describe.DescriberTest<Value=1> @2]`
+	actual = NewDescriber(
+		WithCustomDescriberForInterface(ifaceType, nil),
+		WithCustomDescriberForKind(reflect.Int, nil),
+	).Describe(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	actual = NewDescriber(WithGlobalDescribers(false)).Describe(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescriberUnsafeOperations(t *testing.T) {
+	v := &PointerReceiverStringerTest{
+		Exported:   PointerReceiverStringer{1},
+		unexported: PointerReceiverStringer{2},
+	}
+
+	expected := `*describe.PointerReceiverStringerTest<Exported=describe.PointerReceiverStringer<value 1> unexported=describe.PointerReceiverStringer<unexported>>`
+	actual := NewDescriber(WithUnsafeOperations(false)).Describe(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescriberConcurrent(t *testing.T) {
+	describer := NewDescriber(WithIndent(2), WithSortedMapKeys(true))
+	v := map[string][]int{"a": {1, 2}, "b": {3}}
+	expected := describer.Describe(v)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if actual := describer.Describe(v); actual != expected {
+					t.Errorf("Expected %v but got %v", expected, actual)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDescriberChannelLengths(t *testing.T) {
+	ch := make(chan int, 4)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+
+	expected := "chan<int>(len=3 cap=4)"
+	actual := NewDescriber(WithChannelLengths(true)).Describe(ch)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "chan<int>"
+	actual = D(ch)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescriberReset(t *testing.T) {
+	pool := sync.Pool{New: func() interface{} {
+		return NewDescriber(WithMaxElements(1))
+	}}
+
+	a := []interface{}{nil, 2}
+	a[0] = a
+	b := &DescriberTest{Value: 1}
+
+	describer := pool.Get().(*Describer)
+	expected := `1~interface[@$1 …(+1 more elements)]`
+	actual := describer.Describe(a)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	describer.Reset()
+	pool.Put(describer)
+
+	// References and cycle tracking start afresh, and options are kept.
+	describer = pool.Get().(*Describer)
+	expected = `*describe.DescriberTest[*1~describe.DescriberTest<Value=1> …(+1 more elements)]`
+	actual = describer.Describe([]*DescriberTest{b, b})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
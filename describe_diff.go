@@ -0,0 +1,306 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	diffIndent = "  "
+	// Beyond this many comparisons, items are aligned by position only.
+	maxDiffAlignmentCost = 1000000
+)
+
+// DiffDescriptions compares two descriptions and returns a unified-diff-like
+// view of their differences, aligned on the structure of the notation rather
+// than on lines of text:
+//
+//	  main.Config<
+//	    Name="a"
+//	    Server=main.Server<
+//	-     Port=80
+//	+     Port=8080
+//	    >
+//	  >
+//
+// Struct fields and map entries are matched by name or key, and array
+// elements by content. Only containers with differences are expanded;
+// unchanged siblings are shown as context, with unchanged containers
+// summarized. Descriptions are compared exactly as given, so descriptions
+// from different processes should first be passed through Normalize().
+//
+// Returns an empty string if the descriptions are structurally identical.
+func DiffDescriptions(a, b string) string {
+	rootA := parseDescription(a)
+	rootB := parseDescription(b)
+	if compactDescription(rootA) == compactDescription(rootB) {
+		return ""
+	}
+	differ := descriptionDiffer{}
+	differ.diffItems(rootA.items, rootB.items, 0)
+	return differ.buffer.String()
+}
+
+type descriptionDiffer struct {
+	buffer bytes.Buffer
+}
+
+func (this *descriptionDiffer) writeLine(marker string, indent int, text string) {
+	this.buffer.WriteString(marker)
+	this.buffer.WriteString(" ")
+	this.buffer.WriteString(strings.Repeat(diffIndent, indent))
+	this.buffer.WriteString(text)
+	this.buffer.WriteString("\n")
+}
+
+func (this *descriptionDiffer) writeItem(marker string, indent int, item *parsedItem) {
+	this.writeLine(marker, indent, compactItem(item))
+}
+
+func (this *descriptionDiffer) writeContextItem(indent int, item *parsedItem) {
+	this.writeLine(" ", indent, itemKeyText(item)+summarizeNode(item.value))
+}
+
+func (this *descriptionDiffer) diffItems(itemsA, itemsB []*parsedItem, indent int) {
+	for _, pair := range alignItems(itemsA, itemsB) {
+		switch {
+		case pair.b == nil:
+			this.writeItem("-", indent, pair.a)
+		case pair.a == nil:
+			this.writeItem("+", indent, pair.b)
+		case compactItem(pair.a) == compactItem(pair.b):
+			this.writeContextItem(indent, pair.a)
+		default:
+			this.diffNode(itemKeyText(pair.a), pair.a.value, pair.b.value, indent)
+		}
+	}
+}
+
+func (this *descriptionDiffer) diffNode(keyText string, a, b *parsedNode, indent int) {
+	if a.isContainer() && a.prefix == b.prefix && a.head == b.head && a.open == b.open {
+		this.writeLine(" ", indent, keyText+a.prefix+a.head+a.open)
+		this.diffItems(a.items, b.items, indent+1)
+		this.writeLine(" ", indent, a.close)
+		return
+	}
+	this.writeLine("-", indent, keyText+compactDescription(a))
+	this.writeLine("+", indent, keyText+compactDescription(b))
+}
+
+type alignedItems struct {
+	a *parsedItem
+	b *parsedItem
+}
+
+func isKeyedItem(item *parsedItem) bool {
+	return item.key != nil
+}
+
+// Items are identified by key if they have one, or by content otherwise.
+func itemIdentity(item *parsedItem) string {
+	if isKeyedItem(item) {
+		return "k" + compactDescription(item.key)
+	}
+	return "v" + compactDescription(item.value)
+}
+
+// Align two item lists via their longest common subsequence of identities.
+// Unmatched unkeyed items between matches are paired up by position so that
+// they can be compared in detail.
+func alignItems(itemsA, itemsB []*parsedItem) (aligned []alignedItems) {
+	identitiesA := make([]string, len(itemsA))
+	identitiesB := make([]string, len(itemsB))
+	for i, item := range itemsA {
+		identitiesA[i] = itemIdentity(item)
+	}
+	for i, item := range itemsB {
+		identitiesB[i] = itemIdentity(item)
+	}
+
+	var pendingA, pendingB []*parsedItem
+	flushPending := func() {
+		for len(pendingA) > 0 && len(pendingB) > 0 && !isKeyedItem(pendingA[0]) && !isKeyedItem(pendingB[0]) {
+			aligned = append(aligned, alignedItems{pendingA[0], pendingB[0]})
+			pendingA = pendingA[1:]
+			pendingB = pendingB[1:]
+		}
+		for _, item := range pendingA {
+			aligned = append(aligned, alignedItems{a: item})
+		}
+		for _, item := range pendingB {
+			aligned = append(aligned, alignedItems{b: item})
+		}
+		pendingA = nil
+		pendingB = nil
+	}
+
+	consumedA, consumedB := 0, 0
+	for _, match := range findCommonSubsequence(identitiesA, identitiesB) {
+		pendingA = itemsA[consumedA:match.indexA]
+		pendingB = itemsB[consumedB:match.indexB]
+		flushPending()
+		aligned = append(aligned, alignedItems{itemsA[match.indexA], itemsB[match.indexB]})
+		consumedA = match.indexA + 1
+		consumedB = match.indexB + 1
+	}
+	pendingA = itemsA[consumedA:]
+	pendingB = itemsB[consumedB:]
+	flushPending()
+	return
+}
+
+type subsequenceMatch struct {
+	indexA int
+	indexB int
+}
+
+// Find the longest common subsequence of a and b.
+func findCommonSubsequence(a, b []string) (matches []subsequenceMatch) {
+	if len(a)*len(b) > maxDiffAlignmentCost {
+		// Too expensive: match only identical items at identical positions.
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if a[i] == b[i] {
+				matches = append(matches, subsequenceMatch{i, i})
+			}
+		}
+	} else {
+		lengths := make([][]int, len(a)+1)
+		for i := range lengths {
+			lengths[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lengths[i][j] = lengths[i+1][j+1] + 1
+				} else if lengths[i+1][j] >= lengths[i][j+1] {
+					lengths[i][j] = lengths[i+1][j]
+				} else {
+					lengths[i][j] = lengths[i][j+1]
+				}
+			}
+		}
+		for i, j := 0, 0; i < len(a) && j < len(b); {
+			switch {
+			case a[i] == b[j]:
+				matches = append(matches, subsequenceMatch{i, j})
+				i++
+				j++
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				i++
+			default:
+				j++
+			}
+		}
+	}
+	return
+}
+
+func itemKeyText(item *parsedItem) string {
+	if item.key == nil {
+		return ""
+	}
+	return compactDescription(item.key) + tokKeyValueSeparator
+}
+
+func compactItem(item *parsedItem) string {
+	return itemKeyText(item) + compactDescription(item.value)
+}
+
+// Render a node on a single line, regardless of how it was laid out.
+func compactDescription(node *parsedNode) string {
+	var buffer bytes.Buffer
+	writeCompact(&buffer, node)
+	return buffer.String()
+}
+
+func writeCompact(buffer *bytes.Buffer, node *parsedNode) {
+	buffer.WriteString(node.prefix)
+	buffer.WriteString(node.head)
+	buffer.WriteString(node.open)
+	for i, item := range node.items {
+		if i > 0 {
+			buffer.WriteString(" ")
+		}
+		if item.key != nil {
+			writeCompact(buffer, item.key)
+			buffer.WriteString(tokKeyValueSeparator)
+		}
+		writeCompact(buffer, item.value)
+	}
+	buffer.WriteString(node.close)
+}
+
+// Summarize a node for use as context: containers with contents are elided.
+func summarizeNode(node *parsedNode) string {
+	if len(node.items) == 0 {
+		return compactDescription(node)
+	}
+	return node.prefix + node.head + node.open + tokElided + node.close
+}
+
+// Summarize the changes from description a to b on a single line, as a
+// space-separated list of paths (built like Go expressions, as with
+// WithAnnotator): `Server.Port=8080 +Tags[2]="new" -Env["OLD"]`
+//
+// Changed and added (`+`) values are followed by their new description, and
+// removed values (`-`) by nothing. A replaced top-level value is given in
+// full. Returns an empty string if there are no differences.
+func describeChanges(a, b string) string {
+	var changes []string
+	collectItemChanges("", "", parseDescription(a).items, parseDescription(b).items, &changes)
+	return strings.Join(changes, tokItemSeparator)
+}
+
+func collectItemChanges(path string, open string, itemsA, itemsB []*parsedItem, changes *[]string) {
+	indexA, indexB := 0, 0
+	for _, pair := range alignItems(itemsA, itemsB) {
+		switch {
+		case pair.b == nil:
+			*changes = append(*changes, "-"+buildChangePath(path, open, pair.a, indexA))
+		case pair.a == nil:
+			itemPath := buildChangePath(path, open, pair.b, indexB)
+			*changes = append(*changes, "+"+itemPath+tokKeyValueSeparator+compactDescription(pair.b.value))
+		default:
+			collectNodeChanges(buildChangePath(path, open, pair.b, indexB), pair.a.value, pair.b.value, changes)
+		}
+		if pair.a != nil {
+			indexA++
+		}
+		if pair.b != nil {
+			indexB++
+		}
+	}
+}
+
+func collectNodeChanges(path string, a, b *parsedNode, changes *[]string) {
+	descriptionB := compactDescription(b)
+	if compactDescription(a) == descriptionB {
+		return
+	}
+	if a.isContainer() && a.prefix == b.prefix && a.head == b.head && a.open == b.open {
+		collectItemChanges(path, a.open, a.items, b.items, changes)
+		return
+	}
+	if path == "" {
+		*changes = append(*changes, descriptionB)
+		return
+	}
+	*changes = append(*changes, path+tokKeyValueSeparator+descriptionB)
+}
+
+func buildChangePath(parentPath string, open string, item *parsedItem, index int) string {
+	switch {
+	case open == "":
+		return parentPath
+	case open == tokOpenStruct && item.key != nil:
+		if parentPath == "" {
+			return compactDescription(item.key)
+		}
+		return parentPath + "." + compactDescription(item.key)
+	case item.key != nil:
+		return parentPath + tokOpenArray + compactDescription(item.key) + tokCloseArray
+	default:
+		return fmt.Sprintf("%v%v%v%v", parentPath, tokOpenArray, index, tokCloseArray)
+	}
+}
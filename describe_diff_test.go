@@ -0,0 +1,55 @@
+package describe
+
+import (
+	"testing"
+)
+
+func TestDiffDescriptions(t *testing.T) {
+	a := `describe.X<A=1 B=describe.Y<C=2 D=int[9]> E=int[1 2 3] F=string:int{"x"=1 "y"=2}>`
+	b := "describe.X<\n    A=1\n    B=describe.Y<C=3 D=int[9]>\n    E=int[1 3 4]\n    F=string:int{\"y\"=2 \"z\"=3}\n>"
+	expected := `  describe.X<
+    A=1
+    B=describe.Y<
+-     C=2
++     C=3
+      D=int[…]
+    >
+    E=int[
+      1
+-     2
+      3
++     4
+    ]
+    F=string:int{
+-     "x"=1
+      "y"=2
++     "z"=3
+    }
+  >
+`
+	actual := DiffDescriptions(a, b)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDiffDescriptionsChangedType(t *testing.T) {
+	expected := "- int[1 2]\n+ \"a\"\n"
+	actual := DiffDescriptions(`int[1 2]`, `"a"`)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	expected = "  describe.X<\n-   A=describe.Y<B=1>\n+   A=describe.Z<B=1>\n  >\n"
+	actual = DiffDescriptions(`describe.X<A=describe.Y<B=1>>`, `describe.X<A=describe.Z<B=1>>`)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDiffDescriptionsIdentical(t *testing.T) {
+	expected := ""
+	actual := DiffDescriptions("int[1 2]", "int[\n    1\n    2\n]")
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -0,0 +1,173 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Describes an object graph in Graphviz DOT format, for rendering cyclic or
+// heavily shared structures as a picture (for example with
+// `dot -Tsvg graph.dot > graph.svg`).
+//
+// Every non-empty struct, array, slice and map is a node, listing its type and
+// any fields or elements that aren't themselves nodes. Fields and elements
+// that are nodes (directly, or through pointers and interfaces) are edges,
+// labeled with the field name, index or map key. Data that's reachable via
+// multiple paths is a single node with multiple incoming edges:
+//
+//	digraph describe {
+//	  node [shape=box];
+//	  n1 [label="main.Node\l\lName=\"a\"\l"];
+//	  n1 -> n1 [label="Next"];
+//	}
+//
+// Values described by user code (custom describers, String() methods) are
+// shown using their descriptions rather than as nodes.
+func DescribeDOT(v interface{}, opts ...Option) (description string) {
+	context := describer{}
+	context.applyOptions(opts)
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.describeDOT(rv)
+}
+
+type dotGraph struct {
+	describer  *describer
+	nodeIDs    map[duplicates.TypedPointer]int
+	lastNodeID int
+	statements []string
+}
+
+func (this *describer) describeDOT(rv reflect.Value) (description string) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				description = notifyRecoveredLibraryBug(e)
+			}
+		}
+	}()
+
+	graph := dotGraph{
+		describer: this,
+		nodeIDs:   make(map[duplicates.TypedPointer]int),
+	}
+	if target := graph.getNodeTarget(rv); target.IsValid() {
+		graph.addNode(target)
+	} else {
+		graph.lastNodeID++
+		graph.addStatement("n%v [label=%v]", graph.lastNodeID, quoteDOT(this.describeWithUserCode(rv)))
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("digraph describe {\n  node [shape=box];\n")
+	for _, statement := range graph.statements {
+		builder.WriteString("  ")
+		builder.WriteString(statement)
+		builder.WriteString(";\n")
+	}
+	builder.WriteString("}")
+	return builder.String()
+}
+
+func (this *dotGraph) addStatement(format string, args ...interface{}) {
+	this.statements = append(this.statements, fmt.Sprintf(format, args...))
+}
+
+// Get what v should be drawn as a node as (following pointers and
+// interfaces), or an invalid value if v should be shown inline instead.
+func (this *dotGraph) getNodeTarget(v reflect.Value) (target reflect.Value) {
+	v = followPointers(v, this.describer.isDescribedByUserCode)
+	if !v.IsValid() || isNil(v) || this.describer.isDescribedByUserCode(v) {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if len(getVisibleFieldIndices(v)) > 0 {
+			return v
+		}
+	case reflect.Array, reflect.Slice, reflect.Map:
+		// Empty slices may share their (zero-length) storage.
+		if v.Len() > 0 {
+			return v
+		}
+	}
+	return
+}
+
+// Add a node for v (unless it was already added), returning its ID.
+func (this *dotGraph) addNode(v reflect.Value) (id int) {
+	ptr, hasIdentity := getReferencePointer(v)
+	if hasIdentity {
+		if id, ok := this.nodeIDs[ptr]; ok {
+			return id
+		}
+	}
+	this.lastNodeID++
+	id = this.lastNodeID
+	if hasIdentity {
+		this.nodeIDs[ptr] = id
+	}
+
+	// Edges are added while the node's contents are gathered, so the node
+	// itself goes before them.
+	nodeIndex := len(this.statements)
+	this.statements = append(this.statements, "")
+	lines := []string{this.describer.typeName(v.Type()), ""}
+	addChild := func(name string, child reflect.Value) {
+		if target := this.getNodeTarget(child); target.IsValid() {
+			this.addStatement("n%v -> n%v [label=%v]", id, this.addNode(target), quoteDOT(name))
+		} else {
+			lines = append(lines, name+tokKeyValueSeparator+this.describer.describeWithUserCode(child))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, i := range getVisibleFieldIndices(v) {
+			addChild(getFieldName(v.Type(), i), this.describer.getDescribedField(v, i))
+		}
+	case reflect.Array, reflect.Slice:
+		count := this.describer.getElementCountToDescribe(v.Len())
+		for i := 0; i < count; i++ {
+			addChild(fmt.Sprintf("[%v]", i), v.Index(i))
+		}
+		lines = appendOmittedDOTLine(lines, v.Len()-count, tokMoreElements)
+	case reflect.Map:
+		iter, omittedCount := this.describer.iterateMap(v)
+		for iter.Next() {
+			addChild("["+this.describer.describeWithUserCode(iter.Key())+"]", this.describer.getDescribedMapValue(iter.Key(), iter.Value()))
+		}
+		lines = appendOmittedDOTLine(lines, omittedCount, tokMoreEntries)
+	}
+
+	label := strings.Builder{}
+	for _, line := range lines {
+		label.WriteString(escapeDOT(line))
+		label.WriteString(`\l`)
+	}
+	this.statements[nodeIndex] = fmt.Sprintf(`n%v [label="%v"]`, id, label.String())
+	return
+}
+
+func appendOmittedDOTLine(lines []string, count int, items string) []string {
+	if count > 0 {
+		lines = append(lines, fmt.Sprintf("%v(+%v more %v)", tokElided, count, items))
+	}
+	return lines
+}
+
+func escapeDOT(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return strings.Replace(s, "\n", `\n`, -1)
+}
+
+func quoteDOT(s string) string {
+	return `"` + escapeDOT(s) + `"`
+}
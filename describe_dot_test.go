@@ -0,0 +1,40 @@
+package describe
+
+import (
+	"testing"
+)
+
+type DOTNode struct {
+	Name     string
+	Next     *DOTNode
+	Children map[string]*DOTNode
+}
+
+func TestDescribeDOT(t *testing.T) {
+	v := &DOTNode{Name: "a"}
+	v.Next = v
+	v.Children = map[string]*DOTNode{"b": {Name: "b", Next: v}}
+
+	expected := `digraph describe {
+  node [shape=box];
+  n1 [label="describe.DOTNode\l\lName=\"a\"\l"];
+  n1 -> n1 [label="Next"];
+  n2 [label="map[string]*describe.DOTNode\l\l"];
+  n3 [label="describe.DOTNode\l\lName=\"b\"\lChildren=nil\l"];
+  n3 -> n1 [label="Next"];
+  n2 -> n3 [label="[\"b\"]"];
+  n1 -> n2 [label="Children"];
+}`
+	actual := DescribeDOT(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeDOTScalar(t *testing.T) {
+	expected := "digraph describe {\n  node [shape=box];\n  n1 [label=\"\\\"x\\\"\"];\n}"
+	actual := DescribeDOT("x")
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
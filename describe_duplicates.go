@@ -0,0 +1,155 @@
+package describe
+
+import (
+	"reflect"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Scans data for duplicate pointers the same way that the duplicates library
+// does, but also follows data that the describer shows and that can't be
+// reached via plain fields:
+//
+//   - The contents of reflect.Values (which the library sees as opaque structs)
+//   - What types that describe themselves (see DescribableAsMap etc) are
+//     described as, rather than their internal structure
+//
+// Any pointer that the describer can reach must be scanned, or cycles through
+// it won't be detected.
+type duplicateScanner struct {
+	describer *describer
+	finder    *duplicates.DuplicateFinder
+}
+
+const scannableKinds uint = (uint(1) << reflect.Interface) |
+	(uint(1) << reflect.Ptr) |
+	(uint(1) << reflect.Slice) |
+	(uint(1) << reflect.Map) |
+	(uint(1) << reflect.Array) |
+	(uint(1) << reflect.Struct)
+
+func isScannableKind(kind reflect.Kind) bool {
+	return scannableKinds&(uint(1)<<kind) != 0
+}
+
+// Find all duplicate pointers in roots (including pointers shared between
+// roots). Every duplicate is present in the returned map, with a reference
+// name of 0. Reference names are assigned in the order that the duplicates are
+// first described (see assignReferenceName), so that descriptions are stable.
+func (this *describer) findDuplicates(roots ...reflect.Value) map[duplicates.TypedPointer]int {
+	scanner := duplicateScanner{
+		describer: this,
+		finder:    duplicates.NewDuplicateFinder(),
+	}
+	for _, v := range roots {
+		// Scanning an addressable root via its address records it the same
+		// way that the describer looks it up, so that cycles back to it are
+		// found.
+		if (v.Kind() == reflect.Struct || v.Kind() == reflect.Array) && v.CanAddr() {
+			v = v.Addr()
+		}
+		scanner.scan(v)
+	}
+
+	referenceNames := map[duplicates.TypedPointer]int{}
+	for pointer, isDuplicate := range scanner.finder.DuplicatePointers {
+		if isDuplicate {
+			referenceNames[pointer] = 0
+		}
+	}
+	return referenceNames
+}
+
+func (this *duplicateScanner) scan(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			this.scan(v.Elem())
+		}
+	case reflect.Ptr:
+		if v.IsNil() || this.finder.RegisterPointer(v) {
+			return
+		}
+		this.scan(v.Elem())
+	case reflect.Map:
+		if v.IsNil() || v.Len() == 0 || this.finder.RegisterPointer(v) {
+			return
+		}
+		scanKeys := isScannableKind(v.Type().Key().Kind())
+		scanValues := isScannableKind(v.Type().Elem().Kind())
+		if !scanKeys && !scanValues {
+			return
+		}
+		for iter := mapRange(v); iter.Next(); {
+			if scanKeys {
+				this.scan(iter.Key())
+			}
+			if scanValues {
+				this.scan(iter.Value())
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() || v.Len() == 0 || this.finder.RegisterPointer(v) {
+			return
+		}
+		this.scanElements(v)
+	case reflect.Array:
+		this.scanElements(v)
+	case reflect.Struct:
+		if v.Type() == reflectValueType {
+			this.scanReflectValueContents(v)
+			return
+		}
+		if this.scanDescribable(v) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !isScannableKind(field.Kind()) {
+				continue
+			}
+			// Scanning via the field's address records it the same way that
+			// the describer looks it up.
+			if field.CanAddr() {
+				field = field.Addr()
+			}
+			this.scan(field)
+		}
+	}
+}
+
+func (this *duplicateScanner) scanElements(v reflect.Value) {
+	if !isScannableKind(v.Type().Elem().Kind()) {
+		return
+	}
+	for i := 0; i < v.Len(); i++ {
+		this.scan(v.Index(i))
+	}
+}
+
+func (this *duplicateScanner) scanReflectValueContents(v reflect.Value) {
+	contents, ok := this.describer.getInterfaceAsReflectValue(v)
+	if !ok || !contents.IsValid() {
+		return
+	}
+	if contents.CanAddr() {
+		contents = contents.Addr()
+	}
+	this.scan(contents)
+}
+
+// Scan what a self-describing type would be described as. Returns false if v
+// doesn't describe itself.
+func (this *duplicateScanner) scanDescribable(v reflect.Value) bool {
+	contents, ok := this.describer.getDescribableContents(v)
+	if !ok {
+		return false
+	}
+	for _, key := range contents.keys {
+		this.scan(key)
+	}
+	for _, value := range contents.values {
+		this.scan(value)
+	}
+	return true
+}
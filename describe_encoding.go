@@ -0,0 +1,241 @@
+package describe
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Describes v, followed by what encoding/json would emit for it, followed by
+// notes (one per line, by path) on where the two differ. Example:
+//
+//	main.User<Name="bob" password="hunter2" Age=0 Created=time.Time<…>>
+//	json: {"name":"bob","Created":"2020-01-01T00:00:00Z"}
+//	Name: encoded as "name"
+//	password: not encoded (unexported)
+//	Age: omitted (omitempty with empty value)
+//	Created: omitempty has no effect on structs
+//
+// Values of types with their own MarshalJSON() or MarshalText() methods are
+// not looked into. Most marshaling bugs come from the gap between what a
+// value holds and what gets encoded, which this makes visible.
+func EncodingPreview(v interface{}) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(D(v))
+	buffer.WriteString("\njson: ")
+	buffer.WriteString(marshalJSONForPreview(v))
+
+	previewer := encodingPreviewer{onPath: make(map[duplicates.TypedPointer]bool)}
+	previewer.inspect(reflect.ValueOf(v))
+	for _, note := range previewer.notes {
+		buffer.WriteString("\n")
+		buffer.WriteString(note)
+	}
+	return buffer.String()
+}
+
+func marshalJSONForPreview(v interface{}) (result string) {
+	defer func() {
+		if e := recover(); e != nil {
+			result = fmt.Sprintf("error: panic(%v)", e)
+		}
+	}()
+	// encoding/json can recurse forever on cyclic data, which can't be
+	// recovered from.
+	if hasCycle(reflect.ValueOf(v)) {
+		return "error: the value contains a cycle"
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	return string(encoded)
+}
+
+type encodingPreviewer struct {
+	// The options that map keys in paths are described with
+	keyOptions options
+	path       []pathSegment
+	notes      []string
+	// Pointers, maps and slices on the path to the current value, which
+	// would lead back to it if encountered again.
+	onPath map[duplicates.TypedPointer]bool
+}
+
+func (this *encodingPreviewer) note(format string, args ...interface{}) {
+	note := fmt.Sprintf(format, args...)
+	if path := buildPath(this.path, &this.keyOptions); path != "" {
+		note = path + ": " + note
+	}
+	this.notes = append(this.notes, note)
+}
+
+func (this *encodingPreviewer) inspectChild(segment pathSegment, v reflect.Value) {
+	this.path = append(this.path, segment)
+	this.inspect(v)
+	this.path = this.path[:len(this.path)-1]
+}
+
+func (this *encodingPreviewer) inspect(v reflect.Value) {
+	if !v.IsValid() || hasCustomJSONEncoding(v) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		ptr := duplicates.TypedPointerOfRV(v)
+		if this.onPath[ptr] {
+			return
+		}
+		this.onPath[ptr] = true
+		defer delete(this.onPath, ptr)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		this.inspect(v.Elem())
+	case reflect.Interface:
+		if !v.IsNil() {
+			this.inspect(v.Elem())
+		}
+	case reflect.Struct:
+		this.inspectStruct(v)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if !v.IsNil() {
+				this.note("encoded as a base64 string")
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			this.inspectChild(indexSegment(i), v.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			this.inspectChild(indexSegment(i), v.Index(i))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sortMapKeys(keys)
+		for _, key := range keys {
+			this.inspectChild(keySegment(key), v.MapIndex(key))
+		}
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		this.note("not supported by encoding/json (%v)", v.Kind())
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			this.note("not supported by encoding/json (%v)", f)
+		}
+	}
+}
+
+func (this *encodingPreviewer) inspectStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		segment := fieldSegment(field.Name)
+		name, options := parseJSONTag(field.Tag.Get("json"))
+		if field.PkgPath != "" && !(field.Anonymous && isStructOrPointerToStruct(field.Type)) {
+			this.inspectNote(segment, "not encoded (unexported)")
+			continue
+		}
+		if name == "-" && options == "" {
+			this.inspectNote(segment, `not encoded (tag "-")`)
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if hasJSONOption(options, "omitempty") {
+			if isEmptyJSONValue(fieldValue) {
+				this.inspectNote(segment, "omitted (omitempty with empty value)")
+				continue
+			}
+			if fieldValue.Kind() == reflect.Struct {
+				this.inspectNote(segment, "omitempty has no effect on structs")
+			}
+		}
+		if name != "" && name != field.Name {
+			this.inspectNote(segment, "encoded as %q", name)
+		}
+		this.inspectChild(segment, fieldValue)
+	}
+}
+
+func (this *encodingPreviewer) inspectNote(segment pathSegment, format string, args ...interface{}) {
+	this.path = append(this.path, segment)
+	this.note(format, args...)
+	this.path = this.path[:len(this.path)-1]
+}
+
+// Returns true if v is encoded using its own MarshalJSON() or MarshalText()
+// method, in which case its contents are none of our business.
+func hasCustomJSONEncoding(v reflect.Value) bool {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return false
+	}
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return true
+	}
+	if v.CanAddr() {
+		pt := reflect.PtrTo(t)
+		return pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType)
+	}
+	return false
+}
+
+func parseJSONTag(tag string) (name string, options string) {
+	if index := strings.Index(tag, ","); index >= 0 {
+		return tag[:index], tag[index+1:]
+	}
+	return tag, ""
+}
+
+func hasJSONOption(options string, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func isStructOrPointerToStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// Same rules as encoding/json uses for omitempty.
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package describe
+
+import (
+	"testing"
+	"time"
+)
+
+type EncodingUser struct {
+	Name     string `json:"name"`
+	password string
+	Age      int       `json:",omitempty"`
+	Created  time.Time `json:",omitempty"`
+	Internal string    `json:"-"`
+	Avatar   []byte
+	Friends  []*EncodingUser
+}
+
+func TestEncodingPreview(t *testing.T) {
+	user := &EncodingUser{
+		Name:     "bob",
+		password: "hunter2",
+		Created:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Internal: "x",
+		Avatar:   []byte{1},
+		Friends:  []*EncodingUser{{Name: "alice"}},
+	}
+
+	expected := `*describe.EncodingUser<Name="bob" password="hunter2" Age=0 Created=time.Time<2020-01-01 00:00:00 +0000 UTC> Internal="x" Avatar=uint8[0x01] Friends=*describe.EncodingUser[*describe.EncodingUser<Name="alice" password="" Age=0 Created=time.Time<wall=0 ext=0 loc=nil> Internal="" Avatar=nil Friends=nil>]>
+json: {"name":"bob","Created":"2020-01-01T00:00:00Z","Avatar":"AQ==","Friends":[{"name":"alice","Created":"0001-01-01T00:00:00Z","Avatar":null,"Friends":null}]}
+Name: encoded as "name"
+password: not encoded (unexported)
+Age: omitted (omitempty with empty value)
+Created: omitempty has no effect on structs
+Internal: not encoded (tag "-")
+Avatar: encoded as a base64 string
+Friends[0].Name: encoded as "name"
+Friends[0].password: not encoded (unexported)
+Friends[0].Age: omitted (omitempty with empty value)
+Friends[0].Created: omitempty has no effect on structs
+Friends[0].Internal: not encoded (tag "-")`
+	actual := EncodingPreview(user)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestEncodingPreviewUnsupported(t *testing.T) {
+	expected := `string:interface{"a"=@func()()}
+json: error: json: unsupported type: func()
+["a"]: not supported by encoding/json (func)`
+	actual := EncodingPreview(map[string]interface{}{"a": func() {}})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -0,0 +1,320 @@
+package describe
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// An error that wraps multiple errors, such as those produced by errors.Join()
+// and fmt.Errorf() with multiple %w verbs (Go 1.20+).
+type multiError interface {
+	Unwrap() []error
+}
+
+var multiErrorType = reflect.TypeOf((*multiError)(nil)).Elem()
+
+// Describe a multi-error as its type name, followed by the errors it wraps
+// within `<>`. Wrapped multi-errors are described the same way, so the full
+// error tree is shown (one error per line in multiline mode).
+//
+// Like String() methods, Unwrap() is looked for on both value and pointer
+// receivers, and is checked once a pointer has been followed (so that
+// duplicates and cycles are marked as usual).
+func (this *describer) tryDescribeMultiError(v reflect.Value) (didDescribe bool) {
+	if this.reflectionOnly {
+		return
+	}
+	receiver, ok := this.getReceiverImplementing(v, multiErrorType)
+	if !ok {
+		return
+	}
+	asInterface, ok := this.getInterface(receiver)
+	if !ok {
+		return
+	}
+
+	var errs []error
+	ok, didTimeOut := this.callUserCode(v.Type(), func() {
+		errs = asInterface.(multiError).Unwrap()
+	})
+	if didTimeOut {
+		this.writeString(this.describeTimeout(v))
+		didDescribe = true
+		return
+	}
+	if !ok {
+		// Fall back to describing the error's contents
+		return
+	}
+
+	errsValue := reflect.ValueOf(errs)
+	this.writeTypeName(v.Type())
+	this.writeString(tokOpenStruct)
+	this.increaseIndent()
+	for i := 0; i < errsValue.Len() && !this.isOutputFull(); i++ {
+		this.writeItemSeparator(i == 0)
+		this.describeChild(indexSegment(i), unwrapInterface(errsValue.Index(i)), false)
+	}
+	this.decreaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString(tokCloseStruct)
+	didDescribe = true
+	return
+}
+
+// An error that wraps a single error, such as those produced by fmt.Errorf()
+// with a %w verb.
+type wrapperError interface {
+	Unwrap() error
+}
+
+// Describe an error as its type name, followed by its message and the error(s)
+// it wraps (if any) within `<>`. Wrapped errors are described the same way, so
+// the full chain (or tree, for multi-errors) is shown:
+//
+//	*fmt.wrapError<Error="load: missing" Unwrap=*errors.errorString<Error="missing">>
+//
+// Only done if enabled via WithErrorChains(). If Error() or Unwrap() panics,
+// the error's contents are described instead.
+func (this *describer) tryDescribeErrorChain(v reflect.Value) (didDescribe bool) {
+	if !this.errorChains || this.reflectionOnly {
+		return
+	}
+	receiver, ok := this.getReceiverImplementing(v, errorType)
+	if !ok {
+		return
+	}
+	asInterface, ok := this.getInterface(receiver)
+	if !ok {
+		return
+	}
+	if isInUserDescription(v) {
+		this.writeEnclosingReference(v)
+		didDescribe = true
+		return
+	}
+
+	var message string
+	var wrapped reflect.Value
+	ok, didTimeOut := this.callUserCode(v.Type(), func() {
+		message = asInterface.(error).Error()
+		switch err := asInterface.(type) {
+		case wrapperError:
+			if unwrapped := err.Unwrap(); unwrapped != nil {
+				wrapped = valueOfInterface(unwrapped)
+			}
+		case multiError:
+			if unwrapped := err.Unwrap(); len(unwrapped) > 0 {
+				wrapped = reflect.ValueOf(unwrapped)
+			}
+		}
+	})
+	if didTimeOut {
+		this.writeString(this.describeTimeout(v))
+		didDescribe = true
+		return
+	}
+	if !ok {
+		// Fall back to describing the error's contents
+		return
+	}
+
+	this.writeTypeName(v.Type())
+	this.writeString(tokOpenStruct)
+	this.increaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString("Error")
+	this.writeKeyValueSeparator()
+	this.writeFmt("%q", message)
+	if wrapped.IsValid() {
+		this.writeItemSeparator(false)
+		this.writeString("Unwrap")
+		this.writeKeyValueSeparator()
+		// Catch errors that wrap themselves.
+		runUserDescription(v, func() string {
+			this.describeChild(fieldSegment("Unwrap"), wrapped, false)
+			return ""
+		})
+	}
+	this.decreaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString(tokCloseStruct)
+	didDescribe = true
+	return
+}
+
+// Get the value (or pointer to the value) that implements iface, so that
+// methods with pointer receivers are found too. Pointers and interfaces are
+// rejected, since they are checked once followed.
+func (this *describer) getReceiverImplementing(v reflect.Value, iface reflect.Type) (receiver reflect.Value, ok bool) {
+	if !v.IsValid() || v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return
+	}
+	if v.Type().Implements(iface) {
+		return v, true
+	}
+	if !reflect.PtrTo(v.Type()).Implements(iface) {
+		return
+	}
+	return this.getPointerTo(v)
+}
+
+// Call user code with the same protections as String() methods. Returns
+// ok = false if the code panicked or timed out.
+func (this *describer) callUserCode(t reflect.Type, code func()) (ok bool, didTimeOut bool) {
+	defer func() {
+		if e := recover(); e != nil {
+			notifyRecoveredPanic(t, e)
+			ok = false
+		}
+	}()
+	if _, completed := this.runUserCode(func() string {
+		code()
+		return ""
+	}); !completed {
+		didTimeOut = true
+		return
+	}
+	return true, false
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Describe an error that carries a call stack as its message and a condensed
+// stack. Only done if enabled via WithErrorStacks().
+func (this *describer) tryDescribeErrorStack(v reflect.Value) (didDescribe bool) {
+	if this.errorStackFrames <= 0 || this.reflectionOnly {
+		return
+	}
+	receiver, ok := this.getReceiverImplementing(v, errorType)
+	if !ok || !hasErrorStack(receiver) {
+		return
+	}
+	asInterface, ok := this.getInterface(receiver)
+	if !ok {
+		return
+	}
+
+	var message string
+	var stack []uintptr
+	ok, didTimeOut := this.callUserCode(v.Type(), func() {
+		message = asInterface.(error).Error()
+		// The receiver can't call methods if it was reached through an
+		// unexported field, but the exposed interface can.
+		stack = getErrorStack(reflect.ValueOf(asInterface))
+	})
+	if didTimeOut {
+		this.writeString(this.describeTimeout(v))
+		didDescribe = true
+		return
+	}
+	if !ok {
+		return
+	}
+
+	this.writeTypeName(v.Type())
+	this.writeString(tokOpenStruct)
+	this.increaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString("Error")
+	this.writeKeyValueSeparator()
+	this.writeFmt("%q", message)
+	this.writeItemSeparator(false)
+	this.writeString("Stack")
+	this.writeKeyValueSeparator()
+	this.writeString(tokOpenArray)
+	this.increaseIndent()
+	frames := runtime.CallersFrames(stack)
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if frame.PC == 0 {
+			break
+		}
+		this.writeItemSeparator(i == 0)
+		if i >= this.errorStackFrames {
+			this.writeString(tokElided)
+			break
+		}
+		this.writeString(describeStackFrame(frame))
+		if !more {
+			break
+		}
+	}
+	this.decreaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString(tokCloseArray)
+	this.decreaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString(tokCloseStruct)
+	didDescribe = true
+	return
+}
+
+// Stacks are recorded as program counters (return addresses, as returned by
+// runtime.Callers), in a slice of some uintptr type.
+func isProgramCounters(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uintptr
+}
+
+func getStackTraceMethod(receiver reflect.Value) (method reflect.Value, ok bool) {
+	method = receiver.MethodByName("StackTrace")
+	if !method.IsValid() {
+		return
+	}
+	t := method.Type()
+	ok = t.NumIn() == 0 && t.NumOut() == 1 && isProgramCounters(t.Out(0))
+	return
+}
+
+func getStackField(receiver reflect.Value) (field reflect.StructField, ok bool) {
+	t := reflect.Indirect(receiver).Type()
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	if field, ok = t.FieldByName("stack"); !ok {
+		return
+	}
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	ok = isProgramCounters(fieldType)
+	return
+}
+
+func hasErrorStack(receiver reflect.Value) bool {
+	if _, ok := getStackTraceMethod(receiver); ok {
+		return true
+	}
+	_, ok := getStackField(receiver)
+	return ok
+}
+
+func getErrorStack(receiver reflect.Value) (stack []uintptr) {
+	var pcs reflect.Value
+	if method, ok := getStackTraceMethod(receiver); ok && method.CanInterface() {
+		pcs = method.Call(nil)[0]
+	} else if field, ok := getStackField(receiver); ok {
+		pcs = reflect.Indirect(reflect.Indirect(receiver).FieldByIndex(field.Index))
+	}
+	if !pcs.IsValid() {
+		return
+	}
+	stack = make([]uintptr, pcs.Len())
+	for i := range stack {
+		stack[i] = uintptr(pcs.Index(i).Uint())
+	}
+	return
+}
+
+// Condense a frame to `pkg.Function(file.go:line)`
+func describeStackFrame(frame runtime.Frame) string {
+	function := frame.Function
+	if index := strings.LastIndex(function, "/"); index >= 0 {
+		function = function[index+1:]
+	}
+	return fmt.Sprintf("%v(%v:%v)", function, filepath.Base(frame.File), frame.Line)
+}
@@ -0,0 +1,204 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// ExplainDescribers reports what will describe each value in v's object graph
+// (using opts), and why. This is for debugging situations like "why didn't my
+// registered describer get called", which is usually because it was set for T,
+// but the graph holds *T (or the other way around):
+//
+//	(top): main.Request: default
+//	Body: *main.Payload: default → main.Payload: custom describer for main.Payload (global)
+//	Token: main.Secret: default (a custom describer is set for *main.Secret, which doesn't match main.Secret values without MatchPointersAndValues)
+//	Sent: time.Time: String() method
+//
+// There is one line per value, as visited by Walk(). Pointers and interfaces
+// that are followed are shown as a chain ending with what describes the value
+// they lead to. Values whose describer takes care of their contents aren't
+// examined any further.
+//
+// Only types and options are examined: no user code (such as custom
+// describers or String() methods) is called.
+func ExplainDescribers(v interface{}, opts ...Option) string {
+	context := describer{}
+	context.applyOptions(opts)
+
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.explainDescribers(rv)
+}
+
+func (this *describer) explainDescribers(v reflect.Value) (explanation string) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				explanation = notifyRecoveredLibraryBug(e)
+			}
+		}
+	}()
+
+	// Map keys in paths are described without calling user code.
+	keyOptions := this.options
+	keyOptions.reflectionOnly = true
+	var builder strings.Builder
+	walkValue(v, keyOptions, func(path string, v reflect.Value, depth int) bool {
+		if path == "" {
+			path = "(top)"
+		}
+		chain, describesContents := this.explainChain(v)
+		builder.WriteString(path)
+		builder.WriteString(": ")
+		builder.WriteString(chain)
+		builder.WriteString("\n")
+		return describesContents
+	})
+	return builder.String()
+}
+
+// Explain what describes v, following pointers and interfaces for as long as
+// they're described by default. describesContents is true if v's contents will
+// be described by default as well.
+func (this *describer) explainChain(v reflect.Value) (chain string, describesContents bool) {
+	var links []string
+	var followed map[duplicates.TypedPointer]bool
+	for {
+		explanation, isDefault := this.explainDescriber(v)
+		if v.IsValid() {
+			explanation = this.typeName(v.Type()) + ": " + explanation
+		}
+		links = append(links, explanation)
+		if !isDefault {
+			break
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			describesContents = true
+			break
+		}
+		// Chains of pointers and interfaces can lead back to themselves
+		// (such as `x = &x`).
+		if ptr, ok := getReferencePointer(v); ok {
+			if followed[ptr] {
+				links = append(links, "(cycle)")
+				break
+			}
+			if followed == nil {
+				followed = make(map[duplicates.TypedPointer]bool)
+			}
+			followed[ptr] = true
+		}
+		v = v.Elem()
+	}
+	return strings.Join(links, " → "), describesContents
+}
+
+// Explain what describes v itself, in the same order that
+// describeReflectedValue() tries things.
+func (this *describer) explainDescriber(v reflect.Value) (explanation string, isDefault bool) {
+	if !v.IsValid() {
+		return tokInvalid, false
+	}
+	t := v.Type()
+	var notes []string
+	if isNil(v) {
+		if match, found := this.findCustomDescriber(t); found && match.describer != nil {
+			notes = append(notes, "nil values aren't passed to describers")
+		}
+		return explain("nil", notes), false
+	}
+	if t == reflectValueType || t.Implements(reflectTypeType) {
+		return "reflection value or type", false
+	}
+	if t == timeType && (this.timeLayout != "" || this.timeDetails) {
+		return "time layout", false
+	}
+
+	if match, found := this.findCustomDescriber(t); found {
+		switch {
+		case this.reflectionOnly:
+			notes = append(notes, "custom describers are disabled by WithReflectionOnly()")
+		case match.describer == nil:
+			notes = append(notes, "the "+this.explainMatch(t, match)+" is disabled")
+		case !v.CanInterface() && this.hideUnexportedFromDescribers:
+			notes = append(notes, "the "+this.explainMatch(t, match)+" isn't used for values in unexported fields")
+		default:
+			return this.explainMatch(t, match), false
+		}
+	}
+	if t.Kind() != reflect.Ptr {
+		if match, found := this.findCustomDescriber(reflect.PtrTo(t)); found && match.describer != nil && match.level == describerForType {
+			notes = append(notes, fmt.Sprintf("a custom describer is set for %v, which doesn't match %v values without MatchPointersAndValues",
+				this.typeName(reflect.PtrTo(t)), this.typeName(t)))
+		}
+	}
+	if this.reflectionOnly {
+		return explain("default", notes), true
+	}
+
+	if protocol, _, ok := this.getDescribableProtocol(v); ok {
+		return explain(fmt.Sprintf("self-described as %v", protocol), notes), false
+	}
+	if receiver, ok := this.getReceiverImplementing(v, errorType); ok {
+		if this.errorStackFrames > 0 && hasErrorStack(receiver) {
+			return explain("error stack", notes), false
+		}
+		if this.errorChains {
+			return explain("error chain", notes), false
+		}
+	}
+	if _, ok := this.getReceiverImplementing(v, multiErrorType); ok {
+		return explain("multi-error", notes), false
+	}
+	if hasStringMethod(v) {
+		if !v.IsZero() || this.stringerFallback {
+			return explain("String() method", notes), false
+		}
+		notes = append(notes, "String() isn't called on zero values without WithStringerFallback()")
+	} else if _, ok := this.getReceiverImplementing(v, goStringerType); ok {
+		switch {
+		case !this.goStringers:
+			notes = append(notes, "GoString() isn't called without WithGoStringers()")
+		case !v.IsZero() || this.stringerFallback:
+			return explain("GoString() method", notes), false
+		default:
+			notes = append(notes, "GoString() isn't called on zero values without WithStringerFallback()")
+		}
+	}
+	return explain("default", notes), true
+}
+
+// Explain which custom describer type t matched.
+func (this *describer) explainMatch(t reflect.Type, match describerMatch) string {
+	var target string
+	switch match.level {
+	case describerForInterface:
+		target = "interface " + this.typeName(match.iface)
+	case describerForKind:
+		target = "kind " + t.Kind().String()
+	case describerForCounterpart:
+		counterpart, _ := adaptToCounterpart(t, nil)
+		target = this.typeName(counterpart) + ", matching pointers and values"
+	default:
+		target = this.typeName(t)
+	}
+	source := "from options"
+	if match.isGlobal {
+		source = "global"
+	}
+	return fmt.Sprintf("custom describer for %v (%v)", target, source)
+}
+
+func explain(explanation string, notes []string) string {
+	if len(notes) == 0 {
+		return explanation
+	}
+	return fmt.Sprintf("%v (%v)", explanation, strings.Join(notes, "; "))
+}
@@ -0,0 +1,121 @@
+package describe
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ExplainToken struct {
+	Value string
+}
+
+type ExplainPayload struct {
+	Size int
+}
+
+type ExplainLevel int
+
+func (this ExplainLevel) String() string {
+	return "level"
+}
+
+type ExplainRequest struct {
+	URL     *url.URL
+	Payload *ExplainPayload
+	Token   ExplainToken
+	Level   ExplainLevel
+	Ignored ExplainLevel
+	Sent    time.Time
+	Tags    []string
+	Next    *ExplainRequest
+}
+
+var explainTokenType = reflect.TypeOf(ExplainToken{})
+
+func TestExplainDescribers(t *testing.T) {
+	SetCustomDescriber(reflect.PtrTo(explainTokenType), func(v reflect.Value) string { return "token" })
+	defer SetCustomDescriber(reflect.PtrTo(explainTokenType), nil)
+
+	v := ExplainRequest{
+		URL:     &url.URL{Host: "example.com"},
+		Payload: &ExplainPayload{Size: 10},
+		Level:   1,
+		Sent:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		Tags:    []string{"a"},
+	}
+	expected := strings.Join([]string{
+		`(top): describe.ExplainRequest: default`,
+		`URL: *url.URL: String() method`,
+		`Payload: *describe.ExplainPayload: default → describe.ExplainPayload: custom describer for describe.ExplainPayload (from options)`,
+		`Token: describe.ExplainToken: default (a custom describer is set for *describe.ExplainToken, which doesn't match describe.ExplainToken values without MatchPointersAndValues)`,
+		`Token.Value: string: default`,
+		`Level: describe.ExplainLevel: String() method`,
+		`Ignored: describe.ExplainLevel: default (String() isn't called on zero values without WithStringerFallback())`,
+		`Sent: time.Time: String() method`,
+		`Tags: []string: custom describer for kind slice (global)`,
+		`Next: *describe.ExplainRequest: nil`,
+		``,
+	}, "\n")
+	SetCustomDescriberForKind(reflect.Slice, func(v reflect.Value) string { return "slice" })
+	defer SetCustomDescriberForKind(reflect.Slice, nil)
+	actual := ExplainDescribers(v, WithCustomDescriber(reflect.TypeOf(ExplainPayload{}), func(v reflect.Value) string { return "payload" }))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestExplainDescribersContents(t *testing.T) {
+	v := ExplainToken{Value: "x"}
+	expected := "(top): describe.ExplainToken: default\nValue: string: default\n"
+	actual := ExplainDescribers(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "(top): describe.ExplainLevel: custom describer for interface fmt.Stringer (from options)\n"
+	actual = ExplainDescribers(ExplainLevel(1), WithCustomDescriberForInterface(reflect.TypeOf((*fmt.Stringer)(nil)), func(v reflect.Value) string { return "" }))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "(top): describe.ExplainToken: custom describer for *describe.ExplainToken, matching pointers and values (from options)\n"
+	actual = ExplainDescribers(v, WithCustomDescriberMatching(reflect.PtrTo(explainTokenType), func(v reflect.Value) string { return "" }, MatchPointersAndValues))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "(top): describe.GoStringerPoint: default (GoString() isn't called without WithGoStringers())\nX: int: default\nY: int: default\n"
+	actual = ExplainDescribers(GoStringerPoint{X: 1})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "(top): describe.GoStringerPoint: GoString() method\n"
+	actual = ExplainDescribers(GoStringerPoint{X: 1}, WithGoStringers(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "(top): describe.ExplainToken: default (custom describers are disabled by WithReflectionOnly())\nValue: string: default\n"
+	actual = ExplainDescribers(v, WithReflectionOnly(true), WithCustomDescriber(explainTokenType, func(v reflect.Value) string { return "" }))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestExplainDescribersStringerKeys(t *testing.T) {
+	countedStringerCalls = 0
+	v := map[*CountedStringer]int{&CountedStringer{"a"}: 1, &CountedStringer{"b"}: 2}
+	actual := ExplainDescribers(v)
+	expected := `[*describe.CountedStringer<Name="a">]: int: default`
+	if !strings.Contains(actual, expected) {
+		t.Errorf("Expected %v to contain %v", actual, expected)
+	}
+	if countedStringerCalls != 0 {
+		t.Errorf("Expected String() to not be called but was called %v times", countedStringerCalls)
+	}
+}
@@ -0,0 +1,221 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// The frame that all elements of an array or slice (or values of a map) share.
+const flameGraphElementsFrame = "[*]"
+
+// Stacks are cut off at this depth (unless WithMaxDepth() says otherwise),
+// with anything deeper counted towards the deepest frame.
+const flameGraphMaxDepth = 100
+
+// Describes where the memory of an object lives in folded stack format, for
+// visualizing large structures in flame graph tools such as speedscope or
+// flamegraph.pl:
+//
+//	main.Cache;Entries;[*];Body 1048576
+//	main.Cache;Entries;[*];Key 4096
+//	main.Cache;Name 21
+//
+// Each line is a path from the top-level value (named after its type) through
+// struct fields, ending with the number of bytes at that path (excluding what's
+// under deeper paths). All elements of an array or slice, and all values of a
+// map share the frame `[*]`, so that the output follows the structure of the
+// types rather than the number of elements. Elements that are numbers, bools
+// or strings are counted towards their container rather than getting frames.
+//
+// Sizes include pointed-to data, string contents and slice backing arrays.
+// Data that's reachable via multiple paths is only counted the first time.
+// Channels, functions and unsafe pointers count only as their own size.
+func DescribeFlameGraph(v interface{}, opts ...Option) (description string) {
+	context := describer{}
+	context.applyOptions(opts)
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.describeFlameGraph(rv)
+}
+
+type flameGraph struct {
+	describer *describer
+	weights   map[string]uint64
+	seen      map[duplicates.TypedPointer]bool
+	maxDepth  int
+}
+
+func (this *describer) describeFlameGraph(rv reflect.Value) (description string) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				description = notifyRecoveredLibraryBug(e)
+			}
+		}
+	}()
+
+	graph := flameGraph{
+		describer: this,
+		weights:   make(map[string]uint64),
+		seen:      make(map[duplicates.TypedPointer]bool),
+		maxDepth:  flameGraphMaxDepth,
+	}
+	if this.limitDepth {
+		graph.maxDepth = this.maxDepth
+	}
+	root := tokInvalid
+	if rv.IsValid() {
+		root = this.typeName(rv.Type())
+	}
+	graph.visit(escapeFlameGraphFrame(root), 0, rv)
+
+	stacks := make([]string, 0, len(graph.weights))
+	for stack, weight := range graph.weights {
+		if weight > 0 {
+			stacks = append(stacks, stack)
+		}
+	}
+	if len(stacks) == 0 {
+		// Always describe something, even if it's empty.
+		stacks = append(stacks, escapeFlameGraphFrame(root))
+	}
+	sort.Strings(stacks)
+	builder := strings.Builder{}
+	for i, stack := range stacks {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(fmt.Sprintf("%v %v", stack, graph.weights[stack]))
+	}
+	return builder.String()
+}
+
+// Frame names can't contain the separator or line breaks.
+func escapeFlameGraphFrame(frame string) string {
+	return strings.NewReplacer(";", ":", "\n", " ", "\r", " ").Replace(frame)
+}
+
+func (this *flameGraph) getChildStack(stack string, depth int, frame string) string {
+	if depth >= this.maxDepth {
+		return stack
+	}
+	return stack + ";" + escapeFlameGraphFrame(frame)
+}
+
+// Returns true if v was already counted (and marks it as counted).
+func (this *flameGraph) wasCounted(v reflect.Value) bool {
+	ptr := duplicates.TypedPointerOfRV(v)
+	if this.seen[ptr] {
+		return true
+	}
+	this.seen[ptr] = true
+	return false
+}
+
+// Returns true if v's elements are counted towards v rather than getting a
+// frame.
+func isFlameGraphScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		return true
+	}
+	return false
+}
+
+// Get the size of a scalar, including string contents.
+func getFlameGraphScalarSize(v reflect.Value) uint64 {
+	size := uint64(v.Type().Size())
+	if v.Kind() == reflect.String {
+		size += uint64(v.Len())
+	}
+	return size
+}
+
+// Count v (at stack) and everything it refers to.
+func (this *flameGraph) visit(stack string, depth int, v reflect.Value) {
+	if !v.IsValid() {
+		return
+	}
+	// Pointers can lead to data that was already counted as part of a struct
+	// or slice (or vice versa).
+	if v.CanAddr() && this.wasCounted(v.Addr()) {
+		return
+	}
+	this.weights[stack] += uint64(v.Type().Size())
+	this.visitReferenced(stack, depth, v)
+}
+
+// Count what v refers to (but not v itself). Struct and array contents are
+// counted towards their fields and elements, with only padding left over.
+func (this *flameGraph) visitReferenced(stack string, depth int, v reflect.Value) {
+	t := v.Type()
+	if t == reflectValueType || t.Implements(reflectTypeType) {
+		// Don't wander into reflection internals.
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		this.weights[stack] += uint64(v.Len())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		this.visit(stack, depth, v.Elem())
+	case reflect.Struct:
+		fieldsSize := uint64(0)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			fieldsSize += uint64(field.Type().Size())
+			this.visit(this.getChildStack(stack, depth, t.Field(i).Name), depth+1, field)
+		}
+		// Padding
+		this.weights[stack] -= fieldsSize
+	case reflect.Array:
+		this.weights[stack] -= uint64(t.Size())
+		this.visitElements(stack, depth, v)
+	case reflect.Slice:
+		if v.Len() == 0 || this.wasCounted(v) {
+			return
+		}
+		// Only the slice header is part of the parent.
+		this.visitElements(stack, depth, v)
+	case reflect.Map:
+		if v.Len() == 0 || this.wasCounted(v) {
+			return
+		}
+		valuesStack := this.getChildStack(stack, depth, flameGraphElementsFrame)
+		isValueScalar := isFlameGraphScalar(t.Elem())
+		iter := mapRange(v)
+		for iter.Next() {
+			this.visit(stack, depth, iter.Key())
+			if isValueScalar {
+				this.weights[stack] += getFlameGraphScalarSize(iter.Value())
+			} else {
+				this.visit(valuesStack, depth+1, iter.Value())
+			}
+		}
+	}
+}
+
+func (this *flameGraph) visitElements(stack string, depth int, v reflect.Value) {
+	if isFlameGraphScalar(v.Type().Elem()) {
+		for i := 0; i < v.Len(); i++ {
+			this.weights[stack] += getFlameGraphScalarSize(v.Index(i))
+		}
+		return
+	}
+	elementsStack := this.getChildStack(stack, depth, flameGraphElementsFrame)
+	for i := 0; i < v.Len(); i++ {
+		this.visit(elementsStack, depth+1, v.Index(i))
+	}
+}
@@ -0,0 +1,67 @@
+package describe
+
+import (
+	"testing"
+)
+
+type FlameGraphEntry struct {
+	Key  string
+	Body []byte
+	Next *FlameGraphEntry
+}
+
+type FlameGraphCache struct {
+	Name    string
+	Entries []FlameGraphEntry
+	Index   map[string]*FlameGraphEntry
+}
+
+func TestDescribeFlameGraph(t *testing.T) {
+	v := &FlameGraphCache{
+		Name: "cache",
+		Entries: []FlameGraphEntry{
+			{Key: "a", Body: make([]byte, 100)},
+			{Key: "bb", Body: make([]byte, 1000)},
+		},
+	}
+	v.Entries[0].Next = &v.Entries[1]
+	v.Index = map[string]*FlameGraphEntry{"a": &v.Entries[0]}
+
+	// Pointers: 8 bytes, strings: 16 + length, slices: 24 + elements.
+	// Entries[1] is counted via Entries[0].Next, since that's reached first.
+	expected := "*describe.FlameGraphCache 8\n" +
+		"*describe.FlameGraphCache;Entries 24\n" +
+		"*describe.FlameGraphCache;Entries;[*];Body 124\n" +
+		"*describe.FlameGraphCache;Entries;[*];Key 17\n" +
+		"*describe.FlameGraphCache;Entries;[*];Next 8\n" +
+		"*describe.FlameGraphCache;Entries;[*];Next;Body 1024\n" +
+		"*describe.FlameGraphCache;Entries;[*];Next;Key 18\n" +
+		"*describe.FlameGraphCache;Entries;[*];Next;Next 8\n" +
+		"*describe.FlameGraphCache;Index 25\n" +
+		"*describe.FlameGraphCache;Index;[*] 8\n" +
+		"*describe.FlameGraphCache;Name 21"
+	actual := DescribeFlameGraph(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeFlameGraphCycle(t *testing.T) {
+	v := &FlameGraphEntry{Key: "a"}
+	v.Next = v
+
+	expected := "*describe.FlameGraphEntry 8\n" +
+		"*describe.FlameGraphEntry;Body 24\n" +
+		"*describe.FlameGraphEntry;Key 17\n" +
+		"*describe.FlameGraphEntry;Next 8"
+	actual := DescribeFlameGraph(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "invalid 0"
+	actual = DescribeFlameGraph(nil)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
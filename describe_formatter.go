@@ -0,0 +1,55 @@
+package describe
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A value that's formatted using its description. See V().
+type Formattable struct {
+	value interface{}
+	opts  []Option
+}
+
+// Wraps v so that fmt (and loggers that only accept format arguments) format
+// it using its description:
+//
+//	fmt.Printf("%v\n", describe.V(obj))   // Single line
+//	fmt.Printf("%4v\n", describe.V(obj))  // Multiline, indented by 4 spaces
+//	fmt.Printf("%.2v\n", describe.V(obj)) // Single line, max depth of 2
+//	log.Printf("%q", describe.V(obj))     // Single line, quoted
+//
+// The width is used as the indent (see WithIndent()), and the precision as the
+// maximum depth (see WithMaxDepth()), overriding any given in opts. The verbs
+// %v and %s write the description, and %q writes it quoted.
+func V(v interface{}, opts ...Option) Formattable {
+	return Formattable{
+		value: v,
+		opts:  opts,
+	}
+}
+
+func (this Formattable) String() string {
+	return DescribeOpts(this.value, this.opts...)
+}
+
+func (this Formattable) Format(f fmt.State, verb rune) {
+	opts := this.opts
+	if width, ok := f.Width(); ok {
+		opts = append(opts[:len(opts):len(opts)], WithIndent(width))
+	}
+	if precision, ok := f.Precision(); ok {
+		opts = append(opts[:len(opts):len(opts)], WithMaxDepth(precision))
+	}
+	description := DescribeOpts(this.value, opts...)
+
+	switch verb {
+	case 'v', 's':
+		fmt.Fprint(f, description)
+	case 'q':
+		fmt.Fprint(f, strconv.Quote(description))
+	default:
+		// Same as fmt's response to an unsupported verb.
+		fmt.Fprintf(f, "%%!%c(describe.Formattable=%v)", verb, description)
+	}
+}
@@ -0,0 +1,38 @@
+package describe
+
+import (
+	"fmt"
+	"testing"
+)
+
+type FormatterNode struct {
+	Name string
+	Next *FormatterNode
+}
+
+func TestFormatter(t *testing.T) {
+	v := &FormatterNode{Name: "a", Next: &FormatterNode{Name: "b"}}
+
+	assertFormat := func(expected string, format string) {
+		actual := fmt.Sprintf(format, V(v))
+		if actual != expected {
+			t.Errorf("Expected %v but got %v", expected, actual)
+		}
+	}
+
+	assertFormat(`*describe.FormatterNode<Name="a" Next=*describe.FormatterNode<Name="b" Next=nil>>`, "%v")
+	assertFormat(`*describe.FormatterNode<Name="a" Next=*describe.FormatterNode<Name="b" Next=nil>>`, "%s")
+	assertFormat(`*describe.FormatterNode<Name="a" Next=*describe.FormatterNode<…>>`, "%.1v")
+	assertFormat(`*describe.FormatterNode<
+  Name = "a"
+  Next = *describe.FormatterNode<…>
+>`, "%2.1v")
+	assertFormat(`"*describe.FormatterNode<Name=\"a\" Next=*describe.FormatterNode<…>>"`, "%.1q")
+	assertFormat(`%!d(describe.Formattable=*describe.FormatterNode<…>)`, "%.0d")
+
+	expected := `*describe.FormatterNode<Name="a" Next=*describe.FormatterNode<…>>`
+	actual := V(v, WithMaxDepth(1)).String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -1,17 +1,32 @@
+//go:build go1.10
 // +build go1.10
 
 package describe
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/kstenerud/go-duplicates"
 )
 
 type describer struct {
-	indentStep     int
-	currentIndent  int
-	stringBuilder  strings.Builder
-	referenceNames map[duplicates.TypedPointer]int
-	seenReferences map[duplicates.TypedPointer]bool
+	options
+	currentIndent         int
+	stringBuilder         strings.Builder
+	referenceNames        map[duplicates.TypedPointer]int
+	lastReferenceName     int
+	seenReferences        map[duplicates.TypedPointer]bool
+	path                  []pathSegment
+	depth                 int
+	didElideDepth         bool
+	outputLimit           int
+	rootOccurrences       []reflect.Value
+	typeDepths            map[reflect.Type]int
+	memoizedDescriptions  map[duplicates.TypedPointer]string
+	fixedOutput           *fixedBuffer
+	streamOutput          *streamOutput
+	lastNewlineEnd        int
+	pendingFieldDescriber ContextDescriber
+	cycleFinder           *cycleFinder
 }
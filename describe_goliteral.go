@@ -0,0 +1,499 @@
+package describe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Describes an object as a Go composite literal (like the `%#v` verb), for
+// pasting described values back into test code as fixtures:
+//
+//	&describe.Node{Name: "a", Tags: []string{"x"}, Next: nil /* $1 */}
+//
+// Unlike `%#v`, cyclic data never causes a stack overflow. The value that a
+// cycle leads back to is marked with a comment (`/* 1~ */`), and the field or
+// element that closes the cycle is written as nil with a comment referencing
+// it (`nil /* $1 */`). Data that's shared without forming a cycle is written
+// out in full each time it occurs, so heavily shared data should be described
+// using WithMaxOutputBytes(). Output that exceeds the limit is truncated, and
+// is no longer valid Go.
+//
+// The result is valid Go in most cases:
+//
+//   - Map entries are sorted by key.
+//   - Pointers to scalars are written as `&[]int{5}[0]`.
+//   - time.Time values are written as `time.Date(...)` calls.
+//   - reflect.Value and reflect.Type values are written as `reflect.ValueOf()`
+//     and `reflect.TypeOf()` calls.
+//   - Non-nil channels are written as `make()` calls.
+//
+// Non-nil functions, unsafe pointers and unexported fields of other packages'
+// types can't be expressed as literals, and will need fixing up by hand.
+// String() methods and custom describers are not used. Redacted fields and map
+// entries (see WithRedaction()) are written as zero values, followed by a
+// comment.
+//
+// With WithIndent(), composite literals are written one element per line, as
+// gofmt would format them.
+func DescribeGoLiteral(v interface{}, opts ...Option) (description string) {
+	context := describer{}
+	context.applyOptions(opts)
+	context.sortMapKeys = true
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.describeGoLiteral(rv)
+}
+
+type goLiteralWriter struct {
+	describer *describer
+	// Values that a cycle leads back to.
+	cycleTargets map[duplicates.TypedPointer]bool
+	// Values currently being written, which are cycles if encountered again.
+	ancestors map[duplicates.TypedPointer]bool
+}
+
+func (this *describer) describeGoLiteral(rv reflect.Value) (description string) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				description = fmt.Sprintf("nil /* %v */", notifyRecoveredLibraryBug(e))
+			}
+		}
+	}()
+
+	this.referenceNames = make(map[duplicates.TypedPointer]int)
+	writer := goLiteralWriter{
+		describer:    this,
+		cycleTargets: make(map[duplicates.TypedPointer]bool),
+		ancestors:    make(map[duplicates.TypedPointer]bool),
+	}
+	writer.findCycles(rv, map[duplicates.TypedPointer]bool{}, map[duplicates.TypedPointer]bool{})
+	if this.maxOutputBytes > 0 {
+		// Writing stops just past the limit, so that we know it was exceeded.
+		this.outputLimit = this.maxOutputBytes + 1
+	}
+	writer.writeValue(rv, false, 0)
+	description = this.stringBuilder.String()
+	if this.maxOutputBytes > 0 && len(description) > this.maxOutputBytes {
+		description = truncateDescription(description, this.maxOutputBytes)
+	}
+	return description
+}
+
+// Get the identity of the data that v refers to, if any. Only data that's
+// referred to by pointers, maps and slices can form cycles.
+func getGoLiteralIdentity(v reflect.Value) (ptr duplicates.TypedPointer, ok bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		return duplicates.TypedPointerOfRV(v), true
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return
+		}
+		return duplicates.TypedPointerOfRV(v), true
+	}
+	return
+}
+
+// Find the data that cycles lead back to, using a depth-first search where
+// visiting marks data that's still being searched, and visited marks data
+// that's been fully searched (so that shared data is only searched once).
+func (this *goLiteralWriter) findCycles(v reflect.Value, visiting, visited map[duplicates.TypedPointer]bool) {
+	if !v.IsValid() {
+		return
+	}
+	if ptr, ok := getGoLiteralIdentity(v); ok {
+		if visiting[ptr] {
+			this.cycleTargets[ptr] = true
+			return
+		}
+		if visited[ptr] {
+			return
+		}
+		visiting[ptr] = true
+		defer func() {
+			delete(visiting, ptr)
+			visited[ptr] = true
+		}()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			this.findCycles(v.Elem(), visiting, visited)
+		}
+	case reflect.Struct:
+		if v.Type() == reflectValueType {
+			if held, ok := this.describer.getInterfaceAsReflectValue(v); ok {
+				this.findCycles(held, visiting, visited)
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			this.findCycles(v.Field(i), visiting, visited)
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			this.findCycles(v.Index(i), visiting, visited)
+		}
+	case reflect.Map:
+		iter := mapRange(v)
+		for iter.Next() {
+			this.findCycles(iter.Key(), visiting, visited)
+			this.findCycles(iter.Value(), visiting, visited)
+		}
+	}
+}
+
+func getGoTypeName(t reflect.Type) string {
+	return t.String()
+}
+
+// Write v as a Go expression. If isTyped is true, the type is implied by the
+// context (such as a struct field or a slice element that isn't an
+// interface), so constants and nil don't need converting.
+func (this *goLiteralWriter) writeValue(v reflect.Value, isTyped bool, level int) {
+	if this.describer.isOutputFull() {
+		return
+	}
+	if !v.IsValid() {
+		this.describer.writeString("nil")
+		return
+	}
+	if v.Kind() != reflect.Interface && !isNil(v) && v.Type().Implements(reflectTypeType) {
+		this.writeType(v)
+		return
+	}
+
+	if ptr, ok := getGoLiteralIdentity(v); ok && this.cycleTargets[ptr] {
+		if this.ancestors[ptr] {
+			this.writeNil(v.Type(), isTyped)
+			this.describer.writeFmt(" /* %v%v */", tokReferencePrefix, this.describer.referenceNames[ptr])
+			return
+		}
+		// Shared data is written out again each time, so it gets a new name
+		// each time.
+		this.describer.writeFmt("/* %v%v */ ", this.describer.assignReferenceName(ptr), tokReferenceSeparator)
+		this.ancestors[ptr] = true
+		defer delete(this.ancestors, ptr)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			this.writeNil(v.Type(), isTyped)
+			return
+		}
+		this.writeValue(v.Elem(), false, level)
+	case reflect.Ptr:
+		this.writePointer(v, isTyped, level)
+	case reflect.Bool:
+		this.writeConstant(v.Type(), strconv.FormatBool(v.Bool()), isTyped || v.Type() == boolType)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		this.writeConstant(v.Type(), strconv.FormatInt(v.Int(), 10), isTyped || v.Type() == intType)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		this.writeConstant(v.Type(), strconv.FormatUint(v.Uint(), 10), isTyped)
+	case reflect.Uintptr:
+		this.writeConstant(v.Type(), fmt.Sprintf("0x%x", v.Uint()), isTyped)
+	case reflect.Float32, reflect.Float64:
+		this.writeFloat(v.Type(), v.Float(), isTyped)
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		bits := v.Type().Bits() / 2
+		this.writeConstant(v.Type(), fmt.Sprintf("complex(%v, %v)",
+			getGoFloat(real(c), bits), getGoFloat(imag(c), bits)), isTyped || v.Type() == complex128Type)
+	case reflect.String:
+		this.writeConstant(v.Type(), strconv.Quote(v.String()), isTyped || v.Type() == stringType)
+	case reflect.Struct:
+		this.writeStruct(v, level)
+	case reflect.Array:
+		this.writeSequence(v, level)
+	case reflect.Slice:
+		if v.IsNil() {
+			this.writeNil(v.Type(), isTyped)
+			return
+		}
+		this.writeSequence(v, level)
+	case reflect.Map:
+		if v.IsNil() {
+			this.writeNil(v.Type(), isTyped)
+			return
+		}
+		this.writeMap(v, level)
+	case reflect.Chan:
+		if v.IsNil() {
+			this.writeNil(v.Type(), isTyped)
+			return
+		}
+		this.describer.writeFmt("make(%v, %v)", getGoTypeName(v.Type()), v.Cap())
+	case reflect.Func:
+		this.writeNil(v.Type(), isTyped)
+		if !v.IsNil() {
+			this.describer.writeString(" /* non-nil func */")
+		}
+	case reflect.UnsafePointer:
+		if v.IsNil() {
+			this.writeNil(v.Type(), isTyped)
+			return
+		}
+		this.describer.writeFmt("unsafe.Pointer(uintptr(0x%x))", v.Pointer())
+	default:
+		this.describer.writeFmt("nil /* %v */", notifyLibraryBug("unhandled type %v (kind %v)", v.Type(), v.Kind()))
+	}
+}
+
+var boolType = reflect.TypeOf(false)
+var intType = reflect.TypeOf(int(0))
+var stringType = reflect.TypeOf("")
+var complex128Type = reflect.TypeOf(complex128(0))
+var float64Type = reflect.TypeOf(float64(0))
+
+// Write a constant, converting it to its type if the type isn't implied.
+func (this *goLiteralWriter) writeConstant(t reflect.Type, constant string, isTyped bool) {
+	if isTyped {
+		this.describer.writeString(constant)
+		return
+	}
+	this.describer.writeString(getGoConversion(t, constant))
+}
+
+func (this *goLiteralWriter) writeNil(t reflect.Type, isTyped bool) {
+	if isTyped || t.Kind() == reflect.Interface {
+		this.describer.writeString("nil")
+		return
+	}
+	this.describer.writeString(getGoConversion(t, "nil"))
+}
+
+// Get a conversion of expression to type t, parenthesizing the type if it
+// would otherwise be ambiguous (as in `*int(nil)` or `chan int(nil)`).
+func getGoConversion(t reflect.Type, expression string) string {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if t.Name() == "" {
+			return fmt.Sprintf("(%v)(%v)", getGoTypeName(t), expression)
+		}
+	}
+	return fmt.Sprintf("%v(%v)", getGoTypeName(t), expression)
+}
+
+func getGoFloat(value float64, bits int) string {
+	switch {
+	case math.IsNaN(value):
+		return "math.NaN()"
+	case math.IsInf(value, 1):
+		return "math.Inf(1)"
+	case math.IsInf(value, -1):
+		return "math.Inf(-1)"
+	}
+	return strconv.FormatFloat(value, 'g', -1, bits)
+}
+
+func (this *goLiteralWriter) writeFloat(t reflect.Type, value float64, isTyped bool) {
+	float := getGoFloat(value, t.Bits())
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		// math.NaN() and math.Inf() return float64 values, not constants.
+		isTyped = isTyped && t == float64Type
+	} else if t == float64Type && strings.ContainsAny(float, ".e") {
+		// Untyped float constants default to float64.
+		isTyped = true
+	}
+	this.writeConstant(t, float, isTyped)
+}
+
+func (this *goLiteralWriter) writePointer(v reflect.Value, isTyped bool, level int) {
+	if v.IsNil() {
+		this.writeNil(v.Type(), isTyped)
+		return
+	}
+	elem := v.Elem()
+	switch elem.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		if !isGoLiteralCall(elem) {
+			this.describer.writeString("&")
+			this.writeValue(elem, true, level)
+			return
+		}
+	}
+	// Go can't take the address of anything other than a composite literal,
+	// but it can take the address of a slice element.
+	this.describer.writeFmt("&[]%v{", getGoTypeName(elem.Type()))
+	this.writeValue(elem, true, level)
+	this.describer.writeString("}[0]")
+}
+
+// Returns true if v is written as a function call rather than a literal.
+func isGoLiteralCall(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.IsNil()
+	case reflect.Struct:
+		return v.Type() == timeType || v.Type() == reflectValueType
+	}
+	return false
+}
+
+func (this *goLiteralWriter) writeStruct(v reflect.Value, level int) {
+	switch v.Type() {
+	case timeType:
+		if value, ok := this.getInterface(v); ok {
+			this.writeTime(value.(time.Time))
+			return
+		}
+	case reflectValueType:
+		if held, ok := this.describer.getInterfaceAsReflectValue(v); ok {
+			if !held.IsValid() {
+				this.describer.writeString("reflect.Value{}")
+				return
+			}
+			this.describer.writeString("reflect.ValueOf(")
+			this.writeValue(held, false, level)
+			this.describer.writeString(")")
+			return
+		}
+	}
+
+	indices := getVisibleFieldIndices(v)
+	this.writeComposite(v.Type(), len(indices), 0, level, func(i int) {
+		field := v.Type().Field(indices[i])
+		this.describer.writeString(field.Name)
+		this.describer.writeString(": ")
+		if this.describer.isRedactedField(v.Type(), indices[i]) {
+			// The mask itself is only a valid literal for string fields.
+			this.writeValue(reflect.Zero(field.Type), true, level+1)
+			this.describer.writeFmt(" /* %v */", tokRedacted)
+			return
+		}
+		this.writeValue(v.Field(indices[i]), true, level+1)
+	})
+}
+
+// reflect.Type values are written as expressions, since their dynamic types
+// are unexported.
+func (this *goLiteralWriter) writeType(v reflect.Value) {
+	if t, ok := this.describer.getInterfaceAsReflectType(v); ok && t != nil {
+		this.describer.writeFmt("reflect.TypeOf((*%v)(nil)).Elem()", getGoTypeName(t))
+		return
+	}
+	this.describer.writeString("nil")
+}
+
+func (this *goLiteralWriter) getInterface(v reflect.Value) (value interface{}, ok bool) {
+	if v.CanInterface() {
+		return v.Interface(), true
+	}
+	if this.describer.canUseUnsafe() {
+		return exposeInterface(v), true
+	}
+	return nil, false
+}
+
+func (this *goLiteralWriter) writeTime(value time.Time) {
+	location := ""
+	switch value.Location() {
+	case time.UTC:
+		location = "time.UTC"
+	case time.Local:
+		location = "time.Local"
+	default:
+		name, offset := value.Zone()
+		location = fmt.Sprintf("time.FixedZone(%v, %v)", strconv.Quote(name), offset)
+	}
+	this.describer.writeFmt("time.Date(%v, %v, %v, %v, %v, %v, %v, %v)",
+		value.Year(), int(value.Month()), value.Day(),
+		value.Hour(), value.Minute(), value.Second(), value.Nanosecond(), location)
+}
+
+func (this *goLiteralWriter) writeSequence(v reflect.Value, level int) {
+	count := this.describer.getElementCountToDescribe(v.Len())
+	isElemTyped := v.Type().Elem().Kind() != reflect.Interface
+	this.writeComposite(v.Type(), count, v.Len()-count, level, func(i int) {
+		this.writeValue(v.Index(i), isElemTyped, level+1)
+	})
+}
+
+func (this *goLiteralWriter) writeMap(v reflect.Value, level int) {
+	iter, omittedCount := this.describer.iterateMap(v)
+	var keys, values []reflect.Value
+	for iter.Next() {
+		keys = append(keys, iter.Key())
+		values = append(values, iter.Value())
+	}
+	isKeyTyped := v.Type().Key().Kind() != reflect.Interface
+	isElemTyped := v.Type().Elem().Kind() != reflect.Interface
+	this.writeComposite(v.Type(), len(keys), omittedCount, level, func(i int) {
+		this.writeValue(keys[i], isKeyTyped, level+1)
+		this.describer.writeString(": ")
+		if this.describer.isRedactedKey(keys[i]) {
+			this.writeValue(reflect.Zero(v.Type().Elem()), isElemTyped, level+1)
+			this.describer.writeFmt(" /* %v */", tokRedacted)
+			return
+		}
+		this.writeValue(values[i], isElemTyped, level+1)
+	})
+}
+
+// Write a composite literal of type t with count items (followed by a comment
+// if omittedCount items were left out due to WithMaxElements()).
+func (this *goLiteralWriter) writeComposite(t reflect.Type, count int, omittedCount int, level int, writeItem func(i int)) {
+	this.describer.writeString(getGoTypeName(t))
+	this.describer.writeString("{")
+	omitted := ""
+	if omittedCount > 0 {
+		items := tokMoreElements
+		if t.Kind() == reflect.Map {
+			items = tokMoreEntries
+		}
+		omitted = fmt.Sprintf("/* %v(+%v more %v) */", tokElided, omittedCount, items)
+	}
+
+	if this.describer.indentStep <= 0 {
+		for i := 0; i < count && !this.describer.isOutputFull(); i++ {
+			if i > 0 {
+				this.describer.writeString(", ")
+			}
+			writeItem(i)
+		}
+		if omitted != "" {
+			if count > 0 {
+				this.describer.writeString(" ")
+			}
+			this.describer.writeString(omitted)
+		}
+		this.describer.writeString("}")
+		return
+	}
+
+	if count == 0 && omitted == "" {
+		this.describer.writeString("}")
+		return
+	}
+	for i := 0; i < count && !this.describer.isOutputFull(); i++ {
+		this.writeLineIndent(level + 1)
+		writeItem(i)
+		this.describer.writeString(",")
+	}
+	if omitted != "" {
+		this.writeLineIndent(level + 1)
+		this.describer.writeString(omitted)
+	}
+	this.writeLineIndent(level)
+	this.describer.writeString("}")
+}
+
+func (this *goLiteralWriter) writeLineIndent(level int) {
+	this.describer.writeString("\n")
+	this.describer.writeString(strings.Repeat(" ", level*this.describer.indentStep))
+}
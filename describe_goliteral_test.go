@@ -0,0 +1,143 @@
+package describe
+
+import (
+	"go/parser"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type GoLiteralNode struct {
+	Name   string
+	Next   *GoLiteralNode
+	Tags   []string
+	Scores map[string]float64
+	Count  *int
+	Any    interface{}
+	when   time.Time
+}
+
+func TestDescribeGoLiteral(t *testing.T) {
+	count := 5
+	v := &GoLiteralNode{
+		Name:   "a",
+		Tags:   []string{"x"},
+		Scores: map[string]float64{"b": 2, "a": 1.5},
+		Count:  &count,
+		Any:    uint8(3),
+		when:   time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+	v.Next = v
+
+	expected := `/* 1~ */ &describe.GoLiteralNode{Name: "a", Next: nil /* $1 */, Tags: []string{"x"}, ` +
+		`Scores: map[string]float64{"a": 1.5, "b": 2}, Count: &[]int{5}[0], Any: uint8(3), ` +
+		`when: time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)}`
+	actual := DescribeGoLiteral(v)
+	if canExposeInterface() {
+		if actual != expected {
+			t.Errorf("Expected %v but got %v", expected, actual)
+		}
+	} else {
+		// The unexported time.Time field can't be examined on safe builds.
+		expected = expected[:strings.Index(expected, "when: ")]
+		if !strings.HasPrefix(actual, expected) {
+			t.Errorf("Expected %v... but got %v", expected, actual)
+		}
+	}
+	if _, err := parser.ParseExpr(actual); err != nil {
+		t.Errorf("Invalid Go expression %v: %v", actual, err)
+	}
+}
+
+func TestDescribeGoLiteralScalars(t *testing.T) {
+	assertGoLiteral := func(expected string, v interface{}) {
+		actual := DescribeGoLiteral(v)
+		if actual != expected {
+			t.Errorf("Expected %v but got %v", expected, actual)
+		}
+		if _, err := parser.ParseExpr(actual); err != nil {
+			t.Errorf("Invalid Go expression %v: %v", actual, err)
+		}
+	}
+
+	assertGoLiteral(`nil`, nil)
+	assertGoLiteral(`1`, 1)
+	assertGoLiteral(`int8(-1)`, int8(-1))
+	assertGoLiteral(`1.5`, 1.5)
+	assertGoLiteral(`float64(2)`, 2.0)
+	assertGoLiteral(`float32(math.NaN())`, float32(math.NaN()))
+	assertGoLiteral(`complex(1, 2)`, complex(1, 2))
+	assertGoLiteral(`"a\n"`, "a\n")
+	assertGoLiteral(`time.Duration(1000000000)`, time.Second)
+	assertGoLiteral(`(*int)(nil)`, (*int)(nil))
+	assertGoLiteral(`[]interface {}{1, "a", nil}`, []interface{}{1, "a", nil})
+	assertGoLiteral(`map[string][]int(nil)`, map[string][]int(nil))
+	assertGoLiteral(`make(chan int, 3)`, make(chan int, 3))
+	assertGoLiteral(`reflect.TypeOf((*int)(nil)).Elem()`, reflect.TypeOf(1))
+	assertGoLiteral(`reflect.ValueOf("a")`, reflect.ValueOf(reflect.ValueOf("a")))
+}
+
+func TestDescribeGoLiteralCycle(t *testing.T) {
+	v := []interface{}{nil, 1}
+	v[0] = v
+
+	expected := `/* 1~ */ []interface {}{[]interface {}(nil) /* $1 */, 1}`
+	actual := DescribeGoLiteral(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeGoLiteralShared(t *testing.T) {
+	shared := &[]string{"s"}
+	v := []*[]string{shared, shared}
+
+	expected := `[]*[]string{&[]string{"s"}, &[]string{"s"}}`
+	actual := DescribeGoLiteral(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Each level doubles the output.
+	var dag interface{} = 1
+	for i := 0; i < 100; i++ {
+		dag = []interface{}{dag, dag}
+	}
+	expected = `[]interface {}{[]interface {}{[]interface {}{…`
+	actual = DescribeGoLiteral(dag, WithMaxOutputBytes(45))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeGoLiteralIndent(t *testing.T) {
+	v := map[string][]int{"a": {1, 2}, "b": {}}
+
+	expected := "map[string][]int{\n  \"a\": []int{\n    1,\n    2,\n  },\n  \"b\": []int{},\n}"
+	actual := DescribeGoLiteral(v, WithIndent(2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `[]int{1 /* …(+2 more elements) */}`
+	actual = DescribeGoLiteral([]int{1, 2, 3}, WithMaxElements(1))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeGoLiteralRedaction(t *testing.T) {
+	v := RedactedCredentials{User: "alice", Password: "hunter2", Pin: 1234, Headers: map[string][]string{"Authorization": {"Bearer abc"}}}
+
+	expected := `describe.RedactedCredentials{User: "alice", Password: "" /* *** */, APIKey: "" /* *** */, ` +
+		`AccessToken: nil /* *** */, Pin: 0 /* *** */, Headers: map[string][]string{"Authorization": nil /* *** */}}`
+	actual := DescribeGoLiteral(v, WithRedaction("password", "apikey", "token", "code", "authorization"))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	if _, err := parser.ParseExpr(actual); err != nil {
+		t.Errorf("Invalid Go expression %v: %v", actual, err)
+	}
+}
@@ -0,0 +1,44 @@
+package describe
+
+import (
+	"reflect"
+)
+
+// Describe v as a hex dump with offsets if it's a long byte array or slice and
+// hex dumps are enabled (see WithHexDump()), returning false if it isn't.
+func (this *describer) tryDescribeHexDump(v reflect.Value) bool {
+	if this.hexDumpBytesPerLine <= 0 || this.indentStep <= 0 || this.sortSlices {
+		return false
+	}
+	if v.Type().Elem().Kind() != reflect.Uint8 || v.Len() <= this.hexDumpBytesPerLine {
+		return false
+	}
+
+	count := this.getElementCountToDescribe(v.Len())
+	offsetWidth := getHexDumpOffsetWidth(count)
+	this.writeTypeName(v.Type().Elem())
+	this.writeString(tokOpenArray)
+	this.increaseIndent()
+	for offset := 0; offset < count && !this.isOutputFull(); offset += this.hexDumpBytesPerLine {
+		this.writeItemSeparator(offset == 0)
+		this.writeFmt("%0*x%v", offsetWidth, offset, tokHexDumpOffsetSeparator)
+		for i := offset; i < offset+this.hexDumpBytesPerLine && i < count; i++ {
+			this.writeFmt(" %02x", uint8(v.Index(i).Uint()))
+		}
+	}
+	this.writeOmittedElements(v.Len()-count, false)
+	this.decreaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString(tokCloseArray)
+	return true
+}
+
+// Get the number of hex digits needed to show every offset below length
+// (at least 4, to line up with common hex dump tools).
+func getHexDumpOffsetWidth(length int) int {
+	width := 4
+	for limit := 0x10000; limit < length; limit <<= 4 {
+		width++
+	}
+	return width
+}
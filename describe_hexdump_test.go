@@ -0,0 +1,45 @@
+package describe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHexDump(t *testing.T) {
+	v := []byte{0xff, 0x80, 0x44, 0x01, 0, 0, 0, 0, 0x7f, 0x12}
+
+	expected := "uint8[\n  0000: ff 80 44 01\n  0004: 00 00 00 00\n  0008: 7f 12\n]"
+	actual := DescribeOpts(v, WithIndent(2), WithHexDump(4))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "uint8[\n  0000: ff 80 44 01\n  0004: 00 00\n  …(+4 more elements)\n]"
+	actual = DescribeOpts(v, WithIndent(2), WithHexDump(4), WithMaxElements(6))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Short enough to fit on one line
+	expected = "uint8[\n  0xff\n  0x80\n]"
+	actual = DescribeOpts(v[:2], WithIndent(2), WithHexDump(4))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Single-line mode
+	expected = "uint8[0xff 0x80 0x44 0x01 0x00 0x00 0x00 0x00 0x7f 0x12]"
+	actual = DescribeOpts(v, WithHexDump(4))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestHexDumpOffsetWidth(t *testing.T) {
+	v := make([]byte, 0x10001)
+	expected := "uint8[\n  00000: 00"
+	actual := DescribeOpts(v, WithIndent(2), WithHexDump(16))
+	if !strings.HasPrefix(actual, expected) {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
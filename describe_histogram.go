@@ -0,0 +1,46 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// Describes how many values (struct fields, array/slice elements and map
+// values) are at each depth of v's object graph, one depth per line, followed
+// by the percentage of all values at or above that depth:
+//
+//	0: 1 (0.8%)
+//	1: 4 (4.0%)
+//	2: 120 (100.0%)
+//
+// Depths count the same way as in WithMaxDepth(), so the percentage is how much
+// of v a description with WithMaxDepth(depth) would show. This is useful for
+// picking a sensible maximum depth before enabling descriptions of large data
+// in production.
+//
+// The object graph is walked as with Walk(): data that's reachable via
+// multiple paths is counted once per path, but its contents only once.
+func DepthHistogram(v interface{}) string {
+	var counts []int
+	total := 0
+	Walk(v, func(path string, v reflect.Value, depth int) bool {
+		for len(counts) <= depth {
+			counts = append(counts, 0)
+		}
+		counts[depth]++
+		total++
+		return true
+	})
+
+	var buffer bytes.Buffer
+	cumulative := 0
+	for depth, count := range counts {
+		if depth > 0 {
+			buffer.WriteString("\n")
+		}
+		cumulative += count
+		fmt.Fprintf(&buffer, "%v: %v (%.1f%%)", depth, count, float64(cumulative)*100/float64(total))
+	}
+	return buffer.String()
+}
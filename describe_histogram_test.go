@@ -0,0 +1,30 @@
+package describe
+
+import (
+	"testing"
+)
+
+type HistogramNode struct {
+	Value    int
+	Children []*HistogramNode
+}
+
+func TestDepthHistogram(t *testing.T) {
+	root := &HistogramNode{Children: []*HistogramNode{{Value: 1}, {Value: 2}}}
+	root.Children[1].Children = []*HistogramNode{root}
+	expected := `0: 1 (10.0%)
+1: 2 (30.0%)
+2: 2 (50.0%)
+3: 4 (90.0%)
+4: 1 (100.0%)`
+	actual := DepthHistogram(root)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "0: 1 (100.0%)"
+	actual = DepthHistogram(nil)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -0,0 +1,123 @@
+package describe
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A description that was recorded in the history. See History().
+type HistoryEntry struct {
+	Time time.Time
+	// Where the description was requested from: `main.go:42`
+	CallSite    string
+	Description string
+}
+
+// A bounded ring of the most recent descriptions. See History().
+type DescriptionHistory struct {
+	mutex   sync.Mutex
+	entries []HistoryEntry
+	next    int
+	isFull  bool
+}
+
+var currentHistory atomic.Value
+
+// Start keeping the last maxEntries descriptions in memory (along with when
+// and where they were made), replacing any previous history. This is for
+// post-mortem debugging: after a crash or on demand (from a signal handler or
+// debug endpoint), dump the history to see what the state looked like leading
+// up to it.
+//
+// Descriptions made via Describe(), DescribeOpts(), DescribeValue(), D() and
+// Describers are recorded. Descriptions that DescribeTo() streams straight to
+// its writer are not, since they're never held in memory.
+//
+// A maxEntries of 0 stops recording, and returns nil.
+func History(maxEntries int) *DescriptionHistory {
+	var history *DescriptionHistory
+	if maxEntries > 0 {
+		history = &DescriptionHistory{
+			entries: make([]HistoryEntry, maxEntries),
+		}
+	}
+	currentHistory.Store(history)
+	return history
+}
+
+func loadHistory() *DescriptionHistory {
+	history, _ := currentHistory.Load().(*DescriptionHistory)
+	return history
+}
+
+func (this *DescriptionHistory) record(description string) {
+	entry := HistoryEntry{
+		Time:        time.Now(),
+		CallSite:    getCallSite(),
+		Description: description,
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.entries[this.next] = entry
+	this.next++
+	if this.next == len(this.entries) {
+		this.next = 0
+		this.isFull = true
+	}
+}
+
+// Get the recorded descriptions, oldest first.
+func (this *DescriptionHistory) Entries() []HistoryEntry {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if !this.isFull {
+		return append([]HistoryEntry{}, this.entries[:this.next]...)
+	}
+	return append(append([]HistoryEntry{}, this.entries[this.next:]...), this.entries[:this.next]...)
+}
+
+// Write the recorded descriptions to w, oldest first, one per line:
+// `2020-01-02T15:04:05.000000Z main.go:42: main.Config<Port=8080>`
+func (this *DescriptionHistory) Dump(w io.Writer) error {
+	_, err := io.WriteString(w, this.String())
+	return err
+}
+
+func (this *DescriptionHistory) String() string {
+	builder := strings.Builder{}
+	for _, entry := range this.Entries() {
+		builder.WriteString(fmt.Sprintf("%v %v: %v\n",
+			entry.Time.UTC().Format("2006-01-02T15:04:05.000000Z"), entry.CallSite, entry.Description))
+	}
+	return builder.String()
+}
+
+var packageDirectory = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// Get the file and line of the first caller outside of this package.
+func getCallSite() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		isInPackage := filepath.Dir(frame.File) == packageDirectory && !strings.HasSuffix(frame.File, "_test.go")
+		if !isInPackage {
+			return fmt.Sprintf("%v:%v", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return "?"
+		}
+	}
+}
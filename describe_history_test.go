@@ -0,0 +1,45 @@
+package describe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	history := History(2)
+	defer History(0)
+
+	D(1)
+	D(2)
+	DescribeOpts(3)
+	entries := history.Entries()
+
+	expected := "2 3"
+	actual := ""
+	for _, entry := range entries {
+		actual = strings.TrimSpace(actual + " " + entry.Description)
+	}
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.CallSite, "describe_history_test.go:") {
+			t.Errorf("Expected call site in describe_history_test.go but got %v", entry.CallSite)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(history.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], ": 3") {
+		t.Errorf("Unexpected history dump: %v", history.String())
+	}
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	if History(0) != nil {
+		t.Errorf("Expected no history")
+	}
+	D(1)
+	if loadHistory() != nil {
+		t.Errorf("Expected no history")
+	}
+}
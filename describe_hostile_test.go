@@ -0,0 +1,284 @@
+package describe
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// Values that are awkward to describe. Describing any of them must not panic,
+// must not produce an empty description, and must not trigger a library bug.
+
+type hostileUnexported struct {
+	str       string
+	ptr       *int
+	iface     interface{}
+	fn        func() chan int
+	ch        chan func()
+	m         map[string]func() chan int
+	arr       [2]unsafe.Pointer
+	slice     []reflect.Value
+	typ       reflect.Type
+	mutex     sync.Mutex
+	err       error
+	stringer  PanickingStringer
+	nilString *nilPanickingStringer
+	embedded
+}
+
+type embedded struct {
+	value interface{}
+}
+
+type nilPanickingStringer struct {
+	name string
+}
+
+func (this *nilPanickingStringer) String() string {
+	return this.name
+}
+
+type hostileError struct{}
+
+func (this *hostileError) Error() string {
+	panic("error panic")
+}
+
+type hostileSelf struct {
+	self  interface{}
+	value reflect.Value
+	slice []interface{}
+	m     map[interface{}]interface{}
+}
+
+type hostileDescribed struct{}
+
+type hostileEmptyDescribed struct{}
+
+type hostileEmptyStringer struct{}
+
+func (this hostileEmptyStringer) String() string {
+	return ""
+}
+
+func getHostileValues() []interface{} {
+	one := 1
+	closedChannel := make(chan int, 3)
+	closedChannel <- 1
+	close(closedChannel)
+
+	self := &hostileSelf{m: map[interface{}]interface{}{}}
+	self.self = self
+	self.value = reflect.ValueOf(self)
+	self.slice = []interface{}{self, nil}
+	self.slice[1] = self.slice
+	self.m[self] = self.m
+
+	selfSlice := []interface{}{nil}
+	selfSlice[0] = selfSlice
+
+	selfMap := map[string]interface{}{}
+	selfMap["self"] = selfMap
+
+	var selfPointer interface{}
+	selfPointer = &selfPointer
+
+	var deepPointer interface{} = 1
+	for i := 0; i < 1000; i++ {
+		p := deepPointer
+		deepPointer = &p
+	}
+	// Multiline descriptions of deep containers grow quadratically due to
+	// indentation, and every renderer is run on these with every option, so
+	// they're kept shallower.
+	var deepSlice interface{} = 1
+	var deepMap interface{} = 1
+	for i := 0; i < 100; i++ {
+		deepSlice = []interface{}{deepSlice}
+		deepMap = map[int]interface{}{i: deepMap}
+	}
+
+	return []interface{}{
+		nil,
+		reflect.Value{},
+		reflect.ValueOf(nil),
+		reflect.Type(nil),
+		(*int)(nil),
+		(**int)(nil),
+		&one,
+		"",
+		"\xff\xfe invalid utf-8",
+		math.NaN(),
+		math.Inf(-1),
+		complex(math.NaN(), math.Inf(1)),
+		map[float64]int{math.NaN(): 1, math.NaN(): 2},
+		map[interface{}]int{nil: 1, 1: 2, "1": 3},
+		map[string]func() chan int(nil),
+		map[chan int]func(){nil: nil},
+		[]func() chan int{nil},
+		[0]int{},
+		[1 << 12]byte{},
+		struct{}{},
+		[]struct{}{{}, {}},
+		unsafe.Pointer(nil),
+		unsafe.Pointer(&one),
+		uintptr(math.MaxUint64 >> 1),
+		closedChannel,
+		(chan int)(nil),
+		(<-chan int)(nil),
+		(chan<- int)(nil),
+		func() {},
+		(func())(nil),
+		hostileUnexported{},
+		&hostileUnexported{ptr: &one, iface: &one, fn: func() chan int { return nil }, ch: make(chan func(), 1), m: map[string]func() chan int{"x": nil}, slice: []reflect.Value{{}, reflect.ValueOf(&one)}, typ: reflect.TypeOf(one), err: &hostileError{}, nilString: nil, embedded: embedded{value: reflect.Value{}}},
+		[]interface{}{reflect.Value{}, reflect.Type(nil), error(nil), (*hostileError)(nil)},
+		&hostileError{},
+		PanickingStringer{},
+		&PanickingStringer{},
+		(*nilPanickingStringer)(nil),
+		hostileEmptyStringer{},
+		hostileDescribed{},
+		hostileEmptyDescribed{},
+		errors.New(""),
+		fmt.Errorf("wrapped: %w", &hostileError{}),
+		&sync.Mutex{},
+		time.Time{},
+		self,
+		selfSlice,
+		selfMap,
+		selfPointer,
+		&hostileSelf{self: &selfPointer},
+		deepPointer,
+		deepSlice,
+		deepMap,
+	}
+}
+
+func getHostileOptions() [][]Option {
+	return [][]Option{
+		nil,
+		{WithIndent(4)},
+		{WithSortedMapKeys(true), WithSortedSlices(nil)},
+		{WithMaxDepth(3)},
+		{WithBreadthFirstBudget(200)},
+		{WithMaxElements(1), WithMaxStringLength(1), WithMaxOutputBytes(1)},
+		{WithReflectionOnly(true)},
+		{WithUnsafeOperations(false)},
+		{WithChannelLengths(true)},
+		{WithColors(ColorsAlways), WithChecksum(true)},
+		{WithHexDump(16), WithIndent(2), WithBaseIndent(3)},
+		{WithCompactThreshold(256)},
+		{WithRedaction(), WithSortedMapKeys(true)},
+		{WithRootType(reflect.TypeOf(hostileSelf{}))},
+		{WithErrorStacks(10), WithUnexportedDescribers(true), WithFieldGroups(true), WithIndent(2)},
+		{WithEncodedSizes("gob", GobSize)},
+		{WithErrorChains(true), WithStringerFallback(true), WithTimeDetails(true), WithTypeHashes(true), WithGoStringers(true)},
+	}
+}
+
+// Describing must never panic, never return an empty string, and never trip
+// over a bug in this library, no matter what it's given.
+func TestHostileValues(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	var bugs []string
+	oldHandler := LibraryBugHandler
+	LibraryBugHandler = func(description string) {
+		bugs = append(bugs, description)
+	}
+	defer func() { LibraryBugHandler = oldHandler }()
+
+	SetCustomDescriber(reflect.TypeOf(hostileDescribed{}), func(v reflect.Value) string {
+		panic("describer panic")
+	})
+	defer SetCustomDescriber(reflect.TypeOf(hostileDescribed{}), nil)
+	SetCustomDescriber(reflect.TypeOf(hostileEmptyDescribed{}), func(v reflect.Value) string {
+		return ""
+	})
+	defer SetCustomDescriber(reflect.TypeOf(hostileEmptyDescribed{}), nil)
+
+	type renderer struct {
+		name     string
+		describe func(v interface{}, opts ...Option) string
+	}
+	renderers := []renderer{
+		{"DescribeOpts", DescribeOpts},
+		{"DescribeJSON", DescribeJSON},
+		{"DescribeYAML", DescribeYAML},
+		{"DescribeDOT", DescribeDOT},
+		{"DescribeHTML", DescribeHTML},
+		{"DescribeTable", DescribeTable},
+		{"DescribeGoLiteral", DescribeGoLiteral},
+		{"DescribeFlameGraph", DescribeFlameGraph},
+		{"DescribeTree", func(v interface{}, opts ...Option) string {
+			return flattenTree(DescribeTree(v, opts...))
+		}},
+		{"OTelAttributes", func(v interface{}, opts ...Option) string {
+			return describeAttributes(OTelAttributes(v, "v", AttributeLimits{}, opts...))
+		}},
+		{"ExplainDescribers", ExplainDescribers},
+		{"DescribeTo", func(v interface{}, opts ...Option) string {
+			buffer := bytes.Buffer{}
+			DescribeTo(&buffer, v, opts...)
+			return buffer.String()
+		}},
+		{"CrashSafe", func(v interface{}, opts ...Option) string {
+			return string(CrashSafe(v, make([]byte, 0, 100)))
+		}},
+		{"Diff", func(v interface{}, opts ...Option) string {
+			// No differences is a valid result.
+			return "diff: " + Diff(v, nil, opts...)
+		}},
+	}
+	// These don't take options, so they only need to run once per value.
+	optionlessRenderers := []renderer{
+		{"Walk", func(v interface{}, opts ...Option) string {
+			visits := 0
+			Walk(v, func(path string, v reflect.Value, depth int) bool {
+				visits++
+				return true
+			})
+			return fmt.Sprint(visits)
+		}},
+		{"DepthHistogram", func(v interface{}, opts ...Option) string {
+			return DepthHistogram(v)
+		}},
+		{"EncodingPreview", func(v interface{}, opts ...Option) string {
+			return EncodingPreview(v)
+		}},
+	}
+
+	for i, value := range getHostileValues() {
+		for j, opts := range getHostileOptions() {
+			currentRenderers := renderers
+			if j == 0 {
+				currentRenderers = append(currentRenderers[:len(renderers):len(renderers)], optionlessRenderers...)
+			}
+			for _, renderer := range currentRenderers {
+				bugs = nil
+				description := func() (description string) {
+					defer func() {
+						if e := recover(); e != nil {
+							t.Errorf("%v panicked on value %v, options %v: %v", renderer.name, i, j, e)
+						}
+					}()
+					return renderer.describe(value, opts...)
+				}()
+				if description == "" {
+					t.Errorf("%v returned an empty description for value %v, options %v", renderer.name, i, j)
+				}
+				if len(bugs) > 0 {
+					t.Errorf("%v hit a library bug on value %v, options %v: %v", renderer.name, i, j, bugs)
+				}
+			}
+		}
+	}
+}
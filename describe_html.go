@@ -0,0 +1,125 @@
+package describe
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Containers with more than this many fields or elements start collapsed.
+const htmlCollapseThreshold = 10
+
+// Distinguishes the anchors of separate HTML descriptions on the same page.
+var lastHTMLDescriptionID uint64
+
+// Describes an object as an HTML fragment for embedding in debug web pages:
+// a collapsible tree of `<details>` elements, where each struct, array, slice
+// and map can be expanded and collapsed. Large containers (more than 10
+// fields or elements) start collapsed.
+//
+// Data that occurs more than once is described in full the first time, and
+// later occurrences link back to it: `<a href="#describe-1-1">$1</a>`
+//
+// Elements have the classes `describe-type`, `describe-key`,
+// `describe-value` and `describe-ref` for styling.
+func DescribeHTML(v interface{}, opts ...Option) (description string) {
+	context := describer{}
+	context.applyOptions(opts)
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.describeHTML(rv)
+}
+
+func (this *describer) describeHTML(rv reflect.Value) (description string) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				description = html.EscapeString(notifyRecoveredLibraryBug(e))
+			}
+		}
+	}()
+
+	this.referenceNames = this.findDuplicates(rv)
+	this.seenReferences = make(map[duplicates.TypedPointer]bool)
+	descriptionID := atomic.AddUint64(&lastHTMLDescriptionID, 1)
+	this.describeHTMLNode(rv, descriptionID, true)
+	return this.stringBuilder.String()
+}
+
+func (this *describer) describeHTMLNode(v reflect.Value, descriptionID uint64, isRoot bool) {
+	v = followPointers(v, this.isDescribedByUserCode)
+
+	var childCount int
+	if v.IsValid() && !this.isDescribedByUserCode(v) {
+		switch v.Kind() {
+		case reflect.Struct:
+			childCount = len(getVisibleFieldIndices(v))
+		case reflect.Array, reflect.Slice, reflect.Map:
+			childCount = v.Len()
+		}
+	}
+	if childCount == 0 {
+		this.writeFmt(`<code class="describe-value">%v</code>`, html.EscapeString(this.describeWithUserCode(v)))
+		return
+	}
+
+	openTag := "<details"
+	referenceMarker := ""
+	if ptr, ok := getReferencePointer(v); ok {
+		if referenceName, isReferenced := this.referenceNames[ptr]; isReferenced {
+			if this.seenReferences[ptr] {
+				this.writeFmt(`<a class="describe-ref" href="#describe-%v-%v">%v%v</a>`,
+					descriptionID, referenceName, tokReferencePrefix, referenceName)
+				return
+			}
+			this.seenReferences[ptr] = true
+			referenceName = this.assignReferenceName(ptr)
+			openTag = fmt.Sprintf(`<details id="describe-%v-%v"`, descriptionID, referenceName)
+			referenceMarker = fmt.Sprintf(`<span class="describe-ref">%v%v</span>`, referenceName, tokReferenceSeparator)
+		}
+	}
+	this.writeString(openTag)
+	if isRoot || childCount <= htmlCollapseThreshold {
+		this.writeString(" open")
+	}
+	this.writeString("><summary>")
+	this.writeString(referenceMarker)
+	this.writeFmt(`<span class="describe-type">%v</span></summary><ul>`, html.EscapeString(this.typeName(v.Type())))
+
+	writeChild := func(key string, child reflect.Value) {
+		this.writeFmt(`<li><span class="describe-key">%v</span>%v`, html.EscapeString(key), tokKeyValueSeparator)
+		this.describeHTMLNode(child, descriptionID, false)
+		this.writeString("</li>")
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, i := range getVisibleFieldIndices(v) {
+			writeChild(getFieldName(v.Type(), i), this.getDescribedField(v, i))
+		}
+	case reflect.Array, reflect.Slice:
+		count := this.getElementCountToDescribe(v.Len())
+		for i := 0; i < count; i++ {
+			writeChild(fmt.Sprintf("[%v]", i), v.Index(i))
+		}
+		this.writeHTMLOmitted(v.Len()-count, tokMoreElements)
+	case reflect.Map:
+		iter, omittedCount := this.iterateMap(v)
+		for iter.Next() {
+			writeChild(this.describeWithUserCode(iter.Key()), this.getDescribedMapValue(iter.Key(), iter.Value()))
+		}
+		this.writeHTMLOmitted(omittedCount, tokMoreEntries)
+	}
+	this.writeString("</ul></details>")
+}
+
+func (this *describer) writeHTMLOmitted(count int, items string) {
+	if count > 0 {
+		this.writeFmt("<li>%v(+%v more %v)</li>", tokElided, count, items)
+	}
+}
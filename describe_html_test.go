@@ -0,0 +1,49 @@
+package describe
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type HTMLNode struct {
+	Name string
+	Next *HTMLNode
+}
+
+func TestDescribeHTML(t *testing.T) {
+	v := &HTMLNode{Name: "<a>"}
+	v.Next = v
+
+	id := atomic.LoadUint64(&lastHTMLDescriptionID) + 1
+	expected := fmt.Sprintf(`<details id="describe-%v-1" open><summary><span class="describe-ref">1~</span>`+
+		`<span class="describe-type">describe.HTMLNode</span></summary><ul>`+
+		`<li><span class="describe-key">Name</span>=<code class="describe-value">&#34;&lt;a&gt;&#34;</code></li>`+
+		`<li><span class="describe-key">Next</span>=<a class="describe-ref" href="#describe-%v-1">$1</a></li>`+
+		`</ul></details>`, id, id)
+	actual := DescribeHTML(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeHTMLCollapsed(t *testing.T) {
+	v := [][]int{make([]int, htmlCollapseThreshold), make([]int, htmlCollapseThreshold+1)}
+	actual := DescribeHTML(v)
+
+	expected := 2
+	if count := strings.Count(actual, "<details open>"); count != expected {
+		t.Errorf("Expected %v open containers but got %v: %v", expected, count, actual)
+	}
+	expected = 1
+	if count := strings.Count(actual, "<details>"); count != expected {
+		t.Errorf("Expected %v collapsed containers but got %v: %v", expected, count, actual)
+	}
+
+	expectedDescription := `<code class="describe-value">5</code>`
+	actualDescription := DescribeHTML(5)
+	if actualDescription != expectedDescription {
+		t.Errorf("Expected %v but got %v", expectedDescription, actualDescription)
+	}
+}
@@ -0,0 +1,132 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+const (
+	tokImplemented    = "yes"
+	tokNotImplemented = "no"
+)
+
+// Describes which of the given interfaces v's type implements, and for those it
+// doesn't, which methods are missing. Example:
+//
+//	*main.Service<fmt.Stringer=yes io.Closer=no[Close() error]>
+//
+// Missing methods with the right name but the wrong signature are followed by
+// the signature that was found: `no[String() string (has String() int)]`, and
+// if the interface is only implemented by a pointer to v's type, that's noted
+// as well: `no[implemented by *main.Service]`
+//
+// This is useful for debugging wiring and dependency injection problems.
+func Implements(v interface{}, ifaces ...reflect.Type) string {
+	var t reflect.Type
+	if rv, ok := v.(reflect.Value); ok {
+		if rv.IsValid() {
+			t = rv.Type()
+		}
+	} else {
+		t = reflect.TypeOf(v)
+	}
+	if t == nil {
+		return tokInvalid
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(getTypeName(t))
+	buffer.WriteString(tokOpenStruct)
+	for i, iface := range ifaces {
+		if i > 0 {
+			buffer.WriteString(tokItemSeparator)
+		}
+		// Allow reflect.TypeOf((*io.Reader)(nil)) as a shorthand.
+		if iface.Kind() == reflect.Ptr && iface.Elem().Kind() == reflect.Interface {
+			iface = iface.Elem()
+		}
+		buffer.WriteString(getTypeName(iface))
+		buffer.WriteString(tokKeyValueSeparator)
+		buffer.WriteString(explainImplements(t, iface))
+	}
+	buffer.WriteString(tokCloseStruct)
+	return buffer.String()
+}
+
+func explainImplements(t reflect.Type, iface reflect.Type) string {
+	if iface.Kind() != reflect.Interface {
+		return fmt.Sprintf("%v%vnot an interface%v", tokNotImplemented, tokOpenArray, tokCloseArray)
+	}
+	if t.Implements(iface) {
+		return tokImplemented
+	}
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface && reflect.PtrTo(t).Implements(iface) {
+		return fmt.Sprintf("%v%vimplemented by *%v%v", tokNotImplemented, tokOpenArray, getTypeName(t), tokCloseArray)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(tokNotImplemented)
+	buffer.WriteString(tokOpenArray)
+	isFirst := true
+	for i := 0; i < iface.NumMethod(); i++ {
+		wanted := iface.Method(i)
+		wantedSignature := getMethodSignature(wanted.Name, wanted.Type, false)
+		found, ok := t.MethodByName(wanted.Name)
+		if ok {
+			foundSignature := getMethodSignature(found.Name, found.Type, t.Kind() != reflect.Interface)
+			if foundSignature == wantedSignature {
+				continue
+			}
+			wantedSignature = fmt.Sprintf("%v (has %v)", wantedSignature, foundSignature)
+		}
+		if !isFirst {
+			buffer.WriteString(", ")
+		}
+		isFirst = false
+		buffer.WriteString(wantedSignature)
+	}
+	buffer.WriteString(tokCloseArray)
+	return buffer.String()
+}
+
+// Get a method signature in the style of an interface declaration:
+// `Read([]uint8) (int, error)`
+func getMethodSignature(name string, funcType reflect.Type, hasReceiver bool) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(name)
+	buffer.WriteString("(")
+	firstIn := 0
+	if hasReceiver {
+		firstIn = 1
+	}
+	for i := firstIn; i < funcType.NumIn(); i++ {
+		if i > firstIn {
+			buffer.WriteString(", ")
+		}
+		if funcType.IsVariadic() && i == funcType.NumIn()-1 {
+			buffer.WriteString("...")
+			buffer.WriteString(getTypeName(funcType.In(i).Elem()))
+		} else {
+			buffer.WriteString(getTypeName(funcType.In(i)))
+		}
+	}
+	buffer.WriteString(")")
+
+	switch funcType.NumOut() {
+	case 0:
+	case 1:
+		buffer.WriteString(" ")
+		buffer.WriteString(getTypeName(funcType.Out(0)))
+	default:
+		buffer.WriteString(" (")
+		for i := 0; i < funcType.NumOut(); i++ {
+			if i > 0 {
+				buffer.WriteString(", ")
+			}
+			buffer.WriteString(getTypeName(funcType.Out(i)))
+		}
+		buffer.WriteString(")")
+	}
+	return buffer.String()
+}
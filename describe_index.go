@@ -0,0 +1,58 @@
+package describe
+
+// An index into a description, for tools such as editors and viewers that
+// want to jump around within large descriptions. All offsets are byte offsets
+// into the description.
+//
+// The index is intended to be stored alongside the description as JSON (via
+// encoding/json).
+type DescriptionIndex struct {
+	// The offset of each reference ID's definition (the `1~` marker)
+	References map[int]int `json:"references"`
+	// The [start, end) offsets of each value's description, keyed by its path
+	// (see WithAnnotator). The top-level value has the path "".
+	Paths map[string][2]int `json:"paths"`
+}
+
+func (this *DescriptionIndex) reset() {
+	this.References = make(map[int]int)
+	this.Paths = make(map[string][2]int)
+}
+
+func (this *DescriptionIndex) copyFrom(other *DescriptionIndex) {
+	this.reset()
+	for id, offset := range other.References {
+		this.References[id] = offset
+	}
+	for path, offsets := range other.Paths {
+		this.Paths[path] = offsets
+	}
+}
+
+// Remove or shorten entries that lie beyond a truncated description's length.
+func (this *DescriptionIndex) clip(length int) {
+	for id, offset := range this.References {
+		if offset >= length {
+			delete(this.References, id)
+		}
+	}
+	for path, offsets := range this.Paths {
+		if offsets[0] >= length {
+			delete(this.Paths, path)
+		} else if offsets[1] > length {
+			this.Paths[path] = [2]int{offsets[0], length}
+		}
+	}
+}
+
+func (this *describer) indexReference(id int, offset int) {
+	if this.index != nil {
+		this.index.References[id] = offset
+	}
+}
+
+func (this *describer) indexPath(start int) {
+	if this.index != nil {
+		this.index.Paths[this.currentPath()] = [2]int{start, this.outputLength()}
+	}
+}
@@ -0,0 +1,58 @@
+package describe
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func assertIndexedText(t *testing.T, description string, index DescriptionIndex, path string, expected string) {
+	offsets, ok := index.Paths[path]
+	if !ok {
+		t.Errorf("Expected path %v in index %v", path, index.Paths)
+		return
+	}
+	actual := description[offsets[0]:offsets[1]]
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescriptionIndex(t *testing.T) {
+	user := &IdentifiedUser{Name: "alice", Age: 30}
+	v := IdentifiedUsers{Owner: user, Admins: []*IdentifiedUser{user}}
+
+	var index DescriptionIndex
+	description := DescribeOpts(v, WithIndex(&index))
+	assertIndexedText(t, description, index, "", description)
+	assertIndexedText(t, description, index, "Owner", `*1~describe.IdentifiedUser<Name="alice" Age=30>`)
+	assertIndexedText(t, description, index, "Owner.Name", `"alice"`)
+	assertIndexedText(t, description, index, "Admins[0]", `*$1("alice")`)
+
+	expected := "1~"
+	actual := description[index.References[1] : index.References[1]+2]
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	encoded, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded DescriptionIndex
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	assertIndexedText(t, description, decoded, "Owner.Name", `"alice"`)
+}
+
+func TestDescriptionIndexBreadthFirst(t *testing.T) {
+	v := map[string][]int{"a": {1, 2, 3, 4, 5, 6, 7, 8, 9}}
+
+	var index DescriptionIndex
+	description := DescribeOpts(v, WithBreadthFirstBudget(30), WithIndex(&index))
+	assertIndexedText(t, description, index, "", description)
+	assertIndexedText(t, description, index, `["a"]`, `int[…]`)
+	if _, ok := index.Paths[`["a"][0]`]; ok {
+		t.Errorf("Expected no index entry for elided values in %v", index.Paths)
+	}
+}
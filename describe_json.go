@@ -0,0 +1,300 @@
+package describe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Describes an object as a JSON document with the same type-annotated,
+// cycle-safe structure as DescribeOpts(), for feeding into log aggregators and
+// tools such as jq:
+//
+//   - Nil values are `null`, and bools, numbers and strings are JSON values.
+//   - Pointers and interfaces are transparent (described as what they point to).
+//     Pointers that lead back to themselves via other pointers and interfaces
+//     (such as `x = &x`) are `{"$type":"*interface {}","$id":1,"$value":{"$ref":1}}`.
+//   - Structs are objects with a `"$type"` member, followed by their fields.
+//   - Arrays and slices are `{"$type":"[]int","$elements":[1,2,3]}`.
+//   - Maps are `{"$type":"map[string]int","$entries":[["a",1],["b",2]]}`.
+//   - Values described by user code (custom describers, String() methods)
+//     are `{"$type":"time.Duration","$description":"time.Duration<1s>"}`.
+//   - Anything else (such as functions and channels) is `{"$type":"chan int"}`.
+//
+// Structs, arrays, slices and maps that occur more than once get an `"$id"`
+// member the first time, and are replaced with `{"$ref":1}` after that.
+// Collections that were truncated (see WithMaxElements()) have an `"$omitted"`
+// member with the number of elements left out.
+//
+// With WithIndent(), the document is indented using that many spaces.
+func DescribeJSON(v interface{}, opts ...Option) (description string) {
+	context := describer{}
+	context.applyOptions(opts)
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.describeJSON(rv)
+}
+
+func (this *describer) describeJSON(rv reflect.Value) (description string) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				description = jsonQuote(notifyRecoveredLibraryBug(e))
+			}
+		}
+	}()
+
+	this.referenceNames = this.findDuplicates(rv)
+	this.seenReferences = make(map[duplicates.TypedPointer]bool)
+	this.describeJSONValue(rv)
+	description = this.stringBuilder.String()
+	if this.indentStep > 0 {
+		buffer := bytes.Buffer{}
+		if err := json.Indent(&buffer, []byte(description), "", strings.Repeat(" ", this.indentStep)); err != nil {
+			return jsonQuote(notifyLibraryBug("invalid JSON generated: %v", err))
+		}
+		description = buffer.String()
+	}
+	return
+}
+
+func (this *describer) describeJSONValue(v reflect.Value) {
+	if !v.IsValid() || isNil(v) {
+		this.writeString("null")
+		return
+	}
+	if this.isDescribedByUserCode(v) {
+		this.writeJSONTypeOpen(v)
+		this.writeFmt(`,"$description":%v}`, jsonQuote(this.describeWithUserCode(v)))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if this.isReferencedPointer(v) {
+			this.describeJSONPointer(v)
+			return
+		}
+		this.describeJSONValue(v.Elem())
+	case reflect.Bool:
+		this.writeString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		this.writeString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		this.writeString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		this.writeJSONFloat(v.Float(), v.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		this.writeString(jsonQuote(fmt.Sprintf("%v", v.Complex())))
+	case reflect.String:
+		this.writeString(jsonQuote(v.String()))
+	case reflect.Struct:
+		this.describeJSONStruct(v)
+	case reflect.Array, reflect.Slice:
+		this.describeJSONArray(v)
+	case reflect.Map:
+		this.describeJSONMap(v)
+	case reflect.UnsafePointer:
+		this.writeString(jsonQuote(stringifyAddress(uint64(v.Pointer()))))
+	default:
+		this.writeJSONTypeOpen(v)
+		this.writeString("}")
+	}
+}
+
+// Write the opening of a JSON object, with its type (and an ID if it's
+// referenced elsewhere). Returns true if v was already described, in which
+// case a reference has been written in its place.
+func (this *describer) writeJSONObjectOpen(v reflect.Value) (didWriteReference bool) {
+	ptr, ok := getReferencePointer(v)
+	if ok {
+		if referenceName, isReferenced := this.referenceNames[ptr]; isReferenced {
+			if this.seenReferences[ptr] {
+				this.writeFmt(`{"$ref":%v}`, referenceName)
+				return true
+			}
+			this.seenReferences[ptr] = true
+			this.writeJSONTypeOpen(v)
+			this.writeFmt(`,"$id":%v`, this.assignReferenceName(ptr))
+			return false
+		}
+	}
+	this.writeJSONTypeOpen(v)
+	return false
+}
+
+func (this *describer) writeJSONTypeOpen(v reflect.Value) {
+	this.writeFmt(`{"$type":%v`, jsonQuote(this.typeName(v.Type())))
+}
+
+func (this *describer) describeJSONStruct(v reflect.Value) {
+	if this.writeJSONObjectOpen(v) {
+		return
+	}
+	for _, i := range getVisibleFieldIndices(v) {
+		this.writeFmt(",%v:", jsonQuote(getFieldName(v.Type(), i)))
+		this.describeJSONValue(this.getDescribedField(v, i))
+	}
+	this.writeString("}")
+}
+
+// Returns true if v is a pointer that is only recorded as a duplicate via its
+// own address (see getReferencePointer()).
+func (this *describer) isReferencedPointer(v reflect.Value) bool {
+	if v.Kind() != reflect.Ptr {
+		return false
+	}
+	ptr, ok := getReferencePointer(v)
+	if !ok {
+		return false
+	}
+	_, isReferenced := this.referenceNames[ptr]
+	return isReferenced
+}
+
+// Describe a referenced pointer as an object, since there's nothing else to
+// hang its ID on.
+func (this *describer) describeJSONPointer(v reflect.Value) {
+	if this.writeJSONObjectOpen(v) {
+		return
+	}
+	this.writeString(`,"$value":`)
+	this.describeJSONValue(v.Elem())
+	this.writeString("}")
+}
+
+func (this *describer) describeJSONArray(v reflect.Value) {
+	if this.writeJSONObjectOpen(v) {
+		return
+	}
+	count := this.getElementCountToDescribe(v.Len())
+	this.writeString(`,"$elements":[`)
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			this.writeString(",")
+		}
+		this.describeJSONValue(v.Index(i))
+	}
+	this.writeString("]")
+	this.writeJSONOmitted(v.Len() - count)
+	this.writeString("}")
+}
+
+func (this *describer) describeJSONMap(v reflect.Value) {
+	if this.writeJSONObjectOpen(v) {
+		return
+	}
+	iter, omittedCount := this.iterateMap(v)
+	this.writeString(`,"$entries":[`)
+	for isFirst := true; iter.Next(); isFirst = false {
+		if !isFirst {
+			this.writeString(",")
+		}
+		this.writeString("[")
+		this.describeJSONValue(iter.Key())
+		this.writeString(",")
+		this.describeJSONValue(this.getDescribedMapValue(iter.Key(), iter.Value()))
+		this.writeString("]")
+	}
+	this.writeString("]")
+	this.writeJSONOmitted(omittedCount)
+	this.writeString("}")
+}
+
+func (this *describer) writeJSONOmitted(count int) {
+	if count > 0 {
+		this.writeFmt(`,"$omitted":%v`, count)
+	}
+}
+
+// JSON has no representation for NaN and infinities, so they become strings.
+func (this *describer) writeJSONFloat(value float64, bits int) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		this.writeString(jsonQuote(fmt.Sprintf("%v", value)))
+		return
+	}
+	this.writeString(strconv.FormatFloat(value, 'g', -1, bits))
+}
+
+// Get the pointer under which duplicates of v are recorded (see
+// tryDescribeReference()).
+func getReferencePointer(v reflect.Value) (ptr duplicates.TypedPointer, ok bool) {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Array:
+		if !v.CanAddr() {
+			return
+		}
+		return duplicates.TypedPointerOfRV(v.Addr()), true
+	case reflect.Slice, reflect.Map:
+		return duplicates.TypedPointerOfRV(v), true
+	case reflect.Ptr:
+		// Pointers to containers are recorded via what they point to, but
+		// chains of pointers and interfaces (such as `x = &x`) can only be
+		// recorded via their pointers.
+		if kind := v.Type().Elem().Kind(); kind == reflect.Ptr || kind == reflect.Interface {
+			return duplicates.TypedPointerOfRV(v), true
+		}
+	}
+	return
+}
+
+// Returns true if the regular describer would describe v using user code (or
+// specially, as with reflect.Value), rather than by its structure.
+func (this *describer) isDescribedByUserCode(v reflect.Value) bool {
+	t := v.Type()
+	if t == reflectValueType || t.Implements(reflectTypeType) {
+		return true
+	}
+	if (this.timeLayout != "" || this.timeDetails) && t == timeType {
+		return true
+	}
+	if this.reflectionOnly {
+		return false
+	}
+	if _, ok := this.getCustomDescriber(t); ok {
+		return true
+	}
+	if v.IsZero() && !this.stringerFallback {
+		return false
+	}
+	if hasStringMethod(v) {
+		return true
+	}
+	if !this.goStringers {
+		return false
+	}
+	_, ok := this.getReceiverImplementing(v, goStringerType)
+	return ok
+}
+
+// Describe v on a single line using the regular describer (with the same
+// options otherwise). Options that apply to the whole output (such as limits
+// and checksums) are left to the caller.
+func (this *describer) describeWithUserCode(v reflect.Value) string {
+	context := describer{options: this.options}
+	context.indentStep = 0
+	context.index = nil
+	context.breadthFirstBudget = 0
+	context.colors = ColorsNever
+	context.appendChecksum = false
+	context.maxOutputBytes = 0
+	return context.describeValue(v)
+}
+
+func jsonQuote(s string) string {
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	// Descriptions are full of `<>`, which would otherwise become \u003c etc.
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
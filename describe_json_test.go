@@ -0,0 +1,74 @@
+package describe
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+type JSONNode struct {
+	Name     string
+	next     *JSONNode
+	Values   []int
+	Scores   map[string]float64
+	Timeout  time.Duration
+	Callback func()
+	Any      interface{}
+}
+
+func TestDescribeJSON(t *testing.T) {
+	v := &JSONNode{
+		Name:    "a",
+		Values:  []int{1, 2, 3},
+		Scores:  map[string]float64{"x": 1.5, "y": math.Inf(1)},
+		Timeout: time.Second,
+		Any:     true,
+	}
+	v.next = v
+
+	expected := `{"$type":"describe.JSONNode","$id":1,"Name":"a","next":{"$ref":1},` +
+		`"Values":{"$type":"[]int","$elements":[1,2,3]},` +
+		`"Scores":{"$type":"map[string]float64","$entries":[["x",1.5],["y","+Inf"]]},` +
+		`"Timeout":{"$type":"time.Duration","$description":"time.Duration<1s>"},` +
+		`"Callback":null,"Any":true}`
+	actual := DescribeJSON(v, WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	if !json.Valid([]byte(actual)) {
+		t.Errorf("Invalid JSON: %v", actual)
+	}
+}
+
+func TestDescribeJSONOptions(t *testing.T) {
+	expected := "{\n  \"$type\": \"[]string\",\n  \"$elements\": [\n    \"a\"\n  ],\n  \"$omitted\": 2\n}"
+	actual := DescribeJSON([]string{"a", "b", "c"}, WithIndent(2), WithMaxElements(1))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `null`
+	actual = DescribeJSON(nil)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `{"$type":"time.Duration","$description":"time.Duration<1s>"}`
+	actual = DescribeJSON(time.Second)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `1000000000`
+	actual = DescribeJSON(time.Second, WithReflectionOnly(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	// Checksums and limits apply to the whole output, not to descriptions.
+	expected = `{"$type":"time.Duration","$description":"time.Duration<1s>"}`
+	actual = DescribeJSON(time.Second, WithChecksum(true), WithMaxOutputBytes(10))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -0,0 +1,60 @@
+package describe
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Without unsafe operations, a reflect.Type in an unexported field can't be
+// extracted, but its underlying pointer can still be read and matched against
+// types that are already known. Only a fixed set of predeclared and common
+// types is known, so that how a type is named never depends on what was
+// described before. This map is only written to during init.
+var knownTypes = make(map[duplicates.TypedPointer]reflect.Type)
+
+func init() {
+	for _, v := range []interface{}{
+		false, int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0), uintptr(0),
+		float32(0), float64(0), complex64(0), complex128(0), "",
+		unsafe.Pointer(nil), []byte(nil), []string(nil), map[string]interface{}(nil),
+		time.Time{}, time.Duration(0), reflect.Value{},
+	} {
+		rememberType(reflect.TypeOf(v))
+	}
+	rememberType(emptyInterfaceType)
+	rememberType(reflectTypeType)
+	rememberType(reflect.TypeOf((*error)(nil)).Elem())
+}
+
+func getTypePointer(v reflect.Value) (ptr duplicates.TypedPointer, ok bool) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return
+	}
+	return duplicates.TypedPointerOfRV(v), true
+}
+
+func rememberType(t reflect.Type) {
+	if ptr, ok := getTypePointer(reflect.ValueOf(t)); ok {
+		knownTypes[ptr] = t
+	}
+}
+
+// Look up the reflect.Type that v (which can't be extracted) holds.
+func lookupKnownType(v reflect.Value) (t reflect.Type, ok bool) {
+	ptr, ok := getTypePointer(v)
+	if !ok {
+		return
+	}
+	t, ok = knownTypes[ptr]
+	return
+}
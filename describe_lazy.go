@@ -0,0 +1,41 @@
+package describe
+
+import (
+	"sync"
+)
+
+// A description that's only made when it's first needed. See Lazy().
+type LazyDescription struct {
+	once        sync.Once
+	value       interface{}
+	opts        []Option
+	description string
+}
+
+// Returns a fmt.Stringer that describes v (using opts) the first time its
+// String() method is called, and returns the same description after that.
+// This keeps log statements that are usually filtered out (such as debug
+// logs) from paying for describing up front:
+//
+//	log.Debugf("state: %v", describe.Lazy(state))
+//
+// Since v isn't described until later, changes made to what v points to in
+// the meantime will show up in the description. v is released once it's been
+// described.
+func Lazy(v interface{}, opts ...Option) *LazyDescription {
+	return &LazyDescription{
+		value: v,
+		opts:  opts,
+	}
+}
+
+// Describe the value (if not done already). This is safe to call from
+// multiple goroutines.
+func (this *LazyDescription) String() string {
+	this.once.Do(func() {
+		this.description = DescribeOpts(this.value, this.opts...)
+		this.value = nil
+		this.opts = nil
+	})
+	return this.description
+}
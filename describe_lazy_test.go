@@ -0,0 +1,37 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type LazyTest struct {
+	Value int
+}
+
+func TestLazy(t *testing.T) {
+	describeCount := 0
+	describer := func(v reflect.Value) string {
+		describeCount++
+		return fmt.Sprintf("lazy %v", v.Interface().(LazyTest).Value)
+	}
+	v := &LazyTest{Value: 1}
+
+	lazy := Lazy(v, WithCustomDescriber(reflect.TypeOf(LazyTest{}), describer))
+	if describeCount != 0 {
+		t.Errorf("Expected no description before String() but got %v", describeCount)
+	}
+
+	v.Value = 2
+	expected := "*lazy 2"
+	for i := 0; i < 2; i++ {
+		actual := fmt.Sprintf("%v", lazy)
+		if actual != expected {
+			t.Errorf("Expected %v but got %v", expected, actual)
+		}
+	}
+	if describeCount != 1 {
+		t.Errorf("Expected one description but got %v", describeCount)
+	}
+}
@@ -0,0 +1,262 @@
+package describe
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+)
+
+type mapIterator interface {
+	Next() bool
+	Key() reflect.Value
+	Value() reflect.Value
+}
+
+type sortedMapIter struct {
+	mapInstance reflect.Value
+	keys        []reflect.Value
+	index       int
+}
+
+func (this *sortedMapIter) Key() reflect.Value {
+	return this.keys[this.index]
+}
+
+func (this *sortedMapIter) Value() reflect.Value {
+	return this.mapInstance.MapIndex(this.Key())
+}
+
+func (this *sortedMapIter) Next() bool {
+	this.index++
+	return this.index < len(this.keys)
+}
+
+func sortedMapRange(v reflect.Value, opts ...Option) *sortedMapIter {
+	keys := v.MapKeys()
+	sortMapKeys(keys, opts...)
+	return &sortedMapIter{
+		mapInstance: v,
+		keys:        keys,
+		index:       -1,
+	}
+}
+
+// Iterate over the entries of v to describe, returning the number of entries
+// that were left out due to sampling (see WithMapSampling()).
+func (this *describer) iterateMap(v reflect.Value) (iter mapIterator, omittedCount int) {
+	if keys, ok := this.getSampledMapKeys(v); ok {
+		return &sortedMapIter{mapInstance: v, keys: keys, index: -1}, v.Len() - len(keys)
+	}
+	// Type-keyed registries are always sorted, since there's no other
+	// meaningful order for types.
+	if this.sortMapKeys || isTypeKeyedMap(v) {
+		return sortedMapRange(v, WithReflectionOnly(this.reflectionOnly)), 0
+	}
+	return mapRange(v), 0
+}
+
+// Returns true if v is a map keyed by reflect.Type, such as a registry.
+func isTypeKeyedMap(v reflect.Value) bool {
+	return v.Type().Key() == reflectTypeType
+}
+
+// Describe the key of a type-keyed map as just the type's name, since the map
+// type already says that it's a reflect.Type: `reflect.Type:int{string=1}`
+func (this *describer) tryDescribeTypeKey(key reflect.Value) (didDescribe bool) {
+	if key.Type() != reflectTypeType || key.IsNil() {
+		return false
+	}
+	t, ok := this.getInterfaceAsReflectType(key)
+	if !ok {
+		return false
+	}
+	this.writeTypeName(t)
+	return true
+}
+
+// Get the keys to describe if v is large enough to be sampled.
+func (this *describer) getSampledMapKeys(v reflect.Value) (keys []reflect.Value, ok bool) {
+	sampleSize := this.mapSampleSize
+	if sampleSize <= 0 {
+		sampleSize = this.maxElements
+	}
+	if sampleSize <= 0 || v.Len() <= sampleSize {
+		return
+	}
+	switch this.mapSampling {
+	case MapSampleRandom:
+		// Reservoir sampling, so that every entry is equally likely to be
+		// chosen regardless of iteration order.
+		keys = make([]reflect.Value, 0, sampleSize)
+		iter := mapRange(v)
+		for seen := 0; iter.Next(); seen++ {
+			if len(keys) < sampleSize {
+				keys = append(keys, iter.Key())
+			} else if i := rand.Intn(seen + 1); i < sampleSize {
+				keys[i] = iter.Key()
+			}
+		}
+	default:
+		keys = v.MapKeys()
+	}
+	sortMapKeys(keys, WithReflectionOnly(this.reflectionOnly))
+	if len(keys) > sampleSize {
+		keys = keys[:sampleSize]
+	}
+	return keys, true
+}
+
+// Write the marker for map entries that were left out:
+// `…(+98765 more entries)`
+func (this *describer) writeOmittedEntries(count int, isFirst bool) {
+	this.writeOmittedItems(count, tokMoreEntries, isFirst)
+}
+
+// Write the marker for elements that were left out: `…(+9963 more elements)`
+func (this *describer) writeOmittedElements(count int, isFirst bool) {
+	this.writeOmittedItems(count, tokMoreElements, isFirst)
+}
+
+func (this *describer) writeOmittedItems(count int, items string, isFirst bool) {
+	if count <= 0 {
+		return
+	}
+	this.writeItemSeparator(isFirst)
+	this.writeFmt("%v(+%v more %v)", tokElided, count, items)
+}
+
+// Sort map keys into a stable order (see getSortedIndices).
+func sortMapKeys(keys []reflect.Value, opts ...Option) {
+	sorted := make([]reflect.Value, len(keys))
+	for i, index := range getSortedIndices(keys, nil, opts...) {
+		sorted[i] = keys[index]
+	}
+	copy(keys, sorted)
+}
+
+// Get the indices of values in sorted order. If less is nil, values are
+// sorted numerically for numbers, lexically for strings, and by their
+// descriptions (using opts) for everything else. The sort is stable.
+func getSortedIndices(values []reflect.Value, less Comparator, opts ...Option) []int {
+	descriptions := make(map[int]string)
+	getDescription := func(index int) string {
+		if description, ok := descriptions[index]; ok {
+			return description
+		}
+		description := describeInternal(values[index], opts...)
+		descriptions[index] = description
+		return description
+	}
+	indices := make([]int, len(values))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	sort.SliceStable(indices, func(i, j int) bool {
+		if less != nil {
+			return less(values[indices[i]], values[indices[j]])
+		}
+		a := unwrapInterface(values[indices[i]])
+		b := unwrapInterface(values[indices[j]])
+		if a.Kind() == b.Kind() {
+			switch a.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return a.Int() < b.Int()
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				return a.Uint() < b.Uint()
+			case reflect.Float32, reflect.Float64:
+				return a.Float() < b.Float()
+			case reflect.String:
+				return a.String() < b.String()
+			case reflect.Bool:
+				return !a.Bool() && b.Bool()
+			}
+		} else if a.Kind() != reflect.Invalid && b.Kind() != reflect.Invalid {
+			return a.Kind() < b.Kind()
+		}
+		return getDescription(indices[i]) < getDescription(indices[j])
+	})
+	return indices
+}
+
+// Get the order in which to describe a slice's elements if they're to be
+// sorted, or nil to describe them in their natural order.
+func (this *describer) getSortedElementOrder(v reflect.Value) []int {
+	if !this.sortSlices || v.Kind() != reflect.Slice {
+		return nil
+	}
+	elements := make([]reflect.Value, v.Len())
+	for i := range elements {
+		elements[i] = v.Index(i)
+	}
+	return getSortedIndices(elements, this.sliceComparator, WithReflectionOnly(this.reflectionOnly))
+}
+
+// Returns true if v is a map used as a set: either its values are empty
+// structs, or they are bools that are all true.
+func isSetLikeMap(v reflect.Value) bool {
+	elemType := v.Type().Elem()
+	switch elemType.Kind() {
+	case reflect.Struct:
+		return elemType.NumField() == 0
+	case reflect.Bool:
+		if v.Len() == 0 {
+			return false
+		}
+		for _, key := range v.MapKeys() {
+			if !v.MapIndex(key).Bool() {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Write a map's type: `key_type:value_type`, or `set[key_type]` for sets.
+func (this *describer) writeMapType(v reflect.Value) {
+	if isSetLikeMap(v) {
+		this.writeString(tokSetPrefix)
+		this.writeString(tokOpenArray)
+		this.writeTypeName(v.Type().Key())
+		this.writeString(tokCloseArray)
+		return
+	}
+	this.writeTypeName(v.Type().Key())
+	this.writeString(tokMapTypeSeparator)
+	this.writeTypeName(v.Type().Elem())
+}
+
+func (this *describer) describeSet(v reflect.Value) {
+	this.writeMapType(v)
+	this.writeString(tokOpenMap)
+	this.increaseIndent()
+	isFirst := true
+	iter, omittedCount := this.iterateMap(v)
+	for iter.Next() && !this.isOutputFull() {
+		this.writeItemSeparator(isFirst)
+		isFirst = false
+		key := iter.Key()
+		this.describeChild(keySegment(key), key, false)
+	}
+	this.writeOmittedEntries(omittedCount, isFirst)
+	this.decreaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString(tokCloseMap)
+}
+
+func unwrapInterface(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// Get the number of elements to describe out of length (see
+// WithMaxElements()).
+func (this *describer) getElementCountToDescribe(length int) int {
+	if this.maxElements > 0 && length > this.maxElements {
+		return this.maxElements
+	}
+	return length
+}
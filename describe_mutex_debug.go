@@ -0,0 +1,101 @@
+//go:build describedebug
+// +build describedebug
+
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+func init() {
+	SetCustomDescriber(reflect.TypeOf(Mutex{}), describeMutex)
+	SetCustomDescriber(reflect.TypeOf(RWMutex{}), describeMutex)
+}
+
+// A sync.Mutex replacement that records which goroutine currently holds it, so
+// that describe output can report it. Example:
+// `describe.Mutex<held by goroutine 12>`
+//
+// Holder tracking is only compiled in with `-tags describedebug`. TryLock() is
+// available from Go 1.18, as it is for sync.Mutex.
+type Mutex struct {
+	holder int64 // Must be first for 64-bit alignment on 32-bit platforms
+	mutex  sync.Mutex
+}
+
+func (this *Mutex) Lock() {
+	this.mutex.Lock()
+	atomic.StoreInt64(&this.holder, int64(getGoroutineID()))
+}
+
+func (this *Mutex) Unlock() {
+	atomic.StoreInt64(&this.holder, 0)
+	this.mutex.Unlock()
+}
+
+// A sync.RWMutex replacement that records which goroutine currently holds the
+// write lock, so that describe output can report it.
+//
+// Holder tracking is only compiled in with `-tags describedebug`. TryLock() and
+// TryRLock() are available from Go 1.18, as they are for sync.RWMutex.
+type RWMutex struct {
+	holder int64 // Must be first for 64-bit alignment on 32-bit platforms
+	mutex  sync.RWMutex
+}
+
+func (this *RWMutex) Lock() {
+	this.mutex.Lock()
+	atomic.StoreInt64(&this.holder, int64(getGoroutineID()))
+}
+
+func (this *RWMutex) Unlock() {
+	atomic.StoreInt64(&this.holder, 0)
+	this.mutex.Unlock()
+}
+
+func (this *RWMutex) RLock() {
+	this.mutex.RLock()
+}
+
+func (this *RWMutex) RUnlock() {
+	this.mutex.RUnlock()
+}
+
+func (this *RWMutex) RLocker() sync.Locker {
+	return this.mutex.RLocker()
+}
+
+type holderLoader interface {
+	loadHolder() int64
+}
+
+func (this *Mutex) loadHolder() int64 {
+	return atomic.LoadInt64(&this.holder)
+}
+
+func (this *RWMutex) loadHolder() int64 {
+	return atomic.LoadInt64(&this.holder)
+}
+
+func describeMutex(v reflect.Value) string {
+	// The holder is written atomically by a goroutine that may still be running
+	// while we describe, so it must be read atomically too, which can only be
+	// done via the mutex itself. A value that can't be addressed is a copy, and
+	// can be read as is.
+	var holder int64
+	switch {
+	case !v.CanAddr():
+		holder = v.FieldByName("holder").Int()
+	case v.CanInterface():
+		holder = v.Addr().Interface().(holderLoader).loadHolder()
+	default:
+		return fmt.Sprintf("%v%vholder unknown%v", getTypeName(v.Type()), tokOpenStruct, tokCloseStruct)
+	}
+	if holder == 0 {
+		return fmt.Sprintf("%v%vunlocked%v", getTypeName(v.Type()), tokOpenStruct, tokCloseStruct)
+	}
+	return fmt.Sprintf("%v%vheld by goroutine %v%v", getTypeName(v.Type()), tokOpenStruct, holder, tokCloseStruct)
+}
@@ -0,0 +1,28 @@
+//go:build describedebug && go1.18
+// +build describedebug,go1.18
+
+package describe
+
+import (
+	"sync/atomic"
+)
+
+func (this *Mutex) TryLock() bool {
+	if !this.mutex.TryLock() {
+		return false
+	}
+	atomic.StoreInt64(&this.holder, int64(getGoroutineID()))
+	return true
+}
+
+func (this *RWMutex) TryLock() bool {
+	if !this.mutex.TryLock() {
+		return false
+	}
+	atomic.StoreInt64(&this.holder, int64(getGoroutineID()))
+	return true
+}
+
+func (this *RWMutex) TryRLock() bool {
+	return this.mutex.TryRLock()
+}
@@ -0,0 +1,30 @@
+//go:build describedebug
+// +build describedebug
+
+package describe
+
+import (
+	"fmt"
+	"testing"
+)
+
+type StructWithMutexes struct {
+	Mutex   Mutex
+	rwMutex RWMutex
+}
+
+func TestMutexHolder(t *testing.T) {
+	v := &StructWithMutexes{}
+	v.Mutex.Lock()
+	defer v.Mutex.Unlock()
+
+	expected := fmt.Sprintf("*describe.StructWithMutexes<Mutex=describe.Mutex<held by goroutine %v> rwMutex=describe.RWMutex<unlocked>>", getGoroutineID())
+	if !canExposeInterface() {
+		// Unexported mutexes can't be read atomically
+		expected = fmt.Sprintf("*describe.StructWithMutexes<Mutex=describe.Mutex<held by goroutine %v> rwMutex=describe.RWMutex<holder unknown>>", getGoroutineID())
+	}
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
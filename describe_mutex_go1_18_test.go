@@ -0,0 +1,36 @@
+//go:build go1.18
+// +build go1.18
+
+package describe
+
+import (
+	"testing"
+)
+
+type StructWithTryLocks struct {
+	Mutex   Mutex
+	RWMutex RWMutex
+}
+
+func TestMutexTryLock(t *testing.T) {
+	v := &StructWithTryLocks{}
+	if !v.Mutex.TryLock() {
+		t.Errorf("Expected TryLock to succeed on an unlocked Mutex")
+	}
+	if v.Mutex.TryLock() {
+		t.Errorf("Expected TryLock to fail on a locked Mutex")
+	}
+	v.Mutex.Unlock()
+
+	if !v.RWMutex.TryRLock() {
+		t.Errorf("Expected TryRLock to succeed on an unlocked RWMutex")
+	}
+	if v.RWMutex.TryLock() {
+		t.Errorf("Expected TryLock to fail on a read-locked RWMutex")
+	}
+	v.RWMutex.RUnlock()
+	if !v.RWMutex.TryLock() {
+		t.Errorf("Expected TryLock to succeed on an unlocked RWMutex")
+	}
+	v.RWMutex.Unlock()
+}
@@ -0,0 +1,28 @@
+//go:build !describedebug
+// +build !describedebug
+
+package describe
+
+import (
+	"sync"
+)
+
+// A sync.Mutex replacement that records which goroutine currently holds it, so
+// that describe output can report it. Example:
+// `describe.Mutex<held by goroutine 12>`
+//
+// Holder tracking is only compiled in with `-tags describedebug`. Otherwise,
+// this is a plain sync.Mutex (including TryLock() from Go 1.18).
+type Mutex struct {
+	sync.Mutex
+}
+
+// A sync.RWMutex replacement that records which goroutine currently holds the
+// write lock, so that describe output can report it.
+//
+// Holder tracking is only compiled in with `-tags describedebug`. Otherwise,
+// this is a plain sync.RWMutex (including TryLock() and TryRLock() from Go
+// 1.18).
+type RWMutex struct {
+	sync.RWMutex
+}
@@ -0,0 +1,83 @@
+package describe
+
+import (
+	"regexp"
+	"strconv"
+)
+
+const (
+	normalizedAddress   = "0xADDRESS"
+	normalizedTimestamp = "TIMESTAMP"
+)
+
+var (
+	addressPattern   = regexp.MustCompile(`^0x[0-9a-f]{8}([0-9a-f]{8})?$`)
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	referenceMarker  = regexp.MustCompile(`(\d+)~`)
+	referencePattern = regexp.MustCompile(`^\$(\d+)`)
+)
+
+// Array types whose elements are printed as fixed-width hex, and so can look
+// like addresses.
+var hexArrayTypes = map[string]bool{
+	"uint":   true,
+	"uint32": true,
+	"uint64": true,
+}
+
+// Normalize strips the volatile parts of a description so that descriptions
+// of the same data taken in different processes (or at different times) can
+// be compared directly:
+//
+//   - Addresses are replaced with 0xADDRESS
+//   - Reference IDs are renumbered in order of appearance
+//   - Timestamps (including the contents of time.Time values) are replaced
+//     with TIMESTAMP
+func Normalize(description string) string {
+	root := parseDescription(description)
+	references := make(map[string]string)
+	renumber := func(id string) string {
+		if renumbered, ok := references[id]; ok {
+			return renumbered
+		}
+		renumbered := strconv.Itoa(len(references) + 1)
+		references[id] = renumbered
+		return renumbered
+	}
+
+	var normalize func(node *parsedNode, parent *parsedNode)
+	normalize = func(node *parsedNode, parent *parsedNode) {
+		node.prefix = referenceMarker.ReplaceAllStringFunc(node.prefix, func(marker string) string {
+			return renumber(marker[:len(marker)-1]) + tokReferenceSeparator
+		})
+
+		switch {
+		case referencePattern.MatchString(node.head) && !node.isContainer():
+			// References may be followed by a label: `$1("alice")`
+			id := referencePattern.FindStringSubmatch(node.head)[1]
+			node.head = tokReferencePrefix + renumber(id) + node.head[len(tokReferencePrefix)+len(id):]
+		case addressPattern.MatchString(node.head) && !isHexArray(parent):
+			node.head = normalizedAddress
+		default:
+			node.head = timestampPattern.ReplaceAllString(node.head, normalizedTimestamp)
+		}
+
+		if node.head == getTypeName(timeType) && node.open == tokOpenStruct {
+			node.items = []*parsedItem{{value: &parsedNode{head: normalizedTimestamp}}}
+			node.trailing = ""
+			return
+		}
+		for _, item := range node.items {
+			if item.key != nil {
+				normalize(item.key, node)
+			}
+			normalize(item.value, node)
+		}
+	}
+	normalize(root, nil)
+	return root.String()
+}
+
+func isHexArray(node *parsedNode) bool {
+	return node != nil && node.open == tokOpenArray && hexArrayTypes[node.head]
+}
@@ -0,0 +1,791 @@
+package describe
+
+import (
+	"reflect"
+	"time"
+)
+
+// An option that changes how DescribeOpts describes an object. Options are
+// created using the With...() functions.
+type Option func(*options)
+
+type options struct {
+	indentStep  int
+	baseIndent  int
+	annotator   Annotator
+	annotations AnnotationStyle
+	sizeCodec   string
+	sizer       EncodedSizer
+	timeLayout  string
+	timeDetails bool
+	typeNames   TypeNameStyle
+	typeHashes  bool
+	groupFields bool
+
+	typeDepthLimits  map[reflect.Type]int
+	validRanges      map[reflect.Type]validRange
+	validFieldRanges map[fieldKey]validRange
+	describerTimeout time.Duration
+	sortMapKeys      bool
+
+	limitDepth         bool
+	maxDepth           int
+	breadthFirstBudget int
+	rootType           reflect.Type
+
+	errorStackFrames             int
+	hideUnexportedFromDescribers bool
+	index                        *DescriptionIndex
+
+	sortSlices      bool
+	sliceComparator Comparator
+
+	reflectionOnly   bool
+	stringerFallback bool
+	goStringers      bool
+	errorChains      bool
+
+	mapSampleSize int
+	mapSampling   MapSampling
+	mapContents   MapContents
+	quoting       StringQuoting
+	maxElements   int
+
+	hexDumpBytesPerLine int
+	maxStringLength     int
+	maxOutputBytes      int
+	appendChecksum      bool
+	compactThreshold    int
+	colors              ColorMode
+
+	customDescribers       map[reflect.Type]ContextDescriber
+	counterpartDescribers  map[reflect.Type]ContextDescriber
+	fieldDescribers        map[fieldKey]ContextDescriber
+	redactionPatterns      []string
+	interfaceDescribers    *interfaceDescriberRegistry
+	kindDescribers         map[reflect.Kind]ContextDescriber
+	ignoreGlobalDescribers bool
+	disableUnsafe          bool
+	hasChannelLengths      bool
+	showChannelLengths     bool
+}
+
+func (this *options) applyOptions(opts []Option) {
+	for _, opt := range opts {
+		opt(this)
+	}
+}
+
+// An options struct can be copied and shared (for example by a Describer), so
+// options that add to one of its maps or slices never modify it in place: they
+// copy it, add to the copy, and replace the original. The copy...() functions
+// make room for one more entry.
+
+func copyTypeDescribers(m map[reflect.Type]ContextDescriber) map[reflect.Type]ContextDescriber {
+	copied := make(map[reflect.Type]ContextDescriber, len(m)+1)
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+func copyFieldDescribers(m map[fieldKey]ContextDescriber) map[fieldKey]ContextDescriber {
+	copied := make(map[fieldKey]ContextDescriber, len(m)+1)
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+func copyKindDescribers(m map[reflect.Kind]ContextDescriber) map[reflect.Kind]ContextDescriber {
+	copied := make(map[reflect.Kind]ContextDescriber, len(m)+1)
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+func copyTypeRanges(m map[reflect.Type]validRange) map[reflect.Type]validRange {
+	copied := make(map[reflect.Type]validRange, len(m)+1)
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+func copyFieldRanges(m map[fieldKey]validRange) map[fieldKey]validRange {
+	copied := make(map[fieldKey]validRange, len(m)+1)
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+func copyTypeLimits(m map[reflect.Type]int) map[reflect.Type]int {
+	copied := make(map[reflect.Type]int, len(m)+1)
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+// Print in multiline mode, indenting indentStep spaces when entering a
+// struct/map/array/slice. An indentStep of 0 prints everything on one line.
+func WithIndent(indentStep int) Option {
+	return func(o *options) {
+		o.indentStep = indentStep
+	}
+}
+
+// In multiline mode, indent every line (including the first) by an extra
+// baseIndent spaces, so that a description can be embedded under an existing
+// log record header without re-indenting it:
+//
+//	request failed:
+//	    main.Request<
+//	        Path = "/"
+//	    >
+//
+// Has no effect on single-line descriptions.
+func WithBaseIndent(baseIndent int) Option {
+	return func(o *options) {
+		o.baseIndent = baseIndent
+	}
+}
+
+// User-defined callback that returns an annotation for the value at path, or
+// an empty string for no annotation. See WithAnnotator().
+type Annotator func(path string, v reflect.Value) string
+
+// Append the annotation returned by annotator to each value, enclosed in `[]`.
+// This is useful for explaining where values came from. Example:
+// `Price=42 [from env PRICE]`
+//
+// The annotator is called for the top-level value (with an empty path), and for
+// every struct field, array/slice element, and map value. Paths are built
+// like Go expressions: `Config.Servers[2].Ports["http"]`
+func WithAnnotator(annotator Annotator) Option {
+	return func(o *options) {
+		o.annotator = annotator
+	}
+}
+
+// How to enclose annotations. See WithAnnotationStyle().
+type AnnotationStyle int
+
+const (
+	// Enclose annotations in square brackets: `State=3 [stale]`
+	AnnotateBrackets AnnotationStyle = iota
+	// Enclose annotations in comments: `State=3 /* stale, >5m old */`
+	AnnotateComments
+)
+
+// Set how annotations from WithAnnotator() are enclosed. Comments stand out
+// more from the data, which suits triage hints computed by the application's
+// own heuristics (`State=3 /* stale, >5m old */`).
+func WithAnnotationStyle(style AnnotationStyle) Option {
+	return func(o *options) {
+		o.annotations = style
+	}
+}
+
+// Annotate each value with its size when encoded by a codec, so that a
+// description doubles as a breakdown of where an encoded payload's bytes come
+// from: `Body="…" [json: 1048576 bytes]`
+//
+// codec names the codec in annotations, and sizer computes the sizes. Sizers
+// for encoding/json and encoding/gob are provided (JSONSize and GobSize), and
+// sizers for other codecs (such as protobuf) are easily written. Values that
+// sizer can't size (such as unexported fields, for the provided sizers) aren't
+// annotated.
+//
+// Sizes are annotated alongside annotations from WithAnnotator(). Every value
+// is encoded separately, so this is slow for large or deep data. Nothing is
+// sized in reflection-only mode (see WithReflectionOnly()), since codecs call
+// user code.
+func WithEncodedSizes(codec string, sizer EncodedSizer) Option {
+	return func(o *options) {
+		o.sizeCodec = codec
+		o.sizer = sizer
+	}
+}
+
+// Describe time.Time values using a fixed layout (see time.Time.Format)
+// instead of time.Time.String(), which includes the monotonic clock reading
+// for times from time.Now(). Example: `WithTimeLayout(time.RFC3339Nano)`
+//
+// Time formatting is locale-independent, so this gives output that can be
+// compared across machines and regions.
+func WithTimeLayout(layout string) Option {
+	return func(o *options) {
+		o.timeLayout = layout
+	}
+}
+
+// Describe time.Time values with their location's name, and their monotonic
+// clock reading (or `none`), which matter when diagnosing timer and deadline
+// bugs where wall clock and monotonic behavior differ:
+//
+//	time.Time<2024-03-01 09:00:00 -0500 EST location=America/New_York monotonic=+0.001384552>
+//
+// The time itself is formatted using WithTimeLayout() if set, or otherwise as
+// time.Time.String() does (minus the monotonic clock reading).
+func WithTimeDetails(enabled bool) Option {
+	return func(o *options) {
+		o.timeDetails = enabled
+	}
+}
+
+// Describe errors as their type, Error() text, and the error(s) returned by
+// Unwrap() (as with fmt.Errorf() and errors.Join()), which are described the
+// same way, so that the whole chain is shown:
+//
+//	*fmt.wrapError<Error="load config: open app.yaml: not found" Unwrap=*fs.PathError<Error="open app.yaml: not found" Unwrap=...>>
+//
+// Errors whose Error() or Unwrap() methods panic are described by their
+// contents instead. Custom describers and WithErrorStacks() take precedence.
+func WithErrorChains(enabled bool) Option {
+	return func(o *options) {
+		o.errorChains = enabled
+	}
+}
+
+// Describe every value that has a String() method (and no custom describer)
+// as `TypeName<String()>`, including zero values. Normally, zero values are
+// described by their contents, since their String() methods (if any) often
+// expect them to be initialized first. Domain types such as IDs, money and
+// enums usually have String() methods that handle zero values well.
+//
+// This is like calling RegisterStringersFromValues() for every type. Panics in
+// String() methods are recovered from as usual.
+func WithStringerFallback(enabled bool) Option {
+	return func(o *options) {
+		o.stringerFallback = enabled
+	}
+}
+
+// Describe values that have a GoString() method (see fmt.GoStringer) but no
+// String() method as `TypeName<GoString()>`. Generated types (such as
+// protobuf messages) often have useful GoString() methods:
+// `pb.Point<&pb.Point{X: 1, Y: 2}>`
+//
+// As with String() methods, zero values are described by their contents unless
+// WithStringerFallback() is also enabled, and panics in GoString() methods are
+// recovered from as usual.
+func WithGoStringers(enabled bool) Option {
+	return func(o *options) {
+		o.goStringers = enabled
+	}
+}
+
+// Flag numeric values of type t that are outside of min to max (inclusive)
+// with a `!` prefix (in bold red when colored), turning descriptions of
+// telemetry structs into quick sanity reports: `Temperature=!412.5`
+//
+// See also WithValidFieldRange().
+func WithValidRange(t reflect.Type, min, max float64) Option {
+	return func(o *options) {
+		ranges := copyTypeRanges(o.validRanges)
+		ranges[t] = validRange{min: min, max: max}
+		o.validRanges = ranges
+	}
+}
+
+// Flag numeric values of the named field of struct type structType that are
+// outside of min to max (inclusive), like WithValidRange(). The range applies
+// to the field's value (following pointers and interfaces), but not to the
+// elements of arrays, slices or maps in the field.
+func WithValidFieldRange(structType reflect.Type, field string, min, max float64) Option {
+	return func(o *options) {
+		ranges := copyFieldRanges(o.validFieldRanges)
+		ranges[fieldKey{structType: structType, field: field}] = validRange{min: min, max: max}
+		o.validFieldRanges = ranges
+	}
+}
+
+// Mask the values of struct fields and map entries (with string keys) whose
+// names contain any of patterns with `***`, so that values such as requests and
+// configs can be described in production logs without leaking credentials:
+// `Password=***`, `"Authorization"=***`
+//
+// Case and punctuation are ignored, so `apikey` matches `APIKey`, `api_key`
+// and `X-Api-Key`. With no patterns, DefaultRedactionPatterns are used. Using
+// WithRedaction() more than once adds to the patterns.
+//
+// Fields can also be masked individually using a `describe:",redact"` tag.
+func WithRedaction(patterns ...string) Option {
+	if len(patterns) == 0 {
+		patterns = DefaultRedactionPatterns
+	}
+	var normalized []string
+	for _, pattern := range patterns {
+		if pattern = normalizeRedactionName(pattern); pattern != "" {
+			normalized = append(normalized, pattern)
+		}
+	}
+	return func(o *options) {
+		redactionPatterns := make([]string, 0, len(o.redactionPatterns)+len(normalized))
+		redactionPatterns = append(redactionPatterns, o.redactionPatterns...)
+		o.redactionPatterns = append(redactionPatterns, normalized...)
+	}
+}
+
+// Set how package names are shown in type names. See TypeNameStyle.
+func WithTypeNames(style TypeNameStyle) Option {
+	return func(o *options) {
+		o.typeNames = style
+	}
+}
+
+// Append a short hash of each named type's identity (its import path and
+// name) to its name, so that types with the same name from different packages
+// can be told apart when using TypeNamesPackage or TypeNamesAbbreviated:
+// `config.Config#5f3a9e` vs `config.Config#c1d07b`
+//
+// The hashes are the same across builds and runs, so they can be searched
+// for. They aren't added when using TypeNamesFull.
+func WithTypeHashes(enabled bool) Option {
+	return func(o *options) {
+		o.typeHashes = enabled
+	}
+}
+
+// In multiline mode, print struct fields that have a group tag (such as
+// `describe:"group=network"`) under a labeled section per group, after the
+// ungrouped fields:
+//
+//	config.Server<
+//	    Name = "main"
+//	    # network
+//	        Host = "example.com"
+//	        Port = 80
+//	>
+//
+// Groups are ordered by the first field that uses them. This option has no
+// effect in single line mode.
+func WithFieldGroups(enabled bool) Option {
+	return func(o *options) {
+		o.groupFields = enabled
+	}
+}
+
+// Limit how deeply values of type t can nest within each other. Once a value of
+// type t is nested within maxDepth other values of type t, it's printed in
+// summarized form, like `main.Tree<…>`.
+//
+// This is useful for limiting how much of a large recursive structure gets
+// printed, while still fully expanding everything else.
+func WithTypeDepth(t reflect.Type, maxDepth int) Option {
+	return func(o *options) {
+		limits := copyTypeLimits(o.typeDepthLimits)
+		limits[t] = maxDepth
+		o.typeDepthLimits = limits
+	}
+}
+
+// Run custom describers and String() methods with a wall-clock budget of
+// timeout. If one takes longer than that, its goroutine is abandoned and the
+// value is described as `typeName<describer timeout>`.
+//
+// This protects against describers that block (for example on network I/O),
+// at the cost of running each one in its own goroutine.
+func WithDescriberTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.describerTimeout = timeout
+	}
+}
+
+// Describe map entries (and set members) in sorted key order rather than Go's
+// randomized iteration order, so that descriptions are stable across runs and
+// can be compared or checked in tests.
+//
+// Keys are sorted numerically for numbers, lexically for strings, and by their
+// descriptions for everything else.
+func WithSortedMapKeys(enabled bool) Option {
+	return func(o *options) {
+		o.sortMapKeys = enabled
+	}
+}
+
+// Expand the description breadth-first within a budget of maxBytes: every
+// value at one level of nesting is described before anything at the next
+// level is, and the description is expanded level by level until the next
+// level would no longer fit. Containers that didn't fit are summarized, like
+// `main.Config<…>`.
+//
+// This gives a summary of every top-level field rather than spending the whole
+// budget on the first one.
+func WithBreadthFirstBudget(maxBytes int) Option {
+	return func(o *options) {
+		o.breadthFirstBudget = maxBytes
+	}
+}
+
+// Stop descending after maxDepth levels of nesting. Containers (structs, maps,
+// arrays and slices) nested deeper than that are printed in summarized form,
+// like `main.Config<…>`. A maxDepth of 0 summarizes the top-level value.
+//
+// This is useful for seeing the top few levels of deeply nested trees.
+func WithMaxDepth(maxDepth int) Option {
+	return func(o *options) {
+		o.limitDepth = true
+		o.maxDepth = maxDepth
+	}
+}
+
+// Skip ahead through any wrapper objects to the first occurrence(s) of type t,
+// and describe from there. This is useful when the interesting object is
+// buried inside layers of framework wrappers.
+//
+// If more than one occurrence is found (not counting those inside other
+// occurrences), they're described as a list: `main.Session[...]`
+//
+// If no occurrences are found, the entire object is described.
+func WithRootType(t reflect.Type) Option {
+	return func(o *options) {
+		o.rootType = t
+	}
+}
+
+// Describe errors that carry a call stack (pkg/errors style: a StackTrace()
+// method or a stack field holding program counters) as their message plus the
+// top maxFrames frames of the stack, rather than as their raw contents:
+// `*errors.fundamental<Error="boom" Stack=[app.handler(handler.go:42) …]>`
+//
+// A maxFrames of 0 disables this (the default).
+func WithErrorStacks(maxFrames int) Option {
+	return func(o *options) {
+		o.errorStackFrames = maxFrames
+	}
+}
+
+// Apply custom describers to values in unexported fields (enabled by default).
+// Such values can't normally be used via Interface(), so they are exposed
+// (using unsafe) before being passed to the describer.
+//
+// In builds where unsafe operations aren't available, such values are passed as
+// is, so the describer can't call Interface() on them. When disabled, values
+// in unexported fields are described by their contents instead.
+func WithUnexportedDescribers(enabled bool) Option {
+	return func(o *options) {
+		o.hideUnexportedFromDescribers = !enabled
+	}
+}
+
+// Fill index with the locations of reference definitions and values within
+// the description, replacing its previous contents.
+func WithIndex(index *DescriptionIndex) Option {
+	return func(o *options) {
+		o.index = index
+	}
+}
+
+// User-defined comparator that returns true if a should be ordered before b.
+// See WithSortedSlices().
+type Comparator func(a, b reflect.Value) bool
+
+// Describe slice elements in sorted order, for stable output when element order
+// is nondeterministic (such as results gathered from goroutines). Elements are
+// ordered using less, or if less is nil, numerically for numbers, lexically
+// for strings, and by their descriptions for everything else.
+//
+// Paths (see WithAnnotator) still refer to the elements' original indices.
+func WithSortedSlices(less Comparator) Option {
+	return func(o *options) {
+		o.sortSlices = true
+		o.sliceComparator = less
+	}
+}
+
+// Describe values using reflection only, guaranteeing that no user code is
+// called: String() and Error() methods, custom describers (including the
+// built-in big.Float describers), and the self-describing interfaces (see
+// DescribableAsMap etc) are all ignored, and values are described by their
+// raw contents instead.
+//
+// This is for describing objects where calling their methods is dangerous,
+// such as in crash handlers, where reentrancy or partially initialized state
+// could cause further failures. Callbacks passed in options (such as
+// annotators and comparators) are still called.
+func WithReflectionOnly(enabled bool) Option {
+	return func(o *options) {
+		o.reflectionOnly = enabled
+	}
+}
+
+// How WithMapSampling() chooses which entries of a large map to describe.
+type MapSampling int
+
+const (
+	// Describe the first entries in sorted key order (numerically for numbers,
+	// lexically for strings, and by description for everything else).
+	MapSampleFirstSorted MapSampling = iota
+	// Describe randomly chosen entries (in sorted key order).
+	MapSampleRandom
+)
+
+// Describe at most maxEntries entries of maps that have more than that,
+// followed by the number of entries that were left out:
+// `string:int{"a"=1 "b"=2 …(+98765 more entries)}`
+//
+// Since map order is arbitrary, the entries to describe are chosen using
+// sampling rather than by taking whichever come first.
+func WithMapSampling(maxEntries int, sampling MapSampling) Option {
+	return func(o *options) {
+		o.mapSampleSize = maxEntries
+		o.mapSampling = sampling
+	}
+}
+
+// Which parts of map entries to describe. See WithMapContents().
+type MapContents int
+
+const (
+	MapKeysAndValues MapContents = iota
+	// Describe only the keys, like a set: `string:*app.Handler{"a" "b"}`
+	MapKeysOnly
+	// Describe only the values: `string:int{1 2}`
+	MapValuesOnly
+)
+
+// Describe only the keys or only the values of maps. This is useful for cases
+// like registries, where the keys alone answer the question and the values
+// are huge. Maps used as sets (described as `set[T]{...}`) are unaffected.
+func WithMapContents(contents MapContents) Option {
+	return func(o *options) {
+		o.mapContents = contents
+	}
+}
+
+// Use describer for values of type t, overriding any describer set using
+// SetCustomDescriber(). A nil describer disables custom describing of t.
+//
+// This is mainly for configuring a Describer, so that libraries sharing a
+// binary don't interfere with each other's describers.
+func WithCustomDescriber(t reflect.Type, describer CustomDescriber) Option {
+	return WithContextDescriber(t, contextDescriberOf(describer))
+}
+
+// Use a context describer for values of type t, overriding any describer set
+// using SetCustomDescriber() or SetContextDescriber(). A nil describer disables
+// custom describing of t.
+func WithContextDescriber(t reflect.Type, describer ContextDescriber) Option {
+	return func(o *options) {
+		describers := copyTypeDescribers(o.customDescribers)
+		describers[t] = describer
+		o.customDescribers = describers
+	}
+}
+
+// Use describer for values of type t as with WithCustomDescriber(), and with
+// MatchPointersAndValues, also for values of t's pointer/value counterpart. See
+// SetCustomDescriberMatching().
+func WithCustomDescriberMatching(t reflect.Type, describer CustomDescriber, matching DescriberMatching) Option {
+	withDescriber := WithCustomDescriber(t, describer)
+	counterpart, adapted := adaptToCounterpart(t, contextDescriberOf(describer))
+	return func(o *options) {
+		withDescriber(o)
+		describers := copyTypeDescribers(o.counterpartDescribers)
+		if matching == MatchExactType {
+			delete(describers, counterpart)
+		} else {
+			describers[counterpart] = adapted
+		}
+		o.counterpartDescribers = describers
+	}
+}
+
+// Use describer for the named field of struct type structType, overriding any
+// describer set for that field using SetFieldDescriber(). A nil describer
+// disables describing the field with a field describer. See
+// SetFieldDescriber().
+//
+// Panics if structType isn't a struct type, or has no such field.
+func WithFieldDescriber(structType reflect.Type, field string, describer CustomDescriber) Option {
+	checkDescribedField("WithFieldDescriber", structType, field)
+	return func(o *options) {
+		describers := copyFieldDescribers(o.fieldDescribers)
+		describers[fieldKey{structType: structType, field: field}] = contextDescriberOf(describer)
+		o.fieldDescribers = describers
+	}
+}
+
+// Use describer for all types that implement iface, overriding any describer
+// set for iface using SetCustomDescriberForInterface(). A nil describer
+// disables custom describing of types that implement iface (other than by
+// concrete type). See SetCustomDescriberForInterface().
+//
+// Panics if iface isn't an interface type.
+func WithCustomDescriberForInterface(iface reflect.Type, describer CustomDescriber) Option {
+	iface = getDescribedInterface("WithCustomDescriberForInterface", iface)
+	return func(o *options) {
+		o.interfaceDescribers = o.interfaceDescribers.with(iface, contextDescriberOf(describer))
+	}
+}
+
+// Use describer for all types of a kind, overriding any describer set for kind
+// using SetCustomDescriberForKind(). A nil describer disables custom
+// describing by kind. See SetCustomDescriberForKind().
+//
+// Panics if kind is reflect.Interface or reflect.Invalid.
+func WithCustomDescriberForKind(kind reflect.Kind, describer CustomDescriber) Option {
+	checkDescribedKind("WithCustomDescriberForKind", kind)
+	return func(o *options) {
+		describers := copyKindDescribers(o.kindDescribers)
+		describers[kind] = contextDescriberOf(describer)
+		o.kindDescribers = describers
+	}
+}
+
+// Use the custom describers set using SetCustomDescriber(),
+// SetCustomDescriberForInterface() and SetCustomDescriberForKind() (enabled by
+// default). When disabled, only those set using WithCustomDescriber(),
+// WithCustomDescriberForInterface() and WithCustomDescriberForKind() are used.
+//
+// Note: This also disables the built-in describers (such as for big.Float).
+func WithGlobalDescribers(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreGlobalDescribers = !enabled
+	}
+}
+
+// Allow unsafe operations (see EnableUnsafeOperations). Disabling them here
+// only affects descriptions made with this option; enabling them has no effect
+// if EnableUnsafeOperations is false or unsafe operations aren't available.
+func WithUnsafeOperations(enabled bool) Option {
+	return func(o *options) {
+		o.disableUnsafe = !enabled
+	}
+}
+
+// Report the queued element count and capacity of buffered channels (see
+// EnableChannelLengths). This overrides EnableChannelLengths.
+func WithChannelLengths(enabled bool) Option {
+	return func(o *options) {
+		o.hasChannelLengths = true
+		o.showChannelLengths = enabled
+	}
+}
+
+// How to quote strings. See WithStringQuoting().
+type StringQuoting int
+
+const (
+	// Enclose strings in double quotes: `"abc"`
+	QuoteDouble StringQuoting = iota
+	// Enclose strings in single quotes: `'abc'`
+	QuoteSingle
+	// Enclose strings in double quotes, or in backticks if they contain double
+	// quotes (and no backticks).
+	QuoteBackticksIfNeeded
+	// Leave identifier-like strings (letters, digits and underscores, not
+	// starting with a digit) unquoted, and quote other strings like
+	// QuoteBackticksIfNeeded.
+	QuoteNonIdentifiers
+)
+
+// Set how strings are quoted, for pipelines where double quotes conflict with
+// downstream shells or log processors. String contents are never escaped.
+//
+// Note: Normalize() and DiffDescriptions() expect double quoted strings.
+func WithStringQuoting(quoting StringQuoting) Option {
+	return func(o *options) {
+		o.quoting = quoting
+	}
+}
+
+// Describe only the first maxElements elements of arrays and slices (and
+// maxElements entries of maps), followed by the number that were left out:
+// `int[1 2 3 …(+9963 more elements)]`
+//
+// Maps are sampled as per WithMapSampling() using MapSampleFirstSorted, unless
+// WithMapSampling() is also used.
+func WithMaxElements(maxElements int) Option {
+	return func(o *options) {
+		o.maxElements = maxElements
+	}
+}
+
+// In multiline mode (see WithIndent()), describe byte arrays and slices longer
+// than bytesPerLine as a hex dump with offsets, for correlating with external
+// hex dumps and wire captures:
+//
+//	uint8[
+//	    0000: ff 80 44 01 00 00 00 00 00 00 00 00 00 00 00 00
+//	    0010: 7f 12
+//	]
+//
+// A bytesPerLine of 0 disables hex dumps.
+func WithHexDump(bytesPerLine int) Option {
+	return func(o *options) {
+		o.hexDumpBytesPerLine = bytesPerLine
+	}
+}
+
+// Truncate strings longer than maxRunes characters, appending an ellipsis and
+// the original length in bytes: `"PCFET0NUWVBF…"(len=48231)`
+//
+// A maxRunes of 0 disables truncation.
+func WithMaxStringLength(maxRunes int) Option {
+	return func(o *options) {
+		o.maxStringLength = maxRunes
+	}
+}
+
+// Stop describing once the description exceeds maxBytes, truncating it to
+// maxBytes (without splitting a UTF-8 character) followed by `…`. This puts a
+// hard cap on the size of descriptions of pathological objects, such as in hot
+// error paths.
+//
+// Unlike WithBreadthFirstBudget(), this only takes one pass, and simply cuts
+// the description off wherever the budget runs out.
+//
+// A maxBytes of 0 disables the limit.
+func WithMaxOutputBytes(maxBytes int) Option {
+	return func(o *options) {
+		o.maxOutputBytes = maxBytes
+	}
+}
+
+// Append a checksum of the description: `int[1 2 3] #crc32:de28fa62`
+//
+// When descriptions are transported through lossy channels (such as log
+// shippers that truncate long lines), VerifyChecksum() can then detect whether
+// a description was cut off or mangled along the way.
+func WithChecksum(enabled bool) Option {
+	return func(o *options) {
+		o.appendChecksum = enabled
+	}
+}
+
+// Describe small values via a fast path that builds the description in a
+// stack buffer. This is meant for high-frequency tracing, where values are
+// described on every call: with a Describer (see NewDescriber()), the returned
+// string is the only allocation.
+//
+// The fast path is taken for bools, numbers, strings, and structs made up only
+// of those (but not for types with methods, or types with custom describers),
+// when the description is guaranteed to be at most maxBytes long. Other values
+// are described as usual. The fast path is also skipped when other options
+// (such as WithIndent(), WithAnnotator() or WithColors()) would change the
+// description.
+//
+// maxBytes is capped at 256. A maxBytes of 0 disables the fast path.
+func WithCompactThreshold(maxBytes int) Option {
+	return func(o *options) {
+		o.compactThreshold = maxBytes
+	}
+}
+
+// Color type names, field names, strings, numbers, nil and reference markers
+// using ANSI escape codes, for easier reading in a terminal. Use StripColors()
+// to remove the colors again.
+//
+// With ColorsIfTerminal, descriptions written via DescribeTo() are colored
+// only if the writer is a terminal. Descriptions returned as strings are never
+// colored in this mode, since where they end up isn't known.
+func WithColors(mode ColorMode) Option {
+	return func(o *options) {
+		o.colors = mode
+	}
+}
@@ -0,0 +1,271 @@
+package describe
+
+import (
+	"bytes"
+	"strings"
+)
+
+// A node in a parsed description.
+//
+// The description notation isn't fully unambiguous (strings and custom
+// describer output are printed verbatim), so parsing is best-effort. It is
+// however lossless: rendering a parsed description always reproduces the
+// original text exactly, so tools built on the parser never lose information.
+type parsedNode struct {
+	// Pointer, interface, and reference markers. Example: `*1~`
+	prefix string
+	// Type name, scalar value, or reference (`$1`)
+	head  string
+	open  string
+	items []*parsedItem
+	// Whitespace between the last item and the closing bracket
+	trailing string
+	close    string
+}
+
+// An item within a container: either a value, or a key (field name or map
+// key) and value.
+type parsedItem struct {
+	// Whitespace before the item
+	before    string
+	key       *parsedNode
+	separator string
+	value     *parsedNode
+}
+
+var closingBrackets = map[string]string{
+	tokOpenArray:  tokCloseArray,
+	tokOpenMap:    tokCloseMap,
+	tokOpenStruct: tokCloseStruct,
+}
+
+func isParseWhitespace(ch byte) bool {
+	return ch == ' ' || ch == '\n' || ch == '\t' || ch == '\r'
+}
+
+func isParseCloser(ch byte) bool {
+	return ch == ']' || ch == '}' || ch == '>'
+}
+
+func isParseOpener(ch byte) bool {
+	return ch == '[' || ch == '{' || ch == '<'
+}
+
+// Returns true if the character at position ends a value.
+func isParseDelimiter(s string, position int) bool {
+	if position >= len(s) {
+		return true
+	}
+	ch := s[position]
+	return isParseWhitespace(ch) || isParseCloser(ch) || ch == '='
+}
+
+type descriptionParser struct {
+	text     string
+	position int
+}
+
+// Parse a description into a tree of nodes. The top-level node has no head or
+// brackets, and contains the top-level value (and anything following it) as
+// items.
+func parseDescription(description string) *parsedNode {
+	parser := descriptionParser{text: description}
+	root := &parsedNode{}
+	parser.parseItems(root, "")
+	return root
+}
+
+func (this *descriptionParser) isAtEnd() bool {
+	return this.position >= len(this.text)
+}
+
+func (this *descriptionParser) skipWhitespace() string {
+	start := this.position
+	for !this.isAtEnd() && isParseWhitespace(this.text[this.position]) {
+		this.position++
+	}
+	return this.text[start:this.position]
+}
+
+// Parse items into node until the closer (or the end of the text if closer is
+// empty) is reached.
+func (this *descriptionParser) parseItems(node *parsedNode, closer string) {
+	for {
+		whitespace := this.skipWhitespace()
+		if this.isAtEnd() {
+			node.trailing = whitespace
+			return
+		}
+		if closer != "" && strings.HasPrefix(this.text[this.position:], closer) {
+			node.trailing = whitespace
+			node.close = closer
+			this.position += len(closer)
+			return
+		}
+		if closer != "" && isParseCloser(this.text[this.position]) {
+			// Mismatched closer. Treat it as closing this container.
+			node.trailing = whitespace
+			node.close = this.text[this.position : this.position+1]
+			this.position++
+			return
+		}
+
+		item := &parsedItem{before: whitespace}
+		item.value = this.parseNode()
+
+		// Look for a key-value separator: `=`, ` = `, or ` =` + newline.
+		afterValue := this.position
+		this.skipWhitespace()
+		if !this.isAtEnd() && this.text[this.position] == '=' {
+			this.position++
+			this.skipWhitespace()
+			item.key = item.value
+			item.separator = this.text[afterValue:this.position]
+			item.value = this.parseNode()
+		} else {
+			this.position = afterValue
+		}
+		node.items = append(node.items, item)
+	}
+}
+
+func (this *descriptionParser) parseNode() *parsedNode {
+	node := &parsedNode{}
+	start := this.position
+
+	node.prefix = this.parsePrefix()
+
+	if !this.isAtEnd() && this.text[this.position] == '"' {
+		node.head = this.parseString()
+		return node
+	}
+
+	headStart := this.position
+	for !this.isAtEnd() {
+		ch := this.text[this.position]
+		if isParseWhitespace(ch) || isParseCloser(ch) || ch == '=' {
+			break
+		}
+		if ch == '(' {
+			this.skipParentheses()
+			continue
+		}
+		if isParseOpener(ch) {
+			opener := string(ch)
+			containerStart := this.position
+			container := &parsedNode{open: opener}
+			this.position++
+			this.parseItems(container, closingBrackets[opener])
+			if isParseDelimiter(this.text, this.position) {
+				// This is the node's container
+				node.head = this.text[headStart:containerStart]
+				node.open = container.open
+				node.items = container.items
+				node.trailing = container.trailing
+				node.close = container.close
+				return node
+			}
+			// Brackets within a type name, such as map[string]int
+			continue
+		}
+		this.position++
+	}
+	node.head = this.text[headStart:this.position]
+
+	if this.position == start && !this.isAtEnd() {
+		// Always make progress, even on malformed input.
+		this.position++
+		node.head = this.text[start:this.position]
+	}
+	return node
+}
+
+// Parse pointer, interface, and reference markers: `*`, `@`, `123~`
+func (this *descriptionParser) parsePrefix() string {
+	start := this.position
+	for !this.isAtEnd() {
+		ch := this.text[this.position]
+		if ch == '*' || ch == '@' {
+			this.position++
+			continue
+		}
+		end := this.position
+		for end < len(this.text) && this.text[end] >= '0' && this.text[end] <= '9' {
+			end++
+		}
+		if end > this.position && end < len(this.text) && this.text[end] == '~' {
+			this.position = end + 1
+			continue
+		}
+		break
+	}
+	return this.text[start:this.position]
+}
+
+// Strings aren't escaped, so the closing quote is taken to be the first quote
+// that's followed by something that can end a value.
+func (this *descriptionParser) parseString() string {
+	start := this.position
+	for end := start + 1; end < len(this.text); end++ {
+		if this.text[end] == '"' && isParseDelimiter(this.text, end+1) {
+			this.position = end + 1
+			return this.text[start:this.position]
+		}
+	}
+	this.position = len(this.text)
+	return this.text[start:]
+}
+
+func (this *descriptionParser) skipParentheses() {
+	depth := 0
+	for !this.isAtEnd() {
+		switch this.text[this.position] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		this.position++
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+func (this *parsedNode) render(buffer *bytes.Buffer) {
+	buffer.WriteString(this.prefix)
+	buffer.WriteString(this.head)
+	buffer.WriteString(this.open)
+	for _, item := range this.items {
+		buffer.WriteString(item.before)
+		if item.key != nil {
+			item.key.render(buffer)
+			buffer.WriteString(item.separator)
+		}
+		item.value.render(buffer)
+	}
+	buffer.WriteString(this.trailing)
+	buffer.WriteString(this.close)
+}
+
+func (this *parsedNode) String() string {
+	var buffer bytes.Buffer
+	this.render(&buffer)
+	return buffer.String()
+}
+
+func (this *parsedNode) isContainer() bool {
+	return this.open != ""
+}
+
+// Visit every node in depth-first order, keys before values.
+func (this *parsedNode) walk(visit func(node *parsedNode, parent *parsedNode)) {
+	for _, item := range this.items {
+		if item.key != nil {
+			visit(item.key, this)
+			item.key.walk(visit)
+		}
+		visit(item.value, this)
+		item.value.walk(visit)
+	}
+}
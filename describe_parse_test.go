@@ -0,0 +1,96 @@
+package describe
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func assertParseRoundTrip(t *testing.T, description string) {
+	actual := parseDescription(description).String()
+	if actual != description {
+		t.Errorf("Expected %v but got %v", description, actual)
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	urlVal, _ := url.Parse("http://example.com?a=b")
+	intVal := 1
+	v := OuterStruct{
+		AnInt: 4,
+		PInt:  &intVal,
+		Bytes: []byte{0xff, 0x80},
+		URL:   urlVal,
+		Time:  time.Date(2020, time.Month(1), 1, 1, 1, 1, 0, time.UTC),
+		AMap: map[interface{}]interface{}{
+			"str":   "bl\"ah>",
+			"inner": InnerStruct{number: 99},
+		},
+	}
+	recursive := RecursiveStruct{}
+	recursive.RecursivePtr = &recursive
+
+	for _, value := range []interface{}{
+		v,
+		recursive,
+		map[MapKeyStruct]MapKeyStruct{{1}: {2}},
+		[]map[string][]int{{"a": {1}}},
+		func(chan<- int) {},
+		make(chan int),
+		"",
+		nil,
+	} {
+		assertParseRoundTrip(t, Describe(value, 0))
+		assertParseRoundTrip(t, Describe(value, 4))
+	}
+
+	for _, description := range []string{"", " ", "]", "<<", "a=", `"unterminated`, "x[y"} {
+		assertParseRoundTrip(t, description)
+	}
+}
+
+func TestParseStructure(t *testing.T) {
+	root := parseDescription(`*1~describe.X<A="a b" B=int[1 $1] C=map[string]int:int{}>`)
+	if len(root.items) != 1 {
+		t.Fatalf("Expected 1 top-level item but got %v", len(root.items))
+	}
+	node := root.items[0].value
+	if node.prefix != "*1~" || node.head != "describe.X" || node.open != "<" || len(node.items) != 3 {
+		t.Fatalf("Unexpected node %+v", node)
+	}
+	if node.items[0].key.head != "A" || node.items[0].value.head != `"a b"` {
+		t.Errorf("Unexpected item %+v", node.items[0])
+	}
+	if len(node.items[1].value.items) != 2 || node.items[1].value.items[1].value.head != "$1" {
+		t.Errorf("Unexpected item %+v", node.items[1].value)
+	}
+	if node.items[2].value.head != "map[string]int:int" || node.items[2].value.open != "{" {
+		t.Errorf("Unexpected item %+v", node.items[2].value)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	first := `describe.X<A=3~@describe.Y<P=*0xc000012345678900> B=$3 C=time.Time<2020-01-01 01:01:01 +0000 UTC> D=0xc000aaaabbbb0000 E=uint64[0x00000000000000ff]>`
+	second := `describe.X<A=7~@describe.Y<P=*0xc000099999999900> B=$7 C=time.Time<2021-05-05 05:05:05 +0000 UTC> D=0xc000ccccdddd0000 E=uint64[0x00000000000000ff]>`
+	expected := `describe.X<A=1~@describe.Y<P=*0xADDRESS> B=$1 C=time.Time<TIMESTAMP> D=0xADDRESS E=uint64[0x00000000000000ff]>`
+	assertNormalized(t, first, expected)
+	assertNormalized(t, second, expected)
+}
+
+func TestNormalizeValues(t *testing.T) {
+	assertNormalized(t, `[a=0xc000012345678900 b="at 2020-01-01T01:01:01Z"]`, `[a=0xADDRESS b="at TIMESTAMP"]`)
+	assertNormalized(t, `uint32[0x12345678]`, `uint32[0x12345678]`)
+	assertNormalized(t, `uintptr[0x12345678]`, `uintptr[0xADDRESS]`)
+	assertNormalized(t, "5~[\n    $5\n    4~int[$4]\n]", "1~[\n    $1\n    2~int[$2]\n]")
+}
+
+func assertNormalized(t *testing.T, description string, expected string) {
+	actual := Normalize(description)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestNormalizeReferenceLabel(t *testing.T) {
+	assertNormalized(t, `[4~x<A=1> $4("alice")]`, `[1~x<A=1> $1("alice")]`)
+}
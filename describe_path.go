@@ -0,0 +1,100 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+type pathSegmentKind int
+
+const (
+	pathSegmentField pathSegmentKind = iota
+	pathSegmentIndex
+	pathSegmentKey
+)
+
+// One step in the path from the top-level value to the value being described.
+// Path strings are only built when needed, since most descriptions never
+// use them.
+type pathSegment struct {
+	kind  pathSegmentKind
+	name  string
+	index int
+	key   reflect.Value
+	// The struct type that a field belongs to, if known
+	owner reflect.Type
+	// The path up to and including this segment, once built
+	builtPath   string
+	isPathBuilt bool
+}
+
+// Identifies a field of a struct type, by name.
+type fieldKey struct {
+	structType reflect.Type
+	field      string
+}
+
+func fieldSegment(name string) pathSegment {
+	return pathSegment{kind: pathSegmentField, name: name}
+}
+
+func indexSegment(index int) pathSegment {
+	return pathSegment{kind: pathSegmentIndex, index: index}
+}
+
+func keySegment(key reflect.Value) pathSegment {
+	return pathSegment{kind: pathSegmentKey, key: key}
+}
+
+// Build the path string for segments, describing map keys using keyOptions.
+// Each segment remembers the path up to it, so that a path shared by many
+// values is only built (and its keys only described) once.
+func buildPath(segments []pathSegment, keyOptions *options) string {
+	start := len(segments)
+	for start > 0 && !segments[start-1].isPathBuilt {
+		start--
+	}
+	var buffer bytes.Buffer
+	if start > 0 {
+		buffer.WriteString(segments[start-1].builtPath)
+	}
+	for i := start; i < len(segments); i++ {
+		segment := &segments[i]
+		switch segment.kind {
+		case pathSegmentField:
+			if buffer.Len() > 0 {
+				buffer.WriteString(".")
+			}
+			buffer.WriteString(segment.name)
+		case pathSegmentIndex:
+			fmt.Fprintf(&buffer, "[%v]", segment.index)
+		case pathSegmentKey:
+			buffer.WriteString("[")
+			buffer.WriteString(keyOptions.describePathKey(segment.key))
+			buffer.WriteString("]")
+		}
+		segment.builtPath = buffer.String()
+		segment.isPathBuilt = true
+	}
+	return buffer.String()
+}
+
+// Describe a map key for a path, on a single line, and otherwise using these
+// options (so that user code is only called if it would be anyway).
+func (this *options) describePathKey(key reflect.Value) string {
+	context := describer{options: *this}
+	context.indentStep = 0
+	context.annotator = nil
+	context.sizer = nil
+	context.index = nil
+	context.breadthFirstBudget = 0
+	context.colors = ColorsNever
+	context.appendChecksum = false
+	context.maxOutputBytes = 0
+	return context.describeValue(key)
+}
+
+func (this *describer) currentPath() string {
+	return buildPath(this.path, &this.options)
+}
@@ -0,0 +1,57 @@
+package describe
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Examines a value for signs that it shouldn't be in use anymore, such as a
+// freed-marker sentinel, or fields that a pool zeroes when taking the object
+// back. Returns a non-empty reason if v is poisoned.
+//
+// v may have been obtained via unexported fields, so use reflection (rather
+// than v.Interface()) to examine it.
+type PoisonDetector func(v reflect.Value) (reason string)
+
+var poisonDetectors sync.Map
+
+// Set a detector for poisoned values of type t. Poisoned values are still
+// described as usual, but are prominently marked with the reason (in bold red
+// when colors are enabled):
+//
+//	Conn=*!POISONED(returned to pool) main.Conn<fd=-1 Owner=nil>
+//
+// This makes dumps stand out when a recycled object is still referenced
+// somewhere. t is matched exactly, so register main.Conn to mark the object
+// itself (as above), or *main.Conn to mark the pointers to it.
+//
+// Detectors are user code, and so are called with the same protections as
+// String() methods, and aren't called when using WithReflectionOnly().
+//
+// Passing a nil detector removes the detector for that type.
+func SetPoisonDetector(t reflect.Type, detector PoisonDetector) {
+	if detector == nil {
+		poisonDetectors.Delete(t)
+		return
+	}
+	poisonDetectors.Store(t, detector)
+}
+
+// Write a poison marker if v's detector reports it as poisoned.
+func (this *describer) markIfPoisoned(v reflect.Value) {
+	if !v.IsValid() || this.reflectionOnly {
+		return
+	}
+	detector, ok := poisonDetectors.Load(v.Type())
+	if !ok {
+		return
+	}
+
+	var reason string
+	if ok, _ = this.callUserCode(v.Type(), func() {
+		reason = detector.(PoisonDetector)(v)
+	}); !ok || reason == "" {
+		return
+	}
+	this.writeColored(colorOutOfRange, tokPoisoned+tokOpenPoisonReason+reason+tokClosePoisonReason)
+}
@@ -0,0 +1,77 @@
+package describe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type PooledConn struct {
+	fd    int
+	Owner *string
+}
+
+type PooledConns struct {
+	Active *PooledConn
+	Stale  *PooledConn
+}
+
+var pooledConnType = reflect.TypeOf(PooledConn{})
+
+func detectRecycledConn(v reflect.Value) string {
+	if v.FieldByName("fd").Int() < 0 {
+		return "returned to pool"
+	}
+	return ""
+}
+
+func TestPoisonDetector(t *testing.T) {
+	SetPoisonDetector(pooledConnType, detectRecycledConn)
+	defer SetPoisonDetector(pooledConnType, nil)
+
+	v := PooledConns{Active: &PooledConn{fd: 3}, Stale: &PooledConn{fd: -1}}
+	expected := `describe.PooledConns<Active=*describe.PooledConn<fd=3 Owner=nil> Stale=*!POISONED(returned to pool) describe.PooledConn<fd=-1 Owner=nil>>`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.PooledConns<Active=*describe.PooledConn<fd=3 Owner=nil> Stale=*describe.PooledConn<fd=-1 Owner=nil>>`
+	actual = DescribeOpts(v, WithReflectionOnly(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetPoisonDetector(pooledConnType, nil)
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestPoisonDetectorColors(t *testing.T) {
+	SetPoisonDetector(pooledConnType, detectRecycledConn)
+	defer SetPoisonDetector(pooledConnType, nil)
+
+	actual := DescribeOpts(PooledConn{fd: -1}, WithColors(ColorsAlways))
+	if !strings.Contains(actual, colorOutOfRange+"!POISONED(returned to pool) ") {
+		t.Errorf("Expected a colored poison marker but got %q", actual)
+	}
+}
+
+func TestPoisonDetectorPanic(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	SetPoisonDetector(pooledConnType, func(v reflect.Value) string {
+		panic("oops")
+	})
+	defer SetPoisonDetector(pooledConnType, nil)
+
+	expected := `describe.PooledConn<fd=-1 Owner=nil>`
+	actual := D(PooledConn{fd: -1})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
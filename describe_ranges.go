@@ -0,0 +1,49 @@
+package describe
+
+import (
+	"reflect"
+)
+
+type validRange struct {
+	min float64
+	max float64
+}
+
+// Returns true if v is a number outside of a range set using WithValidRange()
+// or WithValidFieldRange().
+func (this *describer) isOutOfRange(v reflect.Value) bool {
+	if len(this.validRanges) == 0 && len(this.validFieldRanges) == 0 {
+		return false
+	}
+
+	var value float64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	default:
+		return false
+	}
+
+	if r, ok := this.validRanges[v.Type()]; ok && !r.contains(value) {
+		return true
+	}
+	if len(this.path) > 0 {
+		segment := this.path[len(this.path)-1]
+		if segment.kind == pathSegmentField && segment.owner != nil {
+			key := fieldKey{structType: segment.owner, field: segment.name}
+			if r, ok := this.validFieldRanges[key]; ok && !r.contains(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NaN is never within range.
+func (this validRange) contains(value float64) bool {
+	return value >= this.min && value <= this.max
+}
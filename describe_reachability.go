@@ -0,0 +1,162 @@
+package describe
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	tokUnknownFields    = "?"
+	tokHiddenFields     = "hidden"
+	tokNotImplementedBy = "not implemented"
+)
+
+type accessorKey struct {
+	t      reflect.Type
+	method string
+}
+
+var accessors sync.Map
+
+// Register that method of type t exposes the given fields of t, for use by
+// ExposedFields(). This overrides the name-based guess for that method.
+// Passing no fields registers that the method exposes no fields.
+//
+// Note: t should be a struct type rather than a pointer type.
+func RegisterAccessor(t reflect.Type, method string, fields ...string) {
+	accessors.Store(accessorKey{t: t, method: method}, append([]string{}, fields...))
+}
+
+// Describes, for each of the given interfaces that v's type implements, which
+// of v's struct fields the interface's methods expose, and which are hidden
+// from anything that only sees v via that interface. Example:
+//
+//	*main.User<app.Named[ID()=id Name()=name hidden[email password]] io.Reader[not implemented]>
+//
+// Methods are matched to fields registered via RegisterAccessor(), or else by
+// name: `Name()`, `GetName()`, `IsName()` and `HasName()` all match a field
+// called `name` or `Name`. Methods that don't match any field are marked with
+// `?`, since they might expose anything.
+//
+// This is useful in security reviews of logging, to see what a dump of an
+// object via an interface would leave out.
+func ExposedFields(v interface{}, ifaces ...reflect.Type) string {
+	var t reflect.Type
+	if rv, ok := v.(reflect.Value); ok {
+		if rv.IsValid() {
+			t = rv.Type()
+		}
+	} else {
+		t = reflect.TypeOf(v)
+	}
+	if t == nil {
+		return tokInvalid
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(getTypeName(t))
+	buffer.WriteString(tokOpenStruct)
+	for i, iface := range ifaces {
+		if i > 0 {
+			buffer.WriteString(tokItemSeparator)
+		}
+		// Allow reflect.TypeOf((*io.Reader)(nil)) as a shorthand.
+		if iface.Kind() == reflect.Ptr && iface.Elem().Kind() == reflect.Interface {
+			iface = iface.Elem()
+		}
+		buffer.WriteString(getTypeName(iface))
+		buffer.WriteString(tokOpenArray)
+		if iface.Kind() == reflect.Interface && t.Implements(iface) {
+			writeExposedFields(&buffer, t, iface)
+		} else {
+			buffer.WriteString(tokNotImplementedBy)
+		}
+		buffer.WriteString(tokCloseArray)
+	}
+	buffer.WriteString(tokCloseStruct)
+	return buffer.String()
+}
+
+func writeExposedFields(buffer *bytes.Buffer, t reflect.Type, iface reflect.Type) {
+	structType := t
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	var fieldNames []string
+	if structType.Kind() == reflect.Struct {
+		for i := 0; i < structType.NumField(); i++ {
+			fieldNames = append(fieldNames, structType.Field(i).Name)
+		}
+	}
+
+	exposed := make(map[string]bool)
+	for i := 0; i < iface.NumMethod(); i++ {
+		if i > 0 {
+			buffer.WriteString(tokItemSeparator)
+		}
+		method := iface.Method(i).Name
+		buffer.WriteString(method)
+		buffer.WriteString("()")
+		buffer.WriteString(tokKeyValueSeparator)
+
+		fields, ok := getAccessedFields(structType, method, fieldNames)
+		switch {
+		case !ok:
+			buffer.WriteString(tokUnknownFields)
+		case len(fields) == 1:
+			buffer.WriteString(fields[0])
+		default:
+			buffer.WriteString(tokOpenArray)
+			buffer.WriteString(strings.Join(fields, tokItemSeparator))
+			buffer.WriteString(tokCloseArray)
+		}
+		for _, field := range fields {
+			exposed[field] = true
+		}
+	}
+
+	var hidden []string
+	for _, name := range fieldNames {
+		if !exposed[name] {
+			hidden = append(hidden, name)
+		}
+	}
+	if len(hidden) > 0 {
+		sort.Strings(hidden)
+		if iface.NumMethod() > 0 {
+			buffer.WriteString(tokItemSeparator)
+		}
+		buffer.WriteString(tokHiddenFields)
+		buffer.WriteString(tokOpenArray)
+		buffer.WriteString(strings.Join(hidden, tokItemSeparator))
+		buffer.WriteString(tokCloseArray)
+	}
+}
+
+var accessorPrefixes = []string{"Get", "Is", "Has"}
+
+// Get the fields that method of t exposes: those registered for it, or else
+// the field that its name refers to. Returns ok = false if unknown.
+func getAccessedFields(t reflect.Type, method string, fieldNames []string) (fields []string, ok bool) {
+	if registered, ok := accessors.Load(accessorKey{t: t, method: method}); ok {
+		return registered.([]string), true
+	}
+
+	candidates := []string{method}
+	for _, prefix := range accessorPrefixes {
+		if strings.HasPrefix(method, prefix) && len(method) > len(prefix) {
+			candidates = append(candidates, method[len(prefix):])
+		}
+	}
+	for _, candidate := range candidates {
+		for _, name := range fieldNames {
+			if strings.EqualFold(name, candidate) {
+				return []string{name}, true
+			}
+		}
+	}
+	return nil, false
+}
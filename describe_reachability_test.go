@@ -0,0 +1,63 @@
+package describe
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type ReachabilityUser struct {
+	id       int
+	name     string
+	email    string
+	password string
+	Active   bool
+}
+
+func (this *ReachabilityUser) ID() int         { return this.id }
+func (this *ReachabilityUser) GetName() string { return this.name }
+func (this *ReachabilityUser) IsActive() bool  { return this.Active }
+func (this *ReachabilityUser) String() string  { return this.name }
+func (this *ReachabilityUser) Contact() string { return this.name + " <" + this.email + ">" }
+func (this *ReachabilityUser) Validate() error { return nil }
+
+type ReachabilityNamed interface {
+	ID() int
+	GetName() string
+	IsActive() bool
+}
+
+type ReachabilityContact interface {
+	Contact() string
+	Validate() error
+}
+
+func TestExposedFields(t *testing.T) {
+	user := &ReachabilityUser{}
+	namedType := reflect.TypeOf((*ReachabilityNamed)(nil))
+
+	expected := `*describe.ReachabilityUser<describe.ReachabilityNamed[GetName()=name ID()=id IsActive()=Active hidden[email password]] fmt.Stringer[String()=? hidden[Active email id name password]] io.Reader[not implemented]>`
+	actual := ExposedFields(user, namedType, reflect.TypeOf((*fmt.Stringer)(nil)), reflect.TypeOf((*io.Reader)(nil)))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	userType := reflect.TypeOf(ReachabilityUser{})
+	RegisterAccessor(userType, "Contact", "name", "email")
+	RegisterAccessor(userType, "Validate")
+	defer accessors.Delete(accessorKey{t: userType, method: "Contact"})
+	defer accessors.Delete(accessorKey{t: userType, method: "Validate"})
+
+	expected = `*describe.ReachabilityUser<describe.ReachabilityContact[Contact()=[name email] Validate()=[] hidden[Active id password]]>`
+	actual = ExposedFields(user, reflect.TypeOf((*ReachabilityContact)(nil)))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.ReachabilityUser<describe.ReachabilityNamed[not implemented]>`
+	actual = ExposedFields(ReachabilityUser{}, namedType)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
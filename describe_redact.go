@@ -0,0 +1,69 @@
+package describe
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// The patterns that WithRedaction() uses when none are given.
+var DefaultRedactionPatterns = []string{"password", "secret", "token", "apikey", "authorization"}
+
+// Reduce a name or pattern to lowercase letters and digits, so that
+// `APIKey`, `api_key` and `X-Api-Key` all match `apikey`.
+func normalizeRedactionName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// Returns true if name matches any of the redaction patterns.
+func (this *options) isRedactedName(name string) bool {
+	if len(this.redactionPatterns) == 0 {
+		return false
+	}
+	name = normalizeRedactionName(name)
+	for _, pattern := range this.redactionPatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the value of field i of struct type t is to be masked,
+// either because of its tag or because its name matches a redaction pattern.
+func (this *options) isRedactedField(t reflect.Type, i int) bool {
+	tag := getDescribeTag(t, i)
+	if tag.isRedacted {
+		return true
+	}
+	if len(this.redactionPatterns) == 0 {
+		return false
+	}
+	return this.isRedactedName(t.Field(i).Name) || (tag.name != "" && this.isRedactedName(tag.name))
+}
+
+// Returns true if the value of the map entry with this key is to be masked.
+// Only string keys are matched against the redaction patterns.
+func (this *options) isRedactedKey(key reflect.Value) bool {
+	if len(this.redactionPatterns) == 0 {
+		return false
+	}
+	for key.Kind() == reflect.Interface && !key.IsNil() {
+		key = key.Elem()
+	}
+	return key.Kind() == reflect.String && this.isRedactedName(key.String())
+}
+
+// Get the value of a map entry, or a placeholder if it's to be masked.
+// Invalid (missing) values are left as is.
+func (this *options) getDescribedMapValue(key reflect.Value, value reflect.Value) reflect.Value {
+	if value.IsValid() && this.isRedactedKey(key) {
+		return reflect.ValueOf(tokRedacted)
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package describe
+
+import (
+	"testing"
+)
+
+type RedactedCredentials struct {
+	User        string `describe:"id"`
+	Password    string
+	APIKey      string
+	AccessToken *string
+	Pin         int `describe:"code"`
+	Headers     map[string][]string
+}
+
+func TestRedaction(t *testing.T) {
+	token := "abc"
+	v := RedactedCredentials{
+		User:        "alice",
+		Password:    "hunter2",
+		APIKey:      "k",
+		AccessToken: &token,
+		Pin:         1234,
+		Headers:     map[string][]string{"Accept": {"*/*"}, "Authorization": {"Bearer abc"}},
+	}
+
+	expected := `describe.RedactedCredentials<User="alice" Password=*** APIKey=*** AccessToken=*** code=1234 Headers=string:[]string{"Accept"=string["*/*"] "Authorization"=***}>`
+	actual := DescribeOpts(v, WithRedaction(), WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.RedactedCredentials<User="alice" Password="hunter2" APIKey="k" AccessToken=*"abc" code=*** Headers=string:[]string{"Accept"=string["*/*"] "Authorization"=string["Bearer abc"]}>`
+	actual = DescribeOpts(v, WithRedaction("CODE"), WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `{"$type":"describe.RedactedCredentials","User":"alice","Password":"***","APIKey":"***","AccessToken":"***","code":1234,"Headers":{"$type":"map[string][]string","$entries":[["Accept",{"$type":"[]string","$elements":["*/*"]}],["Authorization","***"]]}}`
+	actual = DescribeJSON(v, WithRedaction(), WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestRedactionPatterns(t *testing.T) {
+	v := map[string]int{"api_key": 1, "X-Api-Key": 2, "keys": 3, "": 4}
+	expected := `string:int{""=4 "X-Api-Key"=*** "api_key"=*** "keys"=3}`
+	actual := DescribeOpts(v, WithRedaction("API-Key", ""), WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `string:int{""=4 "X-Api-Key"=*** "api_key"=*** "keys"=***}`
+	actual = DescribeOpts(v, WithRedaction("apikey"), WithRedaction("keys"), WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestRedactionIdentity(t *testing.T) {
+	type Session struct {
+		Token string `describe:"id"`
+	}
+	session := &Session{Token: "abc"}
+	expected := `*describe.Session[*1~describe.Session<Token=***> *$1]`
+	actual := DescribeOpts([]*Session{session, session}, WithRedaction())
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
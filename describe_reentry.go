@@ -0,0 +1,97 @@
+package describe
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// User code (custom describers and String() methods) often describes other
+// values by calling back into this library. If those values lead back to the
+// object that the user code is describing, each call starts a fresh describer
+// that knows nothing of the previous one, and the recursion never ends.
+//
+// To catch this, every object being described by user code is recorded
+// together with the goroutine that the user code runs on. An object that is
+// reached again on the same goroutine while it's still being described is
+// replaced with a marker: `$^`
+//
+// Only objects with a stable identity (pointers, and addressable values) can
+// be tracked this way.
+type userDescription struct {
+	goroutine uint64
+	key       duplicates.TypedPointer
+}
+
+var activeUserDescriptions sync.Map
+var activeUserDescriptionCount int32
+
+// Run user code that describes v, recording v as being described on the
+// current goroutine until the code returns. This must be called from the
+// goroutine that runs the user code (see runUserCode()).
+func runUserDescription(v reflect.Value, code func() string) string {
+	key, ok := getMemoKey(v)
+	if !ok {
+		return code()
+	}
+	description := userDescription{
+		goroutine: getGoroutineID(),
+		key:       key,
+	}
+	activeUserDescriptions.Store(description, true)
+	atomic.AddInt32(&activeUserDescriptionCount, 1)
+	defer func() {
+		activeUserDescriptions.Delete(description)
+		atomic.AddInt32(&activeUserDescriptionCount, -1)
+	}()
+	return code()
+}
+
+// Returns true if v is already being described by user code further up the
+// current goroutine's stack.
+func isInUserDescription(v reflect.Value) bool {
+	// Avoid the cost of looking up the goroutine in the common case.
+	if atomic.LoadInt32(&activeUserDescriptionCount) == 0 {
+		return false
+	}
+	key, ok := getMemoKey(v)
+	if !ok {
+		return false
+	}
+	_, ok = activeUserDescriptions.Load(userDescription{
+		goroutine: getGoroutineID(),
+		key:       key,
+	})
+	return ok
+}
+
+// Write the marker for an object that's already being described by user
+// code: `$^`
+func (this *describer) writeEnclosingReference(v reflect.Value) {
+	this.writeString(tokReferencePrefix)
+	this.writeString(tokEnclosingReference)
+	this.writeReferenceLabel(v)
+}
+
+var goroutinePrefix = []byte("goroutine ")
+
+// Get the ID of the current goroutine, from the header of its stack trace:
+// `goroutine 18 [running]:`
+func getGoroutineID() uint64 {
+	var buffer [64]byte
+	header := buffer[:runtime.Stack(buffer[:], false)]
+	header = bytes.TrimPrefix(header, goroutinePrefix)
+	if end := bytes.IndexByte(header, ' '); end >= 0 {
+		header = header[:end]
+	}
+	id, err := strconv.ParseUint(string(header), 10, 64)
+	if err != nil {
+		notifyLibraryBug("could not parse goroutine ID from %q", string(buffer[:]))
+	}
+	return id
+}
@@ -0,0 +1,106 @@
+package describe
+
+import (
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Report describes several objects together, such as everything relevant to
+// an incident:
+//
+//	r := describe.NewReport()
+//	r.Add("request", req)
+//	r.Add("session", sess)
+//	r.Render(os.Stderr)
+//
+// All objects share one set of reference IDs, so data that's reachable from
+// more than one of them (such as a session that's also referenced from a
+// request's context) is described once, and referenced from everywhere else:
+//
+//	request=*main.Request<Path="/" Session=*1~main.Session<User="a">>
+//	session=*$1
+type Report struct {
+	mutex  sync.Mutex
+	opts   []Option
+	names  []string
+	values []reflect.Value
+}
+
+// Create an empty report. opts are used when rendering it.
+func NewReport(opts ...Option) *Report {
+	return &Report{
+		opts: opts,
+	}
+}
+
+// Add an object to the report, under a name. Objects are described in the
+// order that they were added, and are examined when the report is rendered
+// (not when they're added).
+func (this *Report) Add(name string, v interface{}) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	this.names = append(this.names, name)
+	this.values = append(this.values, rv)
+}
+
+// Write the report to w, with one `name=description` per object (each
+// starting on a new line). Paths passed to WithAnnotator() start with the
+// object's name: `session.User`
+//
+// Options apply as with DescribeOpts(), except for WithBreadthFirstBudget()
+// and WithRootType(), which are ignored.
+func (this *Report) Render(w io.Writer) error {
+	context := describer{}
+	context.applyOptions(this.opts)
+	context.resolveColors(w)
+	_, err := io.WriteString(w, this.describe(&context))
+	return err
+}
+
+// Describe the report as a string. See Render().
+func (this *Report) String() string {
+	context := describer{}
+	context.applyOptions(this.opts)
+	return this.describe(&context)
+}
+
+func (this *Report) describe(context *describer) string {
+	// Describers may add to the report, so it's not kept locked while
+	// describing.
+	this.mutex.Lock()
+	names := append([]string(nil), this.names...)
+	values := append([]reflect.Value(nil), this.values...)
+	this.mutex.Unlock()
+
+	return context.describeReport(names, values)
+}
+
+func (this *describer) describeReport(names []string, values []reflect.Value) string {
+	return this.describeRoots(values, func() string {
+		this.seenReferences = make(map[duplicates.TypedPointer]bool)
+		for i, v := range values {
+			if this.isOutputFull() {
+				break
+			}
+			if i > 0 {
+				this.writeString("\n")
+			}
+			this.writeBaseIndent()
+			this.writeColored(colorFieldName, names[i])
+			this.writeKeyValueSeparator()
+			this.path = append(this.path[:0], fieldSegment(names[i]))
+			this.depth = 0
+			this.describeReflectedValue(v, false)
+			this.annotate(v)
+		}
+		return this.stringBuilder.String()
+	})
+}
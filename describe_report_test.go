@@ -0,0 +1,100 @@
+package describe
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type ReportSession struct {
+	User string
+}
+
+type ReportRequest struct {
+	Path    string
+	Session *ReportSession
+}
+
+func TestReport(t *testing.T) {
+	session := &ReportSession{User: "a"}
+	request := &ReportRequest{Path: "/", Session: session}
+
+	report := NewReport()
+	report.Add("request", request)
+	report.Add("session", session)
+	report.Add("count", 5)
+	expected := `request=*describe.ReportRequest<Path="/" Session=*1~describe.ReportSession<User="a">>
+session=*$1
+count=5`
+	actual := report.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	buffer := bytes.Buffer{}
+	if err := report.Render(&buffer); err != nil {
+		t.Error(err)
+	}
+	actual = buffer.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestReportOptions(t *testing.T) {
+	report := NewReport(WithIndent(2), WithAnnotator(func(path string, v reflect.Value) string {
+		if path == "b.User" {
+			return "owner"
+		}
+		return ""
+	}))
+	report.Add("a", []int{1})
+	report.Add("b", ReportSession{User: "x"})
+	expected := `a = int[
+  1
+]
+b = describe.ReportSession<
+  User = "x" [owner]
+>`
+	actual := report.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	report = NewReport(WithIndent(2), WithBaseIndent(4))
+	report.Add("a", []int{1})
+	report.Add("b", 2)
+	expected = "    a = int[\n      1\n    ]\n    b = 2"
+	actual = report.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = ""
+	actual = NewReport().String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestReportAddWhileRendering(t *testing.T) {
+	var report *Report
+	report = NewReport(WithCustomDescriber(reflect.TypeOf(ReportSession{}), func(v reflect.Value) string {
+		report.Add("late", 1)
+		return "session"
+	}))
+	report.Add("session", ReportSession{})
+
+	// Objects added while rendering are included the next time.
+	expected := `session=session`
+	actual := report.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "session=session\nlate=1"
+	actual = report.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
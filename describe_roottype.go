@@ -0,0 +1,72 @@
+package describe
+
+import (
+	"reflect"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Find the outermost values of type t within v (not including any within
+// other values of type t), in depth-first order.
+func findTypeOccurrences(v reflect.Value, t reflect.Type) (occurrences []reflect.Value) {
+	visited := make(map[duplicates.TypedPointer]bool)
+	var search func(v reflect.Value)
+	search = func(v reflect.Value) {
+		if !v.IsValid() {
+			return
+		}
+		if v.Type() == t {
+			occurrences = append(occurrences, v)
+			return
+		}
+
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice:
+			if v.IsNil() {
+				return
+			}
+			ptr := duplicates.TypedPointerOfRV(v)
+			if visited[ptr] {
+				return
+			}
+			visited[ptr] = true
+		}
+
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			search(v.Elem())
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				search(v.Field(i))
+			}
+		case reflect.Array, reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				search(v.Index(i))
+			}
+		case reflect.Map:
+			for iter := mapRange(v); iter.Next(); {
+				search(iter.Value())
+			}
+		}
+	}
+	search(v)
+	return
+}
+
+func (this *describer) describeRootOccurrences() {
+	this.writeTypeName(this.rootType)
+	this.writeString(tokOpenArray)
+	this.increaseIndent()
+	isFirst := true
+	for i, occurrence := range this.rootOccurrences {
+		if this.isOutputFull() {
+			break
+		}
+		this.writeItemSeparator(isFirst)
+		isFirst = false
+		this.describeChild(indexSegment(i), occurrence, false)
+	}
+	this.decreaseIndent()
+	this.writeItemSeparator(true)
+	this.writeString(tokCloseArray)
+}
@@ -16,3 +16,7 @@ func canExposeInterface() bool {
 func exposeInterface(v reflect.Value) interface{} {
 	return "go-describe.BUG(exposeInterface called from a safe build)"
 }
+
+func exposeValue(v reflect.Value) reflect.Value {
+	return v
+}
@@ -0,0 +1,151 @@
+package describe
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Returns the number of bytes that v encodes to in some codec, or false if v
+// can't be encoded on its own. See WithEncodedSizes().
+type EncodedSizer func(v reflect.Value) (size int, ok bool)
+
+// Sizes values as encoded by encoding/json.Marshal(). Sizes don't include
+// field names or map keys, since those belong to the enclosing value.
+func JSONSize(v reflect.Value) (size int, ok bool) {
+	value, ok := getEncodableValue(v)
+	if !ok {
+		return
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return 0, false
+	}
+	return len(encoded), true
+}
+
+// Sizes values as encoded by encoding/gob. Each value is encoded as its own
+// stream, so sizes include the value's type definition (which gob sends once
+// per stream). Type IDs are allocated as gob first sees each type, so sizes
+// can differ slightly from process to process.
+func GobSize(v reflect.Value) (size int, ok bool) {
+	value, ok := getEncodableValue(v)
+	if !ok {
+		return
+	}
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(value); err != nil {
+		return 0, false
+	}
+	return buffer.Len(), true
+}
+
+// Codecs can only see values that can be extracted from their containers.
+func getEncodableValue(v reflect.Value) (value interface{}, ok bool) {
+	if !v.IsValid() || !v.CanInterface() {
+		return
+	}
+	return v.Interface(), true
+}
+
+// Get the annotation for v's encoded size (if any): `json: 12 bytes`
+func (this *describer) getEncodedSizeAnnotation(v reflect.Value) (annotation string) {
+	if this.sizer == nil || this.reflectionOnly {
+		// Codecs call methods such as MarshalJSON().
+		return ""
+	}
+	// Codecs and sizers are outside code, and can panic on odd data.
+	defer func() {
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				annotation = ""
+			}
+		}
+	}()
+	// Some codecs (such as gob) recurse forever on cyclic data. The finder is
+	// kept for the whole description, so that each piece of data is only
+	// searched once.
+	if this.cycleFinder == nil {
+		this.cycleFinder = newCycleFinder()
+	}
+	if this.cycleFinder.search(v) {
+		return ""
+	}
+	size, ok := this.sizer(v)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%v: %v bytes", this.sizeCodec, size)
+}
+
+// Finds cycles via depth-first search, remembering the result for each piece
+// of data searched so that it's only searched once, even across searches.
+type cycleFinder struct {
+	onPath map[duplicates.TypedPointer]bool
+	// Whether a cycle can be reached from each piece of data searched.
+	results map[duplicates.TypedPointer]bool
+}
+
+func newCycleFinder() *cycleFinder {
+	return &cycleFinder{
+		onPath:  make(map[duplicates.TypedPointer]bool),
+		results: make(map[duplicates.TypedPointer]bool),
+	}
+}
+
+// Returns true if v can reach itself (or anything it contains can).
+func hasCycle(v reflect.Value) bool {
+	return newCycleFinder().search(v)
+}
+
+func (this *cycleFinder) search(v reflect.Value) (reachesCycle bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+		ptr := duplicates.TypedPointerOfRV(v)
+		if this.onPath[ptr] {
+			return true
+		}
+		if result, ok := this.results[ptr]; ok {
+			return result
+		}
+		this.onPath[ptr] = true
+		defer func() {
+			delete(this.onPath, ptr)
+			this.results[ptr] = reachesCycle
+		}()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return !v.IsNil() && this.search(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if this.search(v.Field(i)) {
+				return true
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		if !isScannableKind(v.Type().Elem().Kind()) {
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if this.search(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		for iter := mapRange(v); iter.Next(); {
+			if this.search(iter.Key()) || this.search(iter.Value()) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package describe
+
+import (
+	"reflect"
+	"testing"
+)
+
+type SizedPayload struct {
+	Name   string
+	Tags   []string
+	secret string
+	Next   *SizedPayload
+}
+
+func TestEncodedSizes(t *testing.T) {
+	v := SizedPayload{Name: "abc", Tags: []string{"x", "yz"}, secret: "s"}
+
+	expected := `describe.SizedPayload<Name="abc" [json: 5 bytes] Tags=string["x" [json: 3 bytes] "yz" [json: 4 bytes]] [json: 10 bytes] ` +
+		`secret="s" Next=nil [json: 4 bytes]> [json: 44 bytes]`
+	actual := DescribeOpts(v, WithEncodedSizes("json", JSONSize))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	stringSize := func(v reflect.Value) (int, bool) {
+		if v.Kind() != reflect.String {
+			return 0, false
+		}
+		return v.Len(), true
+	}
+	annotator := func(path string, v reflect.Value) string {
+		if path == "Name" {
+			return "from env"
+		}
+		return ""
+	}
+	expected = `describe.SizedPayload<Name="abc" /* raw: 3 bytes, from env */ Tags=nil secret="" /* raw: 0 bytes */ Next=nil>`
+	actual = DescribeOpts(SizedPayload{Name: "abc"}, WithEncodedSizes("raw", stringSize),
+		WithAnnotator(annotator), WithAnnotationStyle(AnnotateComments))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Gob sizes depend on which types gob has already seen.
+	if size, ok := GobSize(reflect.ValueOf(v)); !ok || size == 0 {
+		t.Errorf("Expected a gob size but got %v, %v", size, ok)
+	}
+	if _, ok := GobSize(reflect.ValueOf(v).Field(2)); ok {
+		t.Errorf("Expected no gob size for an unexported field")
+	}
+}
+
+func TestEncodedSizesCycle(t *testing.T) {
+	v := &SizedPayload{Name: "a"}
+	v.Next = v
+
+	// The cyclic structure itself can't be encoded.
+	expected := `*1~describe.SizedPayload<Name="a" [json: 3 bytes] Tags=nil [json: 4 bytes] secret="" Next=*$1>`
+	actual := DescribeOpts(v, WithEncodedSizes("json", JSONSize))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestEncodedSizesReflectionOnly(t *testing.T) {
+	expected := `describe.SizedPayload<Name="abc" Tags=nil secret="" Next=nil>`
+	actual := DescribeOpts(SizedPayload{Name: "abc"}, WithEncodedSizes("json", JSONSize), WithReflectionOnly(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
@@ -0,0 +1,58 @@
+//go:build go1.21
+// +build go1.21
+
+package describe
+
+import (
+	"log/slog"
+)
+
+type slogValuer struct {
+	value interface{}
+	opts  []Option
+}
+
+func (this slogValuer) LogValue() slog.Value {
+	return slog.StringValue(DescribeOpts(this.value, this.opts...))
+}
+
+// Returns a slog.Value that's logged as the description of v (using opts):
+//
+//	logger.Info("handling", "request", describe.SlogValue(req))
+//
+// v is only described if the record is actually logged, and is described when
+// the record is handled (not when SlogValue() is called).
+func SlogValue(v interface{}, opts ...Option) slog.Value {
+	return slog.AnyValue(slogValuer{value: v, opts: opts})
+}
+
+// Returns a function for slog.HandlerOptions.ReplaceAttr that replaces
+// attribute values that slog would otherwise format using fmt (such as
+// structs, pointers, slices and maps) with their descriptions (using opts):
+//
+//	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+//		ReplaceAttr: describe.SlogReplaceAttr(),
+//	})
+//
+// Strings, numbers, times, durations and errors are left as they are, as are
+// the time, level, message and source attributes.
+func SlogReplaceAttr(opts ...Option) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.TimeKey, slog.LevelKey, slog.MessageKey, slog.SourceKey:
+				return a
+			}
+		}
+		// Empty attributes are dropped by handlers.
+		if a.Value.Kind() != slog.KindAny || a.Equal(slog.Attr{}) {
+			return a
+		}
+		v := a.Value.Any()
+		if _, ok := v.(error); ok {
+			return a
+		}
+		a.Value = slog.StringValue(DescribeOpts(v, opts...))
+		return a
+	}
+}
@@ -0,0 +1,64 @@
+//go:build go1.21
+// +build go1.21
+
+package describe
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+type SlogNode struct {
+	Name string
+	Next *SlogNode
+}
+
+func removeSlogTime(a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+func TestSlogValue(t *testing.T) {
+	buffer := bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			return removeSlogTime(a)
+		},
+	}))
+	node := &SlogNode{Name: "a"}
+	node.Next = node
+
+	logger.Info("test", "node", SlogValue(node))
+	expected := `level=INFO msg=test node="*1~describe.SlogNode<Name=\"a\" Next=*$1>"` + "\n"
+	actual := buffer.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestSlogReplaceAttr(t *testing.T) {
+	buffer := bytes.Buffer{}
+	replace := SlogReplaceAttr()
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			return replace(groups, removeSlogTime(a))
+		},
+	}))
+
+	logger.Info("test",
+		"node", &SlogNode{Name: "a"},
+		"tags", []string{"x"},
+		"count", 1,
+		"err", errors.New("failed"),
+		slog.Group("g", "level", map[string]int{"a": 1}))
+	expected := `level=INFO msg=test node="*describe.SlogNode<Name=\"a\" Next=nil>" tags="string[\"x\"]" ` +
+		`count=1 err=failed g.level="string:int{\"a\"=1}"` + "\n"
+	actual := buffer.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
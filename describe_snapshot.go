@@ -0,0 +1,92 @@
+package describe
+
+import (
+	goflag "flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The directory (relative to the test's package) that Snapshot() keeps its
+// files in.
+const snapshotDirectory = "testdata"
+
+// If enabled, Snapshot() writes descriptions to its snapshot files rather than
+// comparing against them. Snapshots are also updated if the test package
+// registers its own boolean `-update` flag, and it's set (for example
+// `go test -update`). This package doesn't register the flag itself, since
+// that would clash with test packages that do.
+var UpdateSnapshots = false
+
+// The parts of *testing.T (or *testing.B) that Snapshot() uses.
+type TestingT interface {
+	Helper()
+	Name() string
+	Errorf(format string, args ...interface{})
+}
+
+// Snapshot compares the multiline description of v to the one saved in
+// `testdata/<test name>.golden`, and fails the test (showing the differences
+// via DiffDescriptions()) if they don't match. When updating snapshots (see
+// UpdateSnapshots), the description is saved instead.
+//
+// Descriptions are made stable across runs: map keys are sorted, and
+// addresses, reference IDs and timestamps are normalized (see Normalize()).
+// opts are applied after the defaults of WithIndent(4) and
+// WithSortedMapKeys(true).
+//
+// Each test can have only one snapshot. Use subtests for more.
+func Snapshot(t TestingT, v interface{}, opts ...Option) {
+	t.Helper()
+
+	opts = append([]Option{WithIndent(4), WithSortedMapKeys(true)}, opts...)
+	actual := Normalize(DescribeOpts(v, opts...))
+	path := filepath.Join(snapshotDirectory, filepath.FromSlash(t.Name())+".golden")
+
+	if shouldUpdateSnapshots() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Errorf("Could not create snapshot directory: %v", err)
+			return
+		}
+		if err := ioutil.WriteFile(path, []byte(actual+"\n"), 0644); err != nil {
+			t.Errorf("Could not write snapshot: %v", err)
+		}
+		return
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			t.Errorf("Snapshot %v doesn't exist (run with -update to create it)", path)
+		} else {
+			t.Errorf("Could not read snapshot: %v", err)
+		}
+		return
+	}
+	expected := strings.TrimSuffix(string(contents), "\n")
+	if actual != expected {
+		diff := DiffDescriptions(expected, actual)
+		if diff == "" {
+			// Only the layout differs.
+			diff = "Expected:\n" + expected + "\nActual:\n" + actual
+		}
+		t.Errorf("Description doesn't match snapshot %v:\n%v", path, diff)
+	}
+}
+
+func shouldUpdateSnapshots() bool {
+	if UpdateSnapshots {
+		return true
+	}
+	// Registering the flag here would clash with test packages that register
+	// their own.
+	if f := goflag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(goflag.Getter); ok {
+			if update, ok := getter.Get().(bool); ok {
+				return update
+			}
+		}
+	}
+	return false
+}
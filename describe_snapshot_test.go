@@ -0,0 +1,79 @@
+package describe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type snapshotT struct {
+	name   string
+	errors []string
+}
+
+func (this *snapshotT) Helper() {}
+
+func (this *snapshotT) Name() string {
+	return this.name
+}
+
+func (this *snapshotT) Errorf(format string, args ...interface{}) {
+	this.errors = append(this.errors, fmt.Sprintf(format, args...))
+}
+
+type SnapshotUser struct {
+	Name    string
+	Roles   map[string]bool
+	Created time.Time
+	Self    *SnapshotUser
+}
+
+func getSnapshotUser() *SnapshotUser {
+	user := &SnapshotUser{
+		Name:    "a",
+		Roles:   map[string]bool{"c": true, "b": false, "a": true},
+		Created: time.Now(),
+	}
+	user.Self = user
+	return user
+}
+
+func TestSnapshot(t *testing.T) {
+	Snapshot(t, getSnapshotUser())
+}
+
+func TestSnapshotMismatch(t *testing.T) {
+	user := getSnapshotUser()
+	user.Name = "b"
+	fakeT := &snapshotT{name: "TestSnapshot"}
+	Snapshot(fakeT, user)
+	if len(fakeT.errors) != 1 || !strings.Contains(fakeT.errors[0], `+   Name="b"`) {
+		t.Errorf("Expected a mismatch but got %v", fakeT.errors)
+	}
+}
+
+func TestSnapshotMissing(t *testing.T) {
+	fakeT := &snapshotT{name: "TestSnapshotMissing"}
+	Snapshot(fakeT, 1)
+	if len(fakeT.errors) != 1 || !strings.Contains(fakeT.errors[0], "doesn't exist") {
+		t.Errorf("Expected a missing snapshot but got %v", fakeT.errors)
+	}
+}
+
+func TestSnapshotUpdate(t *testing.T) {
+	UpdateSnapshots = true
+	defer func() { UpdateSnapshots = false }()
+	path := filepath.Join("testdata", "TestSnapshotUpdate", "sub.golden")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	fakeT := &snapshotT{name: "TestSnapshotUpdate/sub"}
+	Snapshot(fakeT, []int{1})
+	UpdateSnapshots = false
+	Snapshot(fakeT, []int{1})
+	if len(fakeT.errors) != 0 {
+		t.Errorf("Expected no errors but got %v", fakeT.errors)
+	}
+}
@@ -0,0 +1,114 @@
+package describe
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Returns the writer to write a top-level field's description to, or nil to
+// leave the field out.
+type FieldWriterFunc func(field string) (io.Writer, error)
+
+// DescribeSplit describes each top-level field of struct v (or of the struct
+// that v points to) to its own writer, such as one file per subsystem of a
+// large server state struct:
+//
+//	err := describe.DescribeSplit(state, func(field string) (io.Writer, error) {
+//		file, err := os.Create(filepath.Join(dir, field+".txt"))
+//		files = append(files, file)
+//		return file, err
+//	}, describe.WithIndent(4))
+//
+// The object graph is walked once, and the fields share one set of reference
+// IDs, so data that's reachable from more than one field is only described in
+// the first field's output, and referenced from the others. Paths passed to
+// WithAnnotator() start with the field name, and WithMaxOutputBytes() limits
+// each field's output separately.
+//
+// Fields are named (and left out or redacted) according to their `describe`
+// tags, as in other descriptions.
+//
+// Descriptions are written as with DescribeTo(). Returns the first error
+// returned by writerFor or by a writer, after which describing stops.
+func DescribeSplit(v interface{}, writerFor FieldWriterFunc, opts ...Option) error {
+	context := describer{}
+	context.applyOptions(opts)
+
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.describeSplit(rv, writerFor)
+}
+
+func (this *describer) describeSplit(v reflect.Value, writerFor FieldWriterFunc) error {
+	root := followPointers(v, nil)
+	if !root.IsValid() {
+		return fmt.Errorf("DescribeSplit: expected a struct but got %v", tokInvalid)
+	}
+	if root.Kind() != reflect.Struct {
+		return fmt.Errorf("DescribeSplit: expected a struct but got %v", this.typeName(root.Type()))
+	}
+
+	this.sanityCheck()
+	this.referenceNames = this.findDuplicates(v)
+	this.seenReferences = make(map[duplicates.TypedPointer]bool)
+	colors := this.colors
+	for _, i := range getVisibleFieldIndices(root) {
+		field := root.Type().Field(i)
+		w, err := writerFor(getFieldName(root.Type(), i))
+		if err != nil {
+			return err
+		}
+		if w == nil {
+			continue
+		}
+		this.colors = colors
+		this.resolveColors(w)
+		this.streamOutput = &streamOutput{
+			writer: bufio.NewWriter(w),
+			limit:  this.maxOutputBytes,
+		}
+		if this.isRedactedField(root.Type(), i) {
+			this.streamOutput.WriteString(tokRedacted)
+		} else {
+			this.pendingFieldDescriber, _ = this.getFieldDescriber(root.Type(), field.Name)
+			this.describeSplitField(field.Name, root.Field(i))
+		}
+		if this.appendChecksum {
+			// Bypass the limit, since the trailer is how truncation is detected.
+			this.streamOutput.isTruncated = false
+			this.streamOutput.limit = 0
+			this.streamOutput.WriteString(getChecksumTrailer(this.streamOutput.checksum))
+		}
+		if this.streamOutput.err != nil {
+			return this.streamOutput.err
+		}
+		if err := this.streamOutput.writer.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *describer) describeSplitField(name string, v reflect.Value) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				this.streamOutput.WriteString(notifyRecoveredLibraryBug(e))
+			}
+		}
+	}()
+
+	this.path = append(this.path[:0], fieldSegment(name))
+	this.depth = 1
+	this.currentIndent = 0
+	this.lastNewlineEnd = 0
+	this.describeReflectedValue(v, false)
+	this.annotate(v)
+}
@@ -0,0 +1,86 @@
+package describe
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type SplitSession struct {
+	User string
+}
+
+type SplitState struct {
+	Sessions []*SplitSession
+	Current  *SplitSession
+	Config   map[string]int
+	secret   string
+}
+
+func describeSplitToBuffers(v interface{}, opts ...Option) (map[string]*bytes.Buffer, []string, error) {
+	buffers := make(map[string]*bytes.Buffer)
+	var order []string
+	err := DescribeSplit(v, func(field string) (io.Writer, error) {
+		if field == "secret" {
+			return nil, nil
+		}
+		order = append(order, field)
+		buffers[field] = &bytes.Buffer{}
+		return buffers[field], nil
+	}, opts...)
+	return buffers, order, err
+}
+
+func TestDescribeSplit(t *testing.T) {
+	session := &SplitSession{User: "a"}
+	v := &SplitState{
+		Sessions: []*SplitSession{session, {User: "b"}},
+		Current:  session,
+		Config:   map[string]int{"x": 1},
+		secret:   "hunter2",
+	}
+	buffers, order, err := describeSplitToBuffers(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(order, []string{"Sessions", "Current", "Config"}) {
+		t.Errorf("Expected fields [Sessions Current Config] but got %v", order)
+	}
+	for field, expected := range map[string]string{
+		"Sessions": `*describe.SplitSession[*1~describe.SplitSession<User="a"> *describe.SplitSession<User="b">]`,
+		"Current":  `*$1`,
+		"Config":   `string:int{"x"=1}`,
+	} {
+		actual := buffers[field].String()
+		if actual != expected {
+			t.Errorf("%v: Expected %v but got %v", field, expected, actual)
+		}
+	}
+}
+
+func TestDescribeSplitErrors(t *testing.T) {
+	err := DescribeSplit(SplitState{}, func(field string) (io.Writer, error) {
+		return &failingWriter{}, nil
+	})
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected a disk full error but got %v", err)
+	}
+
+	err = DescribeSplit(SplitState{}, func(field string) (io.Writer, error) {
+		return nil, errors.New("cannot create")
+	})
+	if err == nil || err.Error() != "cannot create" {
+		t.Errorf("Expected a cannot create error but got %v", err)
+	}
+
+	expected := "DescribeSplit: expected a struct but got int"
+	err = DescribeSplit(1, func(field string) (io.Writer, error) {
+		return nil, nil
+	})
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %v but got %v", expected, err)
+	}
+}
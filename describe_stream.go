@@ -0,0 +1,66 @@
+package describe
+
+import (
+	"bufio"
+	"hash/crc32"
+	"io"
+)
+
+// Output that goes straight to a writer, used by DescribeTo(). Once a write
+// fails, further writes are ignored (and describing stops). Likewise once the
+// limit (if any) is reached, a truncation marker is written and further
+// writes are ignored.
+type streamOutput struct {
+	writer      *bufio.Writer
+	length      int
+	limit       int
+	isTruncated bool
+	checksum    uint32
+	err         error
+}
+
+func (this *streamOutput) WriteString(value string) {
+	if this.err != nil || this.isTruncated {
+		return
+	}
+	if this.limit > 0 && this.length+len(value) > this.limit {
+		this.isTruncated = true
+		value = truncateDescription(value, this.limit-this.length)
+	}
+	_, this.err = this.writer.WriteString(value)
+	this.length += len(value)
+	this.checksum = crc32.Update(this.checksum, crc32.IEEETable, []byte(value))
+}
+
+func (this *streamOutput) IsFull() bool {
+	return this.err != nil || this.isTruncated
+}
+
+func (this *streamOutput) Len() int {
+	return this.length
+}
+
+func (this *describer) describeTo(w io.Writer, v interface{}) error {
+	this.resolveColors(w)
+	if this.breadthFirstBudget > 0 {
+		_, err := io.WriteString(w, this.describe(v))
+		return err
+	}
+
+	this.streamOutput = &streamOutput{
+		writer: bufio.NewWriter(w),
+		limit:  this.maxOutputBytes,
+	}
+	// Everything has already been written, unless describing failed.
+	this.streamOutput.WriteString(this.describe(v))
+	if this.appendChecksum {
+		// Bypass the limit, since the trailer is how truncation is detected.
+		this.streamOutput.isTruncated = false
+		this.streamOutput.limit = 0
+		this.streamOutput.WriteString(getChecksumTrailer(this.streamOutput.checksum))
+	}
+	if this.streamOutput.err != nil {
+		return this.streamOutput.err
+	}
+	return this.streamOutput.writer.Flush()
+}
@@ -0,0 +1,62 @@
+package describe
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type failingWriter struct {
+	writes int
+}
+
+func (this *failingWriter) Write(p []byte) (int, error) {
+	this.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestDescribeTo(t *testing.T) {
+	v := map[string]interface{}{
+		"a": []int{1, 2, 3},
+	}
+	key := &InnerStruct{number: 1}
+	m := map[*InnerStruct]*InnerStruct{key: key}
+
+	for _, indent := range []int{0, 4} {
+		for _, value := range []interface{}{v, m} {
+			var buffer bytes.Buffer
+			if err := DescribeTo(&buffer, value, WithIndent(indent)); err != nil {
+				t.Error(err)
+			}
+			expected := Describe(value, indent)
+			actual := buffer.String()
+			if actual != expected {
+				t.Errorf("Expected %v but got %v", expected, actual)
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := NewDescriber(WithBreadthFirstBudget(21)).DescribeTo(&buffer, v); err != nil {
+		t.Error(err)
+	}
+	expected := `string:interface{…}`
+	actual := buffer.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeToError(t *testing.T) {
+	writer := &failingWriter{}
+	large := make([]string, 10000)
+
+	expected := "disk full"
+	err := DescribeTo(writer, large)
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %v but got %v", expected, err)
+	}
+	if writer.writes != 1 {
+		t.Errorf("Expected describing to stop after the first failed write, but got %v writes", writer.writes)
+	}
+}
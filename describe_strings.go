@@ -0,0 +1,64 @@
+package describe
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Write a string, quoted according to WithStringQuoting().
+// Strings longer than WithMaxStringLength() are truncated, followed by their
+// original length: `"PCFET0NUWVBF…"(len=48231)`
+func (this *describer) writeQuotedString(value string) {
+	originalLength := len(value)
+	value, isTruncated := truncateString(value, this.maxStringLength)
+	openQuote, closeQuote := tokOpenString, tokCloseString
+	switch this.quoting {
+	case QuoteSingle:
+		openQuote, closeQuote = tokSingleQuote, tokSingleQuote
+	case QuoteNonIdentifiers:
+		if isIdentifierLike(value) {
+			openQuote, closeQuote = "", ""
+			break
+		}
+		fallthrough
+	case QuoteBackticksIfNeeded:
+		if strings.Contains(value, tokOpenString) && !strings.Contains(value, tokBacktick) {
+			openQuote, closeQuote = tokBacktick, tokBacktick
+		}
+	}
+	this.writeColored(colorString, openQuote+value+closeQuote)
+	if isTruncated {
+		this.writeFmt("(len=%v)", originalLength)
+	}
+}
+
+// Truncate s to maxRunes characters (if maxRunes > 0), appending an ellipsis.
+func truncateString(s string, maxRunes int) (result string, isTruncated bool) {
+	if maxRunes <= 0 || len(s) <= maxRunes {
+		return s, false
+	}
+	runeCount := 0
+	for i := range s {
+		if runeCount == maxRunes {
+			return s[:i] + tokElided, true
+		}
+		runeCount++
+	}
+	return s, false
+}
+
+// Returns true if s consists of letters, digits and underscores, and doesn't
+// start with a digit. Words such as `nil` and `true` don't count, since they'd
+// be mistaken for non-string values.
+func isIdentifierLike(s string) bool {
+	switch s {
+	case "", tokNilPointer, tokInvalid, "true", "false":
+		return false
+	}
+	for i, ch := range s {
+		if !(ch == '_' || unicode.IsLetter(ch) || (i > 0 && unicode.IsDigit(ch))) {
+			return false
+		}
+	}
+	return true
+}
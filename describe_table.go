@@ -0,0 +1,126 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+const tableColumnSeparator = "  "
+const tableIndexHeader = "#"
+const tableValueHeader = "value"
+
+// Describes the elements of a slice or array as a table, with a row per
+// element and a column per struct field (following pointers and interfaces),
+// which is much easier to scan than a single line when there are many similar
+// elements:
+//
+//	#  ID  State      Task
+//	0  1   "idle"     nil
+//	1  2   "running"  *main.Task<URL="http://x.com">
+//
+// Elements that aren't structs are described in a single `value` column. If v
+// isn't a slice or array, it's described as a single row.
+func DescribeTable(v interface{}, opts ...Option) string {
+	context := describer{}
+	context.applyOptions(opts)
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.describeTable(rv)
+}
+
+func (this *describer) describeTable(v reflect.Value) string {
+	v = unwrapInterface(v)
+	var elements []reflect.Value
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			elements = append(elements, v.Index(i))
+		}
+	default:
+		elements = []reflect.Value{v}
+	}
+	count := this.getElementCountToDescribe(len(elements))
+
+	// Columns are the union of all struct fields, in order of appearance.
+	headers := []string{tableIndexHeader}
+	columns := map[string]int{}
+	rows := make([]map[int]string, count)
+	for i := 0; i < count; i++ {
+		rows[i] = map[int]string{0: fmt.Sprintf("%v", i)}
+		element := reflect.Value{}
+		if elements[i].IsValid() && !this.isDescribedByUserCode(elements[i]) {
+			element = getTableRowStruct(elements[i])
+		}
+		if !element.IsValid() {
+			rows[i][getTableColumn(tableValueHeader, &headers, columns)] = this.describeWithUserCode(elements[i])
+			continue
+		}
+		for _, field := range getVisibleFieldIndices(element) {
+			column := getTableColumn(getFieldName(element.Type(), field), &headers, columns)
+			rows[i][column] = this.describeWithUserCode(this.getDescribedField(element, field))
+		}
+	}
+
+	widths := make([]int, len(headers))
+	measure := func(column int, cell string) {
+		if width := utf8.RuneCountInString(cell); width > widths[column] {
+			widths[column] = width
+		}
+	}
+	for column, header := range headers {
+		measure(column, header)
+	}
+	for _, row := range rows {
+		for column, cell := range row {
+			measure(column, cell)
+		}
+	}
+
+	builder := strings.Builder{}
+	writeRow := func(getCell func(column int) string) {
+		line := strings.Builder{}
+		for column := range headers {
+			if column > 0 {
+				line.WriteString(tableColumnSeparator)
+			}
+			cell := getCell(column)
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", widths[column]-utf8.RuneCountInString(cell)))
+		}
+		builder.WriteString(strings.TrimRight(line.String(), " "))
+	}
+	writeRow(func(column int) string { return headers[column] })
+	for _, row := range rows {
+		builder.WriteString("\n")
+		writeRow(func(column int) string { return row[column] })
+	}
+	if omitted := len(elements) - count; omitted > 0 {
+		builder.WriteString(fmt.Sprintf("\n%v(+%v more %v)", tokElided, omitted, tokMoreElements))
+	}
+	return builder.String()
+}
+
+// Get the index of the column with the given header, adding it if needed.
+func getTableColumn(header string, headers *[]string, columns map[string]int) int {
+	if column, ok := columns[header]; ok {
+		return column
+	}
+	column := len(*headers)
+	*headers = append(*headers, header)
+	columns[header] = column
+	return column
+}
+
+// Get the struct that a table row describes (following pointers and
+// interfaces), or an invalid value if it's not a struct.
+func getTableRowStruct(v reflect.Value) reflect.Value {
+	v = followPointers(v, nil)
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v
+}
@@ -0,0 +1,64 @@
+package describe
+
+import (
+	"testing"
+)
+
+type TableTask struct {
+	URL string
+}
+
+type TableWorker struct {
+	ID    int
+	State string
+	Task  *TableTask
+}
+
+func TestDescribeTable(t *testing.T) {
+	v := []*TableWorker{
+		{ID: 1, State: "idle"},
+		{ID: 2, State: "running", Task: &TableTask{URL: "http://x.com"}},
+	}
+
+	expected := `#  ID  State      Task
+0  1   "idle"     nil
+1  2   "running"  *describe.TableTask<URL="http://x.com">`
+	actual := DescribeTable(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "#  value\n0  \"a\"\n…(+2 more elements)"
+	actual = DescribeTable([]string{"a", "b", "c"}, WithMaxElements(1))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "#  value\n0  5"
+	actual = DescribeTable(5)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeWorkerPools(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	workers := []TableWorker{{ID: 1, State: "idle"}}
+	RegisterWorkerPool("b-pool", func() interface{} { return workers })
+	RegisterWorkerPool("a-pool", func() interface{} { panic("oops") })
+	defer RegisterWorkerPool("a-pool", nil)
+	defer RegisterWorkerPool("b-pool", nil)
+
+	expected := `a-pool: panic(oops)
+
+b-pool (1 workers):
+#  ID  State   Task
+0  1   "idle"  nil`
+	actual := DescribeWorkerPools()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
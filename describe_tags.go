@@ -0,0 +1,122 @@
+package describe
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const describeTagName = "describe"
+
+// The contents of a struct field's `describe` tag. The tag is a
+// comma-separated list of options, optionally starting with the name to show
+// for the field (as with `json` tags):
+//
+//   - name: Show the field under this name instead
+//   - group=name: Print this field in the named group (see WithFieldGroups)
+//   - id: This field identifies the object, and is printed after references
+//     to it: `$1("alice")`
+//   - redact: Mask the field's value: `Password=***`
+//
+// A tag of `describe:"-"` leaves the field out entirely.
+type describeTag struct {
+	name       string
+	group      string
+	isIdentity bool
+	isRedacted bool
+	isOmitted  bool
+}
+
+var describeTags sync.Map // reflect.Type -> []describeTag
+
+// Get the parsed `describe` tag of field i of struct type t. Tags are parsed
+// once per type, since fields are looked up on every description.
+func getDescribeTag(t reflect.Type, i int) describeTag {
+	if cached, ok := describeTags.Load(t); ok {
+		return cached.([]describeTag)[i]
+	}
+	tags := make([]describeTag, t.NumField())
+	for j := range tags {
+		tags[j] = parseDescribeTag(t.Field(j))
+	}
+	describeTags.Store(t, tags)
+	return tags[i]
+}
+
+func parseDescribeTag(field reflect.StructField) (tag describeTag) {
+	contents := field.Tag.Get(describeTagName)
+	if contents == tokOmittedField {
+		tag.isOmitted = true
+		return
+	}
+	for i, option := range strings.Split(contents, ",") {
+		key, value := option, ""
+		if index := strings.IndexByte(option, '='); index >= 0 {
+			key, value = option[:index], option[index+1:]
+		}
+		switch strings.TrimSpace(key) {
+		case "group":
+			tag.group = strings.TrimSpace(value)
+		case "id":
+			tag.isIdentity = true
+		case "redact":
+			tag.isRedacted = true
+		default:
+			if i == 0 && value == "" {
+				tag.name = strings.TrimSpace(option)
+			}
+		}
+	}
+	return
+}
+
+// Get the name to show for field i of struct type t.
+func getFieldName(t reflect.Type, i int) string {
+	if name := getDescribeTag(t, i).name; name != "" {
+		return name
+	}
+	return t.Field(i).Name
+}
+
+// Get field i of struct v, or a placeholder if the field's value is to be
+// masked.
+func (this *options) getDescribedField(v reflect.Value, i int) reflect.Value {
+	if this.isRedactedField(v.Type(), i) {
+		return reflect.ValueOf(tokRedacted)
+	}
+	return v.Field(i)
+}
+
+func (this *options) getIdentityFieldIndex(t reflect.Type) (index int, ok bool) {
+	for i := 0; i < t.NumField(); i++ {
+		// Redacted identities would leak via the references.
+		if getDescribeTag(t, i).isIdentity && !this.isRedactedField(t, i) {
+			return i, true
+		}
+	}
+	return
+}
+
+// Write the value of a struct's identity field (if it has one, and it's a
+// scalar) as a label for a reference to it.
+func (this *describer) writeReferenceLabel(v reflect.Value) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	index, ok := this.getIdentityFieldIndex(v.Type())
+	if !ok {
+		return
+	}
+	field := v.Field(index)
+	switch field.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		return
+	}
+	this.writeString(tokOpenReferenceLabel)
+	this.describeReflectedValue(field, false)
+	this.writeString(tokCloseReferenceLabel)
+}
@@ -2,9 +2,12 @@ package describe
 
 import (
 	"fmt"
+	"io"
 	"math/big"
 	"net/url"
+	"os"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -249,17 +252,50 @@ type MyTypeUnexported struct {
 func TestStructUnexportedReflectType(t *testing.T) {
 	rv := MyTypeUnexported{reflect.TypeOf(1), reflect.TypeOf(1)}
 
+	// Without unsafe operations, predeclared types are still recognized.
+	expected := `describe.MyTypeUnexported<T=reflect.Type<int> t=reflect.Type<int>>`
 	actual := Describe(rv, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	actual = DescribeOpts(rv, WithUnsafeOperations(false))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type unexportedTypeRegistry struct {
+	Value unexportedTypeRegistryEntry
+	t     reflect.Type
+	u     reflect.Type
+}
+
+type unexportedTypeRegistryEntry struct{}
+
+type unexportedTypeNeverDescribed struct{}
+
+func TestStructUnexportedReflectTypeSafe(t *testing.T) {
+	rv := unexportedTypeRegistry{
+		t: reflect.TypeOf(time.Duration(0)),
+		u: reflect.TypeOf(unexportedTypeNeverDescribed{}),
+	}
+
+	// Without unsafe operations, predeclared and common types are recognized,
+	// but others can't be (even if they have been described).
+	actual := DescribeOpts(rv, WithUnsafeOperations(false))
+	expectedPrefix := "describe.unexportedTypeRegistry<Value=describe.unexportedTypeRegistryEntry<> " +
+		"t=reflect.Type<time.Duration> u=reflect.Type<0x"
+	if !strings.HasPrefix(actual, expectedPrefix) {
+		t.Errorf("Expected %v to start with %v", actual, expectedPrefix)
+	}
+
 	if canExposeInterface() {
-		expected := `describe.MyTypeUnexported<T=reflect.Type<int> t=reflect.Type<int>>`
+		expected := "describe.unexportedTypeRegistry<Value=describe.unexportedTypeRegistryEntry<> " +
+			"t=reflect.Type<time.Duration> u=reflect.Type<describe.unexportedTypeNeverDescribed>>"
+		actual = Describe(rv, 0)
 		if actual != expected {
 			t.Errorf("Expected %v but got %v", expected, actual)
 		}
-	} else {
-		expectedPrefix := "describe.MyTypeUnexported<T=reflect.Type<int> t=reflect.Type<0x"
-		if !strings.HasPrefix(actual, expectedPrefix) {
-			t.Errorf("Expected %v to start with %v", actual, expectedPrefix)
-		}
 	}
 }
 
@@ -419,3 +455,1899 @@ func TestUnexportedStringer(t *testing.T) {
 		t.Errorf("Expected %v but got %v", expected, actual)
 	}
 }
+
+func TestChannelLengths(t *testing.T) {
+	EnableChannelLengths = true
+	defer func() { EnableChannelLengths = false }()
+
+	ch := make(chan int, 4)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	<-ch
+	ch <- 4
+	ch <- 5
+
+	expected := "chan<int>(len=4 cap=4)"
+	actual := D(ch)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	if D(make(chan int)) != "chan<int>" {
+		t.Errorf("Expected unbuffered channel to have no length")
+	}
+}
+
+type AnnotatedConfig struct {
+	Price   int
+	Servers []string
+	Ports   map[string]int
+}
+
+func TestAnnotator(t *testing.T) {
+	v := AnnotatedConfig{
+		Price:   42,
+		Servers: []string{"a", "b"},
+		Ports:   map[string]int{"http": 80},
+	}
+	annotator := func(path string, v reflect.Value) string {
+		switch path {
+		case "Price":
+			return "from env PRICE"
+		case "Servers[1]", `Ports["http"]`:
+			return "default"
+		}
+		return ""
+	}
+	expected := `describe.AnnotatedConfig<Price=42 [from env PRICE] Servers=string["a" "b" [default]] Ports=string:int{"http"=80 [default]}>`
+	actual := DescribeOpts(v, WithAnnotator(annotator))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestAnnotatorReflectionOnlyKeys(t *testing.T) {
+	countedStringerCalls = 0
+	v := map[*CountedStringer][]int{&CountedStringer{"k"}: {1, 2}}
+	var paths []string
+	annotator := func(path string, v reflect.Value) string {
+		paths = append(paths, path)
+		return ""
+	}
+	var index DescriptionIndex
+	DescribeOpts(v, WithReflectionOnly(true), WithAnnotator(annotator), WithIndex(&index))
+
+	expected := `[Name [*describe.CountedStringer<Name="k">][0] [*describe.CountedStringer<Name="k">][1] [*describe.CountedStringer<Name="k">] ]`
+	actual := fmt.Sprintf("%v", paths)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	if countedStringerCalls != 0 {
+		t.Errorf("Expected String() to not be called but was called %v times", countedStringerCalls)
+	}
+}
+
+func TestAnnotationStyle(t *testing.T) {
+	annotator := func(path string, v reflect.Value) string {
+		if path == "Price" && v.Int() > 40 {
+			return "stale, >5m old */"
+		}
+		return ""
+	}
+	expected := `describe.AnnotatedConfig<Price=42 /* stale, >5m old * / */ Servers=nil Ports=nil>`
+	actual := DescribeOpts(AnnotatedConfig{Price: 42}, WithAnnotator(annotator), WithAnnotationStyle(AnnotateComments))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeOptsIndent(t *testing.T) {
+	v := []int{1, 2}
+	expected := Describe(v, 2)
+	actual := DescribeOpts(v, WithIndent(2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type LocaleTest struct {
+	Float float64
+	Time  time.Time
+	time  time.Time
+}
+
+func TestLocaleIndependent(t *testing.T) {
+	for _, name := range []string{"LANG", "LC_ALL", "LC_NUMERIC", "LC_TIME"} {
+		oldValue, wasSet := os.LookupEnv(name)
+		os.Setenv(name, "de_DE.UTF-8")
+		if wasSet {
+			defer os.Setenv(name, oldValue)
+		} else {
+			defer os.Unsetenv(name)
+		}
+	}
+
+	tm := time.Date(2020, time.Month(3), 1, 1, 1, 1, 500000000, time.UTC)
+	v := LocaleTest{Float: 1.5, Time: tm, time: tm}
+
+	expected := `describe.LocaleTest<Float=1.5 Time=time.Time<2020-03-01 01:01:01.5 +0000 UTC> time=time.Time<2020-03-01 01:01:01.5 +0000 UTC>>`
+	if !canExposeInterface() {
+		expected = `describe.LocaleTest<Float=1.5 Time=time.Time<2020-03-01 01:01:01.5 +0000 UTC> time=time.Time<unexported>>`
+	}
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.LocaleTest<Float=1.5 Time=time.Time<Sun, 01 Mar 2020 01:01:01 UTC> time=time.Time<Sun, 01 Mar 2020 01:01:01 UTC>>`
+	if !canExposeInterface() {
+		expected = `describe.LocaleTest<Float=1.5 Time=time.Time<Sun, 01 Mar 2020 01:01:01 UTC> time=time.Time<unexported>>`
+	}
+	actual = DescribeOpts(v, WithTimeLayout(time.RFC1123))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestTimeLayoutStripsMonotonic(t *testing.T) {
+	now := time.Now()
+	expected := "time.Time<" + now.Format(time.RFC3339Nano) + ">"
+	actual := DescribeOpts(now, WithTimeLayout(time.RFC3339Nano))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestTimeDetails(t *testing.T) {
+	location := time.FixedZone("EST", -5*60*60)
+	tm := time.Date(2020, time.Month(3), 1, 1, 1, 1, 0, location)
+	expected := `time.Time<2020-03-01 01:01:01 -0500 EST location=EST monotonic=none>`
+	actual := DescribeOpts(tm, WithTimeDetails(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `time.Time<2020-03-01T01:01:01-05:00 location=EST monotonic=none>`
+	actual = DescribeOpts(tm, WithTimeDetails(true), WithTimeLayout(time.RFC3339))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	now := time.Now()
+	description := now.String()
+	monotonic := description[strings.LastIndex(description, " m=")+3:]
+	expected = "time.Time<" + now.Round(0).String() + " location=Local monotonic=" + monotonic + ">"
+	actual = DescribeOpts(now, WithTimeDetails(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type TypeNameTest struct {
+	Inner []InnerStruct
+	Map   map[string]*InnerStruct
+}
+
+func TestTypeNamesFull(t *testing.T) {
+	v := TypeNameTest{Inner: []InnerStruct{{1}}}
+	expected := `github.com/kstenerud/go-describe.TypeNameTest<Inner=github.com/kstenerud/go-describe.InnerStruct[github.com/kstenerud/go-describe.InnerStruct<number=1>] Map=nil>`
+	actual := DescribeOpts(v, WithTypeNames(TypeNamesFull))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestTypeNamesAbbreviated(t *testing.T) {
+	v := TypeNameTest{Map: map[string]*InnerStruct{"a": nil}}
+	pkg := "…/kstenerud/go-describe#" + getPackageHash("github.com/kstenerud/go-describe")
+	expected := pkg + `.TypeNameTest<Inner=nil Map=string:*` + pkg + `.InnerStruct{"a"=nil}>`
+	actual := DescribeOpts(v, WithTypeNames(TypeNamesAbbreviated))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `reflect.Type<func(` + pkg + `.InnerStruct, ...*net/url.URL) struct { A ` + pkg + `.TypeNameTest "json:\"a\""; B interface { M() ` + pkg + `.InnerStruct } }>`
+	actual = DescribeOpts(reflect.TypeOf(func(InnerStruct, ...*url.URL) (_ struct {
+		A TypeNameTest `json:"a"`
+		B interface{ M() InnerStruct }
+	}) {
+		return
+	}), WithTypeNames(TypeNamesAbbreviated))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestTypeNamesAbbreviatedDisambiguation(t *testing.T) {
+	for _, test := range [][2]string{
+		{"net/url", "net/url"},
+		{"github.com/acme/internal/billing", "…/internal/billing"},
+		{"github.com/other/internal/billing", "…/internal/billing"},
+	} {
+		actual := abbreviatePackagePath(test[0])
+		if actual != test[1] {
+			t.Errorf("Expected %v but got %v", test[1], actual)
+		}
+	}
+	if getPackageHash("github.com/acme/internal/billing") == getPackageHash("github.com/other/internal/billing") {
+		t.Errorf("Expected packages that abbreviate the same to have different hashes")
+	}
+}
+
+func TestTypeHashes(t *testing.T) {
+	v := TypeNameTest{Inner: []InnerStruct{{1}}}
+	innerHash := getTypeHash(reflect.TypeOf(InnerStruct{}))
+	testHash := getTypeHash(reflect.TypeOf(TypeNameTest{}))
+	if len(innerHash) != typeHashDigits || innerHash == testHash {
+		t.Errorf("Expected distinct %v digit hashes but got %v and %v", typeHashDigits, innerHash, testHash)
+	}
+
+	expected := `describe.TypeNameTest#` + testHash + `<Inner=describe.InnerStruct#` + innerHash + `[describe.InnerStruct#` + innerHash + `<number=1>] Map=nil>`
+	actual := DescribeOpts(v, WithTypeHashes(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `…/kstenerud/go-describe.TypeNameTest#` + testHash + `<Inner=…/kstenerud/go-describe.InnerStruct#` + innerHash + `[…/kstenerud/go-describe.InnerStruct#` + innerHash + `<number=1>] Map=nil>`
+	actual = DescribeOpts(v, WithTypeHashes(true), WithTypeNames(TypeNamesAbbreviated))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = DescribeOpts(v, WithTypeNames(TypeNamesFull))
+	actual = DescribeOpts(v, WithTypeHashes(true), WithTypeNames(TypeNamesFull))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestTypeHashStability(t *testing.T) {
+	// Hashes must not change between releases, since they're searched for.
+	expected := "9b2b26"
+	actual := getTypeHash(reflect.TypeOf(url.URL{}))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type UnionCreated struct {
+	ID int
+}
+
+type UnionDeleted struct {
+	Reason string
+}
+
+type UnionEvent struct {
+	Sequence int
+	Created  *UnionCreated
+	Deleted  *UnionDeleted
+	Type     string
+	Extra    map[string]string
+}
+
+func TestTaggedUnion(t *testing.T) {
+	eventType := reflect.TypeOf(UnionEvent{})
+	SetTaggedUnion(eventType, "Type", "Created", "Deleted")
+	defer SetTaggedUnion(eventType, "")
+
+	v := UnionEvent{Sequence: 1, Type: "Created", Created: &UnionCreated{ID: 5}}
+	expected := `describe.UnionEvent<Type="Created" Sequence=1 Created=*describe.UnionCreated<ID=5> Extra=nil>`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetTaggedUnion(eventType, "Type")
+	expected = `describe.UnionEvent<Type="Created" Sequence=1 Created=*describe.UnionCreated<ID=5>>`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	SetTaggedUnion(eventType, "")
+	expected = `describe.UnionEvent<Sequence=1 Created=*describe.UnionCreated<ID=5> Deleted=nil Type="Created" Extra=nil>`
+	actual = D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type GroupedConfig struct {
+	Name    string
+	Host    string `describe:"group=network"`
+	Path    string `describe:"group=storage"`
+	Port    int    `describe:"group=network"`
+	Verbose bool
+}
+
+func TestFieldGroups(t *testing.T) {
+	v := GroupedConfig{Name: "main", Host: "example.com", Path: "/tmp", Port: 80}
+	expected := `describe.GroupedConfig<
+  Name = "main"
+  Verbose = false
+  # network
+    Host = "example.com"
+    Port = 80
+  # storage
+    Path = "/tmp"
+>`
+	actual := DescribeOpts(v, WithIndent(2), WithFieldGroups(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.GroupedConfig<Name="main" Host="example.com" Path="/tmp" Port=80 Verbose=false>`
+	actual = DescribeOpts(v, WithFieldGroups(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type DepthTree struct {
+	Name     string
+	Children []*DepthTree
+	Tags     []string
+}
+
+func TestTypeDepth(t *testing.T) {
+	v := &DepthTree{
+		Name: "root",
+		Children: []*DepthTree{
+			{
+				Name:     "child",
+				Children: []*DepthTree{{Name: "grandchild"}},
+				Tags:     []string{"a"},
+			},
+		},
+	}
+	expected := `*describe.DepthTree<Name="root" Children=*describe.DepthTree[*describe.DepthTree<Name="child" Children=*describe.DepthTree[*describe.DepthTree<…>] Tags=string["a"]>] Tags=nil>`
+	actual := DescribeOpts(v, WithTypeDepth(reflect.TypeOf(DepthTree{}), 2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.DepthTree<Name="root" Children=*describe.DepthTree[…] Tags=nil>`
+	actual = DescribeOpts(v, WithTypeDepth(reflect.TypeOf([]*DepthTree{}), 0))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type MapKeyStruct struct {
+	A int
+}
+
+func TestMultilineMapKey(t *testing.T) {
+	v := map[MapKeyStruct]MapKeyStruct{{1}: {2}}
+	expected := `describe.MapKeyStruct:describe.MapKeyStruct{
+  describe.MapKeyStruct<
+    A = 1
+  > =
+    describe.MapKeyStruct<
+      A = 2
+    >
+}`
+	actual := Describe(v, 2)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `string:int{
+  "a" = 1
+}`
+	actual = Describe(map[string]int{"a": 1}, 2)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestBaseIndent(t *testing.T) {
+	v := map[MapKeyStruct]MapKeyStruct{{1}: {2}}
+	expected := `    describe.MapKeyStruct:describe.MapKeyStruct{
+      describe.MapKeyStruct<
+        A = 1
+      > =
+        describe.MapKeyStruct<
+          A = 2
+        >
+    }`
+	actual := DescribeOpts(v, WithIndent(2), WithBaseIndent(4))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.MapKeyStruct<A=1>`
+	actual = DescribeOpts(MapKeyStruct{1}, WithBaseIndent(4))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestLibraryBugHandler(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	var reported []string
+	LibraryBugHandler = func(description string) {
+		reported = append(reported, description)
+	}
+	defer func() { LibraryBugHandler = nil }()
+
+	actual := Describe(1, maxIndentStep+1)
+	if !strings.HasPrefix(actual, "go-describe.BUG(") {
+		t.Errorf("Expected a BUG description but got %v", actual)
+	}
+	if len(reported) != 1 || !strings.Contains(reported[0], "Sanity check fail") {
+		t.Errorf("Expected one sanity check failure to be reported but got %v", reported)
+	}
+}
+
+func TestPanicOnLibraryBug(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+	PanicOnLibraryBug = true
+	defer func() { PanicOnLibraryBug = false }()
+
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("Expected a panic")
+		}
+	}()
+	Describe(1, maxIndentStep+1)
+}
+
+func TestPanicOnLibraryBugReportsOnce(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+	PanicOnLibraryBug = true
+	defer func() { PanicOnLibraryBug = false }()
+
+	var reported []string
+	LibraryBugHandler = func(description string) {
+		reported = append(reported, description)
+	}
+	defer func() { LibraryBugHandler = nil }()
+
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("Expected a panic")
+		}
+		expected := "[unhandled kind]"
+		actual := fmt.Sprintf("%v", reported)
+		if actual != expected {
+			t.Errorf("Expected %v but got %v", expected, actual)
+		}
+	}()
+	context := describer{}
+	context.describeRoots([]reflect.Value{reflect.ValueOf(1)}, func() string {
+		return notifyLibraryBug("unhandled kind")
+	})
+}
+
+type PanickingStringer struct {
+	Value int
+}
+
+func (this PanickingStringer) String() string {
+	panic("stringer failed")
+}
+
+func TestPanicHandler(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	var types []reflect.Type
+	var recovered []interface{}
+	var stacks []string
+	SetPanicHandler(func(panicType reflect.Type, e interface{}, stack []byte) {
+		types = append(types, panicType)
+		recovered = append(recovered, e)
+		stacks = append(stacks, string(stack))
+	})
+	defer SetPanicHandler(nil)
+
+	expected := `describe.PanickingStringer<Value=1>`
+	actual := D(PanickingStringer{1})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	intType := reflect.TypeOf(1)
+	SetCustomDescriber(intType, func(v reflect.Value) string { panic("describer failed") })
+	defer SetCustomDescriber(intType, nil)
+	expected = `panic(describer failed)`
+	actual = D(1)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	if len(types) != 2 || types[0] != reflect.TypeOf(PanickingStringer{}) || types[1] != intType {
+		t.Errorf("Expected panics from PanickingStringer and int but got %v", types)
+	}
+	if len(recovered) != 2 || recovered[0] != "stringer failed" || recovered[1] != "describer failed" {
+		t.Errorf("Expected recovered values but got %v", recovered)
+	}
+	if len(stacks) != 2 || !strings.Contains(stacks[0], "PanickingStringer") {
+		t.Errorf("Expected stack to contain the panicking method but got %v", stacks)
+	}
+}
+
+type BlockingStringer struct {
+	block chan bool
+}
+
+func (this BlockingStringer) String() string {
+	<-this.block
+	return "unblocked"
+}
+
+func TestDescriberTimeout(t *testing.T) {
+	v := BlockingStringer{block: make(chan bool)}
+	defer close(v.block)
+
+	expected := `describe.BlockingStringer<describer timeout>`
+	actual := DescribeOpts(v, WithDescriberTimeout(10*time.Millisecond))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.InnerStruct<number=1>`
+	actual = DescribeOpts(InnerStruct{1}, WithDescriberTimeout(10*time.Millisecond))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescriberTimeoutPanic(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	var recovered interface{}
+	SetPanicHandler(func(panicType reflect.Type, e interface{}, stack []byte) {
+		recovered = e
+	})
+	defer SetPanicHandler(nil)
+
+	intType := reflect.TypeOf(1)
+	SetCustomDescriber(intType, func(v reflect.Value) string { panic("describer failed") })
+	defer SetCustomDescriber(intType, nil)
+
+	expected := `panic(describer failed)`
+	actual := DescribeOpts(1, WithDescriberTimeout(time.Second))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	if recovered != "describer failed" {
+		t.Errorf("Expected recovered value but got %v", recovered)
+	}
+}
+
+type CountedStringer struct {
+	Name string
+}
+
+var countedStringerCalls = 0
+
+func (this *CountedStringer) String() string {
+	countedStringerCalls++
+	return this.Name
+}
+
+type MemoizeTest struct {
+	A *CountedStringer
+	B *CountedStringer
+	C []*CountedStringer
+}
+
+func TestMemoizeCustomDescriber(t *testing.T) {
+	countedStringerCalls = 0
+	stringer := &CountedStringer{"abc"}
+	v := MemoizeTest{A: stringer, B: stringer, C: []*CountedStringer{stringer}}
+
+	expected := `describe.MemoizeTest<A=1~*describe.CountedStringer<abc> B=$1 C=*describe.CountedStringer[$1]>`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	if countedStringerCalls != 1 {
+		t.Errorf("Expected String() to be called once but was called %v times", countedStringerCalls)
+	}
+}
+
+type PointerReceiverStringer struct {
+	value int
+}
+
+func (this *PointerReceiverStringer) String() string {
+	return fmt.Sprintf("value %v", this.value)
+}
+
+type PointerReceiverStringerTest struct {
+	Exported   PointerReceiverStringer
+	unexported PointerReceiverStringer
+}
+
+func TestPointerReceiverStringer(t *testing.T) {
+	v := &PointerReceiverStringerTest{
+		Exported:   PointerReceiverStringer{1},
+		unexported: PointerReceiverStringer{2},
+	}
+	expected := "*describe.PointerReceiverStringerTest<Exported=describe.PointerReceiverStringer<value 1> unexported=describe.PointerReceiverStringer<value 2>>"
+	if !canExposeInterface() {
+		expected = "*describe.PointerReceiverStringerTest<Exported=describe.PointerReceiverStringer<value 1> unexported=describe.PointerReceiverStringer<unexported>>"
+	}
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	// Not addressable, so a temporary copy is used
+	expected = "describe.PointerReceiverStringer<value 3>"
+	actual = D(PointerReceiverStringer{3})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type ImplementsTest struct{}
+
+func (this ImplementsTest) String() int {
+	return 0
+}
+
+func (this *ImplementsTest) Read(p []byte) (int, error) {
+	return 0, nil
+}
+
+func (this ImplementsTest) Write(p []byte) (int, error) {
+	return 0, nil
+}
+
+func TestImplements(t *testing.T) {
+	stringerType := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	readCloserType := reflect.TypeOf((*io.ReadCloser)(nil))
+	writerType := reflect.TypeOf((*io.Writer)(nil)).Elem()
+	readerType := reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+	expected := "describe.ImplementsTest<fmt.Stringer=no[String() string (has String() int)] io.ReadCloser=no[Close() error, Read([]uint8) (int, error)] io.Writer=yes io.Reader=no[implemented by *describe.ImplementsTest] int=no[not an interface]>"
+	actual := Implements(ImplementsTest{}, stringerType, readCloserType, writerType, readerType, reflect.TypeOf(1))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "*describe.ImplementsTest<io.ReadCloser=no[Close() error] io.Reader=yes>"
+	actual = Implements(&ImplementsTest{}, readCloserType, readerType)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type ComponentDB struct {
+	Host string
+}
+
+type ComponentService struct {
+	DB *ComponentDB
+}
+
+func TestDescribeComponents(t *testing.T) {
+	db := &ComponentDB{Host: "x"}
+	components := map[string]interface{}{
+		"users":  &ComponentService{DB: db},
+		"db":     db,
+		"orders": &ComponentService{DB: db},
+	}
+
+	expected := `string:interface{"db"=@*1~describe.ComponentDB<Host="x"> "orders"=@*describe.ComponentService<DB=*$1> "users"=@*describe.ComponentService<DB=*$1>}`
+	actual := DescribeComponents(components)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestSortMapKeys(t *testing.T) {
+	keys := []reflect.Value{
+		reflect.ValueOf(10),
+		reflect.ValueOf(9),
+		reflect.ValueOf(-1),
+	}
+	sortMapKeys(keys)
+	actual := fmt.Sprintf("%v %v %v", keys[0].Int(), keys[1].Int(), keys[2].Int())
+	expected := "-1 9 10"
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type BreadthFirstTest struct {
+	First  []int
+	Second InnerStruct
+	Third  string
+}
+
+func TestBreadthFirstBudget(t *testing.T) {
+	v := BreadthFirstTest{
+		First:  []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		Second: InnerStruct{1},
+		Third:  "third",
+	}
+
+	expected := `describe.BreadthFirstTest<First=int[…] Second=describe.InnerStruct<…> Third="third">`
+	actual := DescribeOpts(v, WithBreadthFirstBudget(90))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = D(v)
+	actual = DescribeOpts(v, WithBreadthFirstBudget(1000))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.Breadth…`
+	actual = DescribeOpts(v, WithBreadthFirstBudget(16))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type RootTypeSession struct {
+	User string
+}
+
+type RootTypeWrapper struct {
+	Name    string
+	Inner   interface{}
+	Session *RootTypeSession
+}
+
+func TestRootType(t *testing.T) {
+	session := &RootTypeSession{User: "bob"}
+	v := RootTypeWrapper{
+		Name:  "outer",
+		Inner: &RootTypeWrapper{Name: "inner", Session: session},
+	}
+	sessionType := reflect.TypeOf(RootTypeSession{})
+
+	expected := `describe.RootTypeSession<User="bob">`
+	actual := DescribeOpts(v, WithRootType(sessionType))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	v.Session = &RootTypeSession{User: "alice"}
+	expected = `describe.RootTypeSession[describe.RootTypeSession<User="bob"> describe.RootTypeSession<User="alice">]`
+	actual = DescribeOpts(v, WithRootType(sessionType))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = D(1)
+	actual = DescribeOpts(1, WithRootType(sessionType))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type ReflectValueContainers struct {
+	Exported   []reflect.Value
+	unexported []reflect.Value
+	Map        map[string]reflect.Value
+	unexpMap   map[string]reflect.Value
+	Array      [2]reflect.Value
+}
+
+type ReflectValueCycle struct {
+	Values []reflect.Value
+}
+
+func TestReflectValueContainers(t *testing.T) {
+	results := reflect.ValueOf(func() (int, string) { return 1, "a" }).Call(nil)
+	hidden := struct{ a int }{5}
+	v := ReflectValueContainers{
+		Exported:   results,
+		unexported: []reflect.Value{reflect.ValueOf(2)},
+		Map:        map[string]reflect.Value{"x": reflect.ValueOf(hidden).Field(0)},
+		unexpMap:   map[string]reflect.Value{"y": reflect.ValueOf(3)},
+		Array:      [2]reflect.Value{reflect.ValueOf([]int{4})},
+	}
+
+	expectedExported := `Exported=reflect.Value[reflect.Value<1> reflect.Value<"a">]`
+	expectedMap := `Map=string:reflect.Value{"x"=reflect.Value<5>}`
+	expectedArray := `Array=reflect.Value[reflect.Value<int[4]> reflect.Value<invalid>]`
+	actual := Describe(v, 0)
+	for _, expected := range []string{expectedExported, expectedMap, expectedArray} {
+		if !strings.Contains(actual, expected) {
+			t.Errorf("Expected %v to contain %v", actual, expected)
+		}
+	}
+
+	if canExposeInterface() {
+		for _, expected := range []string{
+			`unexported=reflect.Value[reflect.Value<2>]`,
+			`unexpMap=string:reflect.Value{"y"=reflect.Value<3>}`,
+		} {
+			if !strings.Contains(actual, expected) {
+				t.Errorf("Expected %v to contain %v", actual, expected)
+			}
+		}
+	} else {
+		for _, expected := range []string{
+			`unexported=reflect.Value[reflect.Value<{0x`,
+			`unexpMap=string:reflect.Value{"y"=reflect.Value<{0x`,
+		} {
+			if !strings.Contains(actual, expected) {
+				t.Errorf("Expected %v to contain %v", actual, expected)
+			}
+		}
+	}
+}
+
+func TestReflectValueCycle(t *testing.T) {
+	v := &ReflectValueCycle{}
+	v.Values = []reflect.Value{reflect.ValueOf(v)}
+
+	expected := `*1~describe.ReflectValueCycle<Values=reflect.Value[reflect.Value<*$1>]>`
+	actual := Describe(v, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type MultiError struct {
+	errs []error
+}
+
+func (this *MultiError) Error() string {
+	return fmt.Sprintf("%v errors", len(this.errs))
+}
+
+func (this *MultiError) Unwrap() []error {
+	return this.errs
+}
+
+type SimpleError struct {
+	Message string
+}
+
+func (this SimpleError) Error() string {
+	return this.Message
+}
+
+type PanickingMultiError struct {
+	Count int
+}
+
+func (this PanickingMultiError) Error() string {
+	return "panics"
+}
+
+func (this PanickingMultiError) Unwrap() []error {
+	panic("unwrap failed")
+}
+
+func TestMultiError(t *testing.T) {
+	v := &MultiError{errs: []error{
+		SimpleError{"a"},
+		&MultiError{errs: []error{SimpleError{"b"}, nil}},
+	}}
+
+	expected := `*describe.MultiError<describe.SimpleError<Message="a"> *describe.MultiError<describe.SimpleError<Message="b"> nil>>`
+	actual := Describe(v, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.MultiError<
+    describe.SimpleError<
+        Message = "a"
+    >
+    *describe.MultiError<
+        describe.SimpleError<
+            Message = "b"
+        >
+        nil
+    >
+>`
+	actual = Describe(v, 4)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestMultiErrorCycle(t *testing.T) {
+	v := &MultiError{}
+	v.errs = []error{v}
+
+	expected := `*1~describe.MultiError<*$1>`
+	actual := Describe(v, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestPanickingMultiError(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	expected := `describe.PanickingMultiError<Count=1>`
+	actual := Describe(PanickingMultiError{Count: 1}, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type WrappingError struct {
+	context string
+	err     error
+}
+
+func (this *WrappingError) Error() string {
+	return this.context + ": " + this.err.Error()
+}
+
+func (this *WrappingError) Unwrap() error {
+	return this.err
+}
+
+func TestErrorChains(t *testing.T) {
+	v := &WrappingError{"load", &MultiError{errs: []error{
+		SimpleError{"a"},
+		&WrappingError{"parse", SimpleError{"b"}},
+	}}}
+
+	expected := `*describe.WrappingError<Error="load: 2 errors" Unwrap=*describe.MultiError<Error="2 errors" Unwrap=error[@describe.SimpleError<Error="a"> @*describe.WrappingError<Error="parse: b" Unwrap=describe.SimpleError<Error="b">>]>>`
+	actual := DescribeOpts(v, WithErrorChains(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.WrappingError<
+    Error = "parse: b"
+    Unwrap = describe.SimpleError<
+        Error = "b"
+    >
+>`
+	actual = DescribeOpts(&WrappingError{"parse", SimpleError{"b"}}, WithErrorChains(true), WithIndent(4))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.WrappingError<context="parse" err=@describe.SimpleError<Message="b">>`
+	actual = D(&WrappingError{"parse", SimpleError{"b"}})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type SelfWrappingError struct {
+	Code int
+}
+
+func (this *SelfWrappingError) Error() string {
+	return "self"
+}
+
+func (this *SelfWrappingError) Unwrap() error {
+	return this
+}
+
+func TestErrorChainsCycle(t *testing.T) {
+	v := &MultiError{}
+	v.errs = []error{v}
+
+	expected := `*1~describe.MultiError<Error="1 errors" Unwrap=error[@*$1]>`
+	actual := DescribeOpts(v, WithErrorChains(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.SelfWrappingError<Error="self" Unwrap=*$^>`
+	actual = DescribeOpts(&SelfWrappingError{}, WithErrorChains(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestErrorChainsPanic(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	expected := `describe.PanickingMultiError<Count=1>`
+	actual := DescribeOpts(PanickingMultiError{Count: 1}, WithErrorChains(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type StackField []uintptr
+
+type FieldStackError struct {
+	message string
+	stack   *StackField
+}
+
+func (this *FieldStackError) Error() string {
+	return this.message
+}
+
+type StackFrame uintptr
+
+type MethodStackError struct {
+	pcs []uintptr
+}
+
+func (this MethodStackError) Error() string {
+	return "from method"
+}
+
+func (this MethodStackError) StackTrace() []StackFrame {
+	frames := make([]StackFrame, len(this.pcs))
+	for i, pc := range this.pcs {
+		frames[i] = StackFrame(pc)
+	}
+	return frames
+}
+
+func captureStack() []uintptr {
+	pcs := make([]uintptr, 32)
+	return pcs[:runtime.Callers(2, pcs)]
+}
+
+func TestErrorStackField(t *testing.T) {
+	stack := StackField(captureStack())
+	v := &FieldStackError{message: "boom", stack: &stack}
+
+	// Without the option, the raw contents are described
+	actual := Describe(v, 0)
+	expectedPrefix := `*describe.FieldStackError<message="boom" stack=*uintptr[`
+	if !strings.HasPrefix(actual, expectedPrefix) {
+		t.Errorf("Expected %v to start with %v", actual, expectedPrefix)
+	}
+
+	expectedPrefix = `*describe.FieldStackError<
+    Error = "boom"
+    Stack = [
+        go-describe.TestErrorStackField(describe_test.go:`
+	actual = DescribeOpts(v, WithIndent(4), WithErrorStacks(100))
+	if !strings.HasPrefix(actual, expectedPrefix) {
+		t.Errorf("Expected %v to start with %v", actual, expectedPrefix)
+	}
+	if strings.Contains(actual, tokElided) {
+		t.Errorf("Expected no elided frames in %v", actual)
+	}
+}
+
+func TestErrorStackMethod(t *testing.T) {
+	v := MethodStackError{pcs: captureStack()}
+
+	expectedPrefix := `describe.MethodStackError<Error="from method" Stack=[go-describe.TestErrorStackMethod(describe_test.go:`
+	expectedSuffix := ` …]>`
+	actual := DescribeOpts(v, WithErrorStacks(1))
+	if !strings.HasPrefix(actual, expectedPrefix) || !strings.HasSuffix(actual, expectedSuffix) {
+		t.Errorf("Expected %v to start with %v and end with %v", actual, expectedPrefix, expectedSuffix)
+	}
+	if strings.Count(actual, "(") != 1 {
+		t.Errorf("Expected only one frame in %v", actual)
+	}
+}
+
+type WrappedStackError struct {
+	err MethodStackError
+}
+
+func TestErrorStackMethodUnexported(t *testing.T) {
+	if !canExposeInterface() {
+		t.Skip("Unexported errors can't be called on safe builds")
+	}
+	var panics []interface{}
+	SetPanicHandler(func(panicType reflect.Type, e interface{}, stack []byte) {
+		panics = append(panics, e)
+	})
+	defer SetPanicHandler(nil)
+
+	v := WrappedStackError{err: MethodStackError{pcs: captureStack()}}
+	expectedPrefix := `describe.WrappedStackError<err=describe.MethodStackError<Error="from method" Stack=[go-describe.TestErrorStackMethodUnexported(describe_test.go:`
+	actual := DescribeOpts(v, WithErrorStacks(1))
+	if !strings.HasPrefix(actual, expectedPrefix) {
+		t.Errorf("Expected %v to start with %v", actual, expectedPrefix)
+	}
+	if len(panics) != 0 {
+		t.Errorf("Expected no panics but got %v", panics)
+	}
+}
+
+type CustomDescribed struct {
+	Value int
+}
+
+type UnexportedCustomDescribed struct {
+	Exported   CustomDescribed
+	unexported CustomDescribed
+	pointer    *CustomDescribed
+}
+
+func TestUnexportedCustomDescriber(t *testing.T) {
+	customType := reflect.TypeOf(CustomDescribed{})
+	SetCustomDescriber(customType, func(v reflect.Value) string {
+		if !v.CanInterface() {
+			return fmt.Sprintf("unexported<%v>", v.Field(0).Int())
+		}
+		return fmt.Sprintf("custom<%v>", v.Interface().(CustomDescribed).Value)
+	})
+	defer SetCustomDescriber(customType, nil)
+
+	v := UnexportedCustomDescribed{
+		Exported:   CustomDescribed{1},
+		unexported: CustomDescribed{2},
+		pointer:    &CustomDescribed{3},
+	}
+
+	expected := `describe.UnexportedCustomDescribed<Exported=custom<1> unexported=custom<2> pointer=*custom<3>>`
+	if !canExposeInterface() {
+		expected = `describe.UnexportedCustomDescribed<Exported=custom<1> unexported=unexported<2> pointer=*unexported<3>>`
+	}
+	actual := Describe(v, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.UnexportedCustomDescribed<Exported=custom<1> unexported=describe.CustomDescribed<Value=2> pointer=*describe.CustomDescribed<Value=3>>`
+	actual = DescribeOpts(v, WithUnexportedDescribers(false))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type IdentifiedUser struct {
+	Name string `describe:"id"`
+	Age  int
+}
+
+type IdentifiedUsers struct {
+	Owner  *IdentifiedUser
+	Admins []*IdentifiedUser
+}
+
+func TestReferenceLabel(t *testing.T) {
+	user := &IdentifiedUser{Name: "alice", Age: 30}
+	v := IdentifiedUsers{Owner: user, Admins: []*IdentifiedUser{user}}
+
+	expected := `describe.IdentifiedUsers<Owner=*1~describe.IdentifiedUser<Name="alice" Age=30> Admins=*describe.IdentifiedUser[*$1("alice")]>`
+	actual := Describe(v, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type TaggedAccount struct {
+	User     string `describe:"user,id"`
+	Password string `describe:",redact"`
+	Session  string `describe:"-"`
+	Quota    int    `describe:"quota_mb"`
+}
+
+type SecretIdentity struct {
+	Key  string `describe:"id,redact"`
+	Note string
+}
+
+func TestDescribeTags(t *testing.T) {
+	v := TaggedAccount{User: "alice", Password: "hunter2", Session: "abc", Quota: 10}
+	expected := `describe.TaggedAccount<user="alice" Password=*** quota_mb=10>`
+	actual := Describe(v, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `{"$type":"describe.TaggedAccount","user":"alice","Password":"***","quota_mb":10}`
+	actual = DescribeJSON(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	account := &v
+	expected = `*describe.TaggedAccount[*1~describe.TaggedAccount<user="alice" Password=*** quota_mb=10> *$1("alice")]`
+	actual = Describe([]*TaggedAccount{account, account}, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	secret := &SecretIdentity{Key: "k", Note: "n"}
+	expected = `*describe.SecretIdentity[*1~describe.SecretIdentity<Key=*** Note="n"> *$1]`
+	actual = Describe([]*SecretIdentity{secret, secret}, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type SortedSlices struct {
+	Ints    []int
+	Structs []MapKeyStruct
+	Array   [3]int
+}
+
+func TestSortedSlices(t *testing.T) {
+	v := SortedSlices{
+		Ints:    []int{10, 2, 33, -1},
+		Structs: []MapKeyStruct{{3}, {1}, {2}},
+		Array:   [3]int{3, 2, 1},
+	}
+
+	expected := `describe.SortedSlices<Ints=int[-1 2 10 33] Structs=describe.MapKeyStruct[describe.MapKeyStruct<A=1> describe.MapKeyStruct<A=2> describe.MapKeyStruct<A=3>] Array=int[3 2 1]>`
+	actual := DescribeOpts(v, WithSortedSlices(nil))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	descending := func(a, b reflect.Value) bool {
+		if a.Kind() == reflect.Int {
+			return a.Int() > b.Int()
+		}
+		return false
+	}
+	expected = `describe.SortedSlices<Ints=int[33 10 2 -1] Structs=describe.MapKeyStruct[describe.MapKeyStruct<A=3> describe.MapKeyStruct<A=1> describe.MapKeyStruct<A=2>] Array=int[3 2 1]>`
+	actual = DescribeOpts(v, WithSortedSlices(descending))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestSortedSlicesPaths(t *testing.T) {
+	annotator := func(path string, v reflect.Value) string {
+		return path
+	}
+	expected := `int[1 [[2]] 2 [[1]] 3 [[0]]]`
+	actual := DescribeOpts([]int{3, 2, 1}, WithSortedSlices(nil), WithAnnotator(annotator))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestSet(t *testing.T) {
+	expected := `set[string]{"a" "b" "c"}`
+	actual := DescribeOpts(map[string]struct{}{"c": {}, "a": {}, "b": {}}, WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `set[int]{1 2}`
+	actual = DescribeOpts(map[int]bool{2: true, 1: true}, WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "set[int]{\n    1\n}"
+	actual = Describe(map[int]struct{}{1: {}}, 4)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `set[string]{}`
+	actual = Describe(map[string]struct{}{}, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestBoolMapNotSet(t *testing.T) {
+	expected := `int:bool{1=false}`
+	actual := Describe(map[int]bool{1: false}, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `int:bool{}`
+	actual = Describe(map[int]bool{}, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestElidedSet(t *testing.T) {
+	expected := `set[int]{…}`
+	actual := DescribeOpts(map[int]struct{}{1: {}, 2: {}, 3: {}}, WithBreadthFirstBudget(14))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type ReflectionOnlyTest struct {
+	Stringer *CountedStringer
+	Float    big.Float
+	Err      error
+	Keys     map[CountedStringer]int
+}
+
+func TestReflectionOnly(t *testing.T) {
+	countedStringerCalls = 0
+	stringer := &CountedStringer{"abc"}
+	v := ReflectionOnlyTest{
+		Stringer: stringer,
+		Err:      &MultiError{errs: []error{SimpleError{"x"}}},
+		Keys:     map[CountedStringer]int{{"b"}: 2, {"a"}: 1},
+	}
+	intType := reflect.TypeOf(1)
+	SetCustomDescriber(intType, func(v reflect.Value) string { panic("describer called") })
+	defer SetCustomDescriber(intType, nil)
+
+	expected := `*describe.CountedStringer<Name="abc">`
+	actual := DescribeOpts(stringer, WithReflectionOnly(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.SimpleError<Message="x">`
+	actual = DescribeOpts(SimpleError{"x"}, WithReflectionOnly(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.MultiError<errs=error[@describe.SimpleError<Message="x">]>`
+	actual = DescribeOpts(v.Err, WithReflectionOnly(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.Ring<elements=interface[@1] start=0>`
+	actual = DescribeOpts(&Ring{elements: []interface{}{1}}, WithReflectionOnly(true), WithErrorStacks(5))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	DescribeOpts(v, WithReflectionOnly(true), WithErrorStacks(5), WithSortedMapKeys(true), WithIndent(4))
+	if countedStringerCalls != 0 {
+		t.Errorf("Expected String() not to be called but was called %v times", countedStringerCalls)
+	}
+}
+
+func TestMapSampling(t *testing.T) {
+	m := make(map[int]string)
+	for i := 0; i < 100; i++ {
+		m[i] = "x"
+	}
+
+	expected := `int:string{0="x" 1="x" 2="x" …(+97 more entries)}`
+	actual := DescribeOpts(m, WithMapSampling(3, MapSampleFirstSorted))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `int:string{
+    0 = "x"
+    …(+99 more entries)
+}`
+	actual = DescribeOpts(m, WithMapSampling(1, MapSampleFirstSorted), WithIndent(4))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	set := make(map[int]struct{})
+	for i := 0; i < 20; i++ {
+		set[i] = struct{}{}
+	}
+	expected = `set[int]{0 1 …(+18 more entries)}`
+	actual = DescribeOpts(set, WithMapSampling(2, MapSampleFirstSorted))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestMapSamplingRandom(t *testing.T) {
+	m := make(map[int]string)
+	for i := 0; i < 100; i++ {
+		m[i] = "x"
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		description := DescribeOpts(m, WithMapSampling(5, MapSampleRandom))
+		if !strings.HasSuffix(description, ` …(+95 more entries)}`) {
+			t.Errorf("Expected 95 omitted entries but got %v", description)
+		}
+		if strings.Count(description, `="x"`) != 5 {
+			t.Errorf("Expected 5 entries but got %v", description)
+		}
+		seen[description] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Expected different samples but always got %v", seen)
+	}
+}
+
+func TestTypeKeyedMap(t *testing.T) {
+	registry := map[reflect.Type]string{
+		reflect.TypeOf(""):                   "string",
+		reflect.TypeOf([]int{}):              "slice",
+		reflect.TypeOf(DescriberTest{}):      "struct",
+		reflect.TypeOf(map[string]int{}):     "map",
+		reflect.Type(nil):                    "nil",
+		reflect.TypeOf((*error)(nil)).Elem(): "error",
+	}
+
+	expected := `reflect.Type:string{nil="nil" []int="slice" describe.DescriberTest="struct" ` +
+		`error="error" map[string]int="map" string="string"}`
+	actual := Describe(registry, 0)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+	actual = DescribeOpts(registry, WithUnsafeOperations(false))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestMapContents(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2}
+
+	expected := `string:int{"a" "b"}`
+	actual := DescribeOpts(m, WithMapContents(MapKeysOnly), WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `string:int{1 2}`
+	actual = DescribeOpts(m, WithMapContents(MapValuesOnly), WithSortedMapKeys(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `string:int{
+    "a"
+    "b"
+}`
+	actual = DescribeOpts(m, WithMapContents(MapKeysOnly), WithSortedMapKeys(true), WithIndent(4))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `["a"] ["b"]`
+	var paths []string
+	annotator := func(path string, v reflect.Value) string {
+		if path != "" {
+			paths = append(paths, path)
+		}
+		return ""
+	}
+	DescribeOpts(m, WithMapContents(MapValuesOnly), WithSortedMapKeys(true), WithAnnotator(annotator))
+	actual = strings.Join(paths, " ")
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type RegisteredStringer int
+
+func (this RegisteredStringer) String() string {
+	return fmt.Sprintf("#%d", int(this))
+}
+
+type RegisteredStringerField struct {
+	id RegisteredStringer
+}
+
+type RegisteredSelfPointer *RegisteredSelfPointer
+
+func TestRegisterStringersFromValues(t *testing.T) {
+	RegisterStringersFromValues(RegisteredStringer(0), &PointerReceiverStringer{})
+	defer SetCustomDescriber(reflect.TypeOf(RegisteredStringer(0)), nil)
+	defer SetCustomDescriber(reflect.TypeOf(PointerReceiverStringer{}), nil)
+
+	expected := `describe.RegisteredStringer<#0>`
+	actual := D(RegisteredStringer(0))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.PointerReceiverStringer<value 0>`
+	actual = D(PointerReceiverStringer{})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.RegisteredStringerField<id=describe.RegisteredStringer<#1>>`
+	if !canExposeInterface() {
+		expected = `describe.RegisteredStringerField<id=1>`
+	}
+	actual = D(RegisteredStringerField{id: 1})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("Expected a panic for a type without a String() method")
+		}
+	}()
+	RegisterStringersFromValues(InnerStruct{})
+}
+
+func TestRegisterStringersFromSelfPointer(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("Expected a panic for a type without a String() method")
+		}
+	}()
+	RegisterStringersFromValues(RegisteredSelfPointer(nil))
+}
+
+func TestStringerFallback(t *testing.T) {
+	v := []interface{}{RegisteredStringer(0), RegisteredStringer(1), PointerReceiverStringer{}}
+	expected := `interface[@#0 @describe.RegisteredStringer<#1> @describe.PointerReceiverStringer<value=0>]`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `interface[@describe.RegisteredStringer<#0> @describe.RegisteredStringer<#1> @describe.PointerReceiverStringer<value 0>]`
+	actual = DescribeOpts(v, WithStringerFallback(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type GoStringerPoint struct {
+	X int
+	Y int
+}
+
+func (this GoStringerPoint) GoString() string {
+	return fmt.Sprintf("&pb.Point{X: %v, Y: %v}", this.X, this.Y)
+}
+
+type PointerReceiverGoStringer struct {
+	Name string
+}
+
+func (this *PointerReceiverGoStringer) GoString() string {
+	return fmt.Sprintf("pb.Name(%q)", this.Name)
+}
+
+type PanickingGoStringer struct {
+	Value int
+}
+
+func (this PanickingGoStringer) GoString() string {
+	panic("GoString panic")
+}
+
+func TestGoStringers(t *testing.T) {
+	v := []interface{}{GoStringerPoint{X: 1, Y: 2}, GoStringerPoint{}, &PointerReceiverGoStringer{Name: "a"}}
+	expected := `interface[@describe.GoStringerPoint<X=1 Y=2> @describe.GoStringerPoint<X=0 Y=0> @*describe.PointerReceiverGoStringer<Name="a">]`
+	actual := D(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `interface[@describe.GoStringerPoint<&pb.Point{X: 1, Y: 2}> @describe.GoStringerPoint<X=0 Y=0> @*describe.PointerReceiverGoStringer<pb.Name("a")>]`
+	actual = DescribeOpts(v, WithGoStringers(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `interface[@describe.GoStringerPoint<&pb.Point{X: 1, Y: 2}> @describe.GoStringerPoint<&pb.Point{X: 0, Y: 0}> @*describe.PointerReceiverGoStringer<pb.Name("a")>]`
+	actual = DescribeOpts(v, WithGoStringers(true), WithStringerFallback(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `describe.GoStringerPoint<X=1 Y=2>`
+	actual = DescribeOpts(GoStringerPoint{X: 1, Y: 2}, WithGoStringers(true), WithReflectionOnly(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestGoStringerPanic(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	expected := `describe.PanickingGoStringer<Value=1>`
+	actual := DescribeOpts(PanickingGoStringer{Value: 1}, WithGoStringers(true))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type MaxDepthTest struct {
+	Name     string
+	Children []*MaxDepthTest
+	Tags     map[string]int
+}
+
+func TestMaxDepth(t *testing.T) {
+	v := &MaxDepthTest{
+		Name: "root",
+		Children: []*MaxDepthTest{
+			{Name: "child", Children: []*MaxDepthTest{{Name: "grandchild"}}},
+		},
+		Tags: map[string]int{"a": 1},
+	}
+
+	expected := `*describe.MaxDepthTest<…>`
+	actual := DescribeOpts(v, WithMaxDepth(0))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.MaxDepthTest<Name="root" Children=*describe.MaxDepthTest[…] Tags=string:int{…}>`
+	actual = DescribeOpts(v, WithMaxDepth(1))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.MaxDepthTest<Name="root" Children=*describe.MaxDepthTest[*describe.MaxDepthTest<…>] Tags=string:int{"a"=1}>`
+	actual = DescribeOpts(v, WithMaxDepth(2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `*describe.MaxDepthTest<Name="root" Children=*describe.MaxDepthTest[…] Tags=string:int{…}>`
+	actual = DescribeOpts(v, WithMaxDepth(1), WithBreadthFirstBudget(1000))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestStringQuoting(t *testing.T) {
+	v := []string{"abc", `say "hi"`, "a b", "nil", "9lives", "x`\"y"}
+
+	expected := `string["abc" "say "hi"" "a b" "nil" "9lives" "x` + "`" + `"y"]`
+	actual := DescribeOpts(v, WithStringQuoting(QuoteDouble))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `string['abc' 'say "hi"' 'a b' 'nil' '9lives' 'x` + "`" + `"y']`
+	actual = DescribeOpts(v, WithStringQuoting(QuoteSingle))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "string[\"abc\" `say \"hi\"` \"a b\" \"nil\" \"9lives\" \"x`\"y\"]"
+	actual = DescribeOpts(v, WithStringQuoting(QuoteBackticksIfNeeded))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "string[abc `say \"hi\"` \"a b\" \"nil\" \"9lives\" \"x`\"y\"]"
+	actual = DescribeOpts(v, WithStringQuoting(QuoteNonIdentifiers))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestMaxElements(t *testing.T) {
+	v := make([]int, 10000)
+	for i := range v {
+		v[i] = i
+	}
+
+	expected := "int[0 1 2 …(+9997 more elements)]"
+	actual := DescribeOpts(v, WithMaxElements(3))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "int[0 1]"
+	actual = DescribeOpts([2]int{0, 1}, WithMaxElements(2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `int:string{1="a" 2="b" …(+2 more entries)}`
+	actual = DescribeOpts(map[int]string{4: "d", 2: "b", 3: "c", 1: "a"}, WithMaxElements(2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "int[\n    0\n    1\n    …(+9998 more elements)\n]"
+	actual = DescribeOpts(v, WithMaxElements(2), WithIndent(4))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestMaxStringLength(t *testing.T) {
+	v := []string{"PCFET0NUWVBFIGh0bWw+", "short", "日本語のテキスト"}
+
+	expected := `string["PCFET…"(len=20) "short" "日本語のテ…"(len=24)]`
+	actual := DescribeOpts(v, WithMaxStringLength(5))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `string["PCFET…"(len=20) short "日本語のテ…"(len=24)]`
+	actual = DescribeOpts(v, WithMaxStringLength(5), WithStringQuoting(QuoteNonIdentifiers))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestMaxOutputBytes(t *testing.T) {
+	v := make([]int, 1000000)
+
+	expected := "int[0 0 0 0 0 …"
+	actual := DescribeOpts(v, WithMaxOutputBytes(14))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `string["日本…`
+	actual = DescribeOpts([]string{"日本語"}, WithMaxOutputBytes(15))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "int[0 0]"
+	actual = DescribeOpts(v[:2], WithMaxOutputBytes(8))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "int[0 0 0 0 0 …"
+	buffer := strings.Builder{}
+	if err := DescribeTo(&buffer, v, WithMaxOutputBytes(14)); err != nil {
+		t.Error(err)
+	}
+	actual = buffer.String()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeValue(t *testing.T) {
+	v := RecursiveStruct{}
+	v.RecursivePtr = &v
+
+	expected := `1~describe.RecursiveStruct<IntVal=0 RecursivePtr=*$1 data=nil>`
+	actual := DescribeValue(reflect.ValueOf(&v).Elem())
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "int[\n  1\n  2\n]"
+	actual = DescribeValue(reflect.ValueOf([]int{1, 2}), WithIndent(2))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestSortedMapKeys(t *testing.T) {
+	v := map[interface{}]int{"b": 1, 10: 2, "a": 3, 2: 4, 1.5: 5}
+
+	expected := `interface:int{@2=4 @10=2 @1.5=5 @"a"=3 @"b"=1}`
+	for i := 0; i < 10; i++ {
+		actual := DescribeOpts(v, WithSortedMapKeys(true))
+		if actual != expected {
+			t.Errorf("Expected %v but got %v", expected, actual)
+		}
+	}
+}
+
+func TestDescribeAddressable(t *testing.T) {
+	v := RecursiveStruct{}
+	v.RecursivePtr = &v
+
+	expected := `1~describe.RecursiveStruct<IntVal=0 RecursivePtr=*$1 data=nil>`
+	actual := DescribeAddressable(&v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `int[1 2]`
+	actual = DescribeAddressable([]int{1, 2})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	var nilPtr *RecursiveStruct
+	expected = `nil`
+	actual = DescribeAddressable(nilPtr)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type ReentrantNode struct {
+	Name     string
+	Parent   *ReentrantNode
+	Children []*ReentrantNode
+}
+
+var describeReentrantNode CustomDescriber
+
+func init() {
+	describeReentrantNode = func(v reflect.Value) string {
+		node := v.Interface().(*ReentrantNode)
+		return fmt.Sprintf("node(%v parent=%v children=%v)", node.Name,
+			DescribeOpts(node.Parent, WithCustomDescriber(v.Type(), describeReentrantNode)),
+			DescribeOpts(node.Children, WithCustomDescriber(v.Type(), describeReentrantNode)))
+	}
+}
+
+type ReentrantStringer struct {
+	Next *ReentrantStringer
+}
+
+func (this *ReentrantStringer) String() string {
+	return D(this.Next)
+}
+
+func TestReentrantCustomDescriber(t *testing.T) {
+	root := &ReentrantNode{Name: "root"}
+	root.Children = []*ReentrantNode{{Name: "child", Parent: root}}
+
+	expected := `1~node(root parent=nil children=1~*describe.ReentrantNode[node(child parent=$^ children=nil)])`
+	actual := DescribeOpts(root, WithCustomDescriber(reflect.TypeOf(root), describeReentrantNode))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	a := &ReentrantStringer{}
+	a.Next = &ReentrantStringer{Next: a}
+	expected = `1~*describe.ReentrantStringer<1~*describe.ReentrantStringer<$^>>`
+	actual = D(a)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestReentrantCustomDescriberWithTimeout(t *testing.T) {
+	root := &ReentrantNode{Name: "root"}
+	root.Parent = root
+
+	expected := `1~node(root parent=$^ children=nil)`
+	actual := DescribeOpts(root, WithCustomDescriber(reflect.TypeOf(root), describeReentrantNode), WithDescriberTimeout(time.Second))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+type RangePercent int
+
+type RangeTelemetry struct {
+	Temperature float64
+	Humidity    *float64
+	Battery     RangePercent
+	Readings    []float64
+}
+
+func TestValidRanges(t *testing.T) {
+	humidity := 120.0
+	v := RangeTelemetry{
+		Temperature: 412.5,
+		Humidity:    &humidity,
+		Battery:     101,
+		Readings:    []float64{500},
+	}
+	telemetryType := reflect.TypeOf(v)
+	opts := []Option{
+		WithValidFieldRange(telemetryType, "Temperature", -40, 125),
+		WithValidFieldRange(telemetryType, "Humidity", 0, 100),
+		WithValidRange(reflect.TypeOf(RangePercent(0)), 0, 100),
+	}
+
+	expected := `describe.RangeTelemetry<Temperature=!412.5 Humidity=*!120 Battery=!101 Readings=float64[500]>`
+	actual := DescribeOpts(v, opts...)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	v = RangeTelemetry{Temperature: 20, Battery: 50}
+	expected = `describe.RangeTelemetry<Temperature=20 Humidity=nil Battery=50 Readings=nil>`
+	actual = DescribeOpts(v, opts...)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
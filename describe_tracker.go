@@ -0,0 +1,43 @@
+package describe
+
+import (
+	"sync"
+)
+
+// Tracker describes an evolving object as a series of compact deltas, which
+// is useful for logging how state is built up over time.
+type Tracker struct {
+	mutex       sync.Mutex
+	value       interface{}
+	opts        []Option
+	snapshot    string
+	hasSnapshot bool
+}
+
+// Create a tracker for v, which should be a pointer (or other reference type)
+// so that changes to it are visible. opts are used for every description.
+func NewTracker(v interface{}, opts ...Option) *Tracker {
+	return &Tracker{
+		value: v,
+		opts:  opts,
+	}
+}
+
+// Describe what changed in the tracked object since the last call to
+// Changes(), as a single line of changed paths and their new values:
+// `Server.Port=8080 +Tags[2]="new" -Env["OLD"]`
+//
+// The first call describes the whole object. Returns an empty string if
+// nothing changed.
+func (this *Tracker) Changes() string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	snapshot := DescribeOpts(this.value, this.opts...)
+	previous, hadSnapshot := this.snapshot, this.hasSnapshot
+	this.snapshot, this.hasSnapshot = snapshot, true
+	if !hadSnapshot {
+		return snapshot
+	}
+	return describeChanges(previous, snapshot)
+}
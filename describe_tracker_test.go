@@ -0,0 +1,55 @@
+package describe
+
+import (
+	"testing"
+)
+
+type TrackedServer struct {
+	Port int
+}
+
+type TrackedState struct {
+	Name   string
+	Server *TrackedServer
+	Tags   []string
+	Env    map[string]string
+}
+
+func assertChanges(t *testing.T, tracker *Tracker, expected string) {
+	actual := tracker.Changes()
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	state := &TrackedState{
+		Name:   "a",
+		Server: &TrackedServer{Port: 80},
+		Tags:   []string{"x", "y"},
+		Env:    map[string]string{"OLD": "1"},
+	}
+	tracker := NewTracker(state)
+
+	assertChanges(t, tracker, `*describe.TrackedState<Name="a" Server=*describe.TrackedServer<Port=80> Tags=string["x" "y"] Env=string:string{"OLD"="1"}>`)
+	assertChanges(t, tracker, "")
+
+	state.Server.Port = 8080
+	state.Tags = append(state.Tags, "z")
+	state.Env = map[string]string{"NEW": "2"}
+	assertChanges(t, tracker, `Server.Port=8080 +Tags[2]="z" -Env["OLD"] +Env["NEW"]="2"`)
+
+	state.Tags = []string{"y", "z"}
+	state.Server = nil
+	assertChanges(t, tracker, `Server=nil -Tags[0]`)
+	assertChanges(t, tracker, "")
+}
+
+func TestTrackerReplacedValue(t *testing.T) {
+	value := []int{1}
+	tracker := NewTracker(&value)
+	tracker.Changes()
+
+	value = nil
+	assertChanges(t, tracker, "*nil")
+}
@@ -0,0 +1,121 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// A node in a tree describing an object. See DescribeTree().
+type Node struct {
+	// The kind of value, after following pointers and interfaces
+	// (reflect.Invalid for nil).
+	Kind reflect.Kind
+	// The value's type name, as in descriptions: `map[string]int`
+	TypeName string
+	// How this node is reached from its parent: a field name, an index
+	// (`[2]`), or a map key (`["a"]`). Empty for the root node.
+	Key string
+	// The description of a value that has no children, such as `42`, `"abc"`,
+	// `nil` or `time.Duration<1s>`. Empty for structs, arrays, slices and maps.
+	Value    string
+	Children []*Node
+	// The number of elements or entries left out (see WithMaxElements() and
+	// WithMapSampling()).
+	Omitted int
+	// If non-zero, the data this node describes occurs more than once. The
+	// first node to describe it has the full contents, and later nodes have
+	// no children and IsReference set.
+	ReferenceID int
+	IsReference bool
+}
+
+// Describes an object as a tree of nodes rather than as text, for tools built
+// on this package (such as diff viewers, UIs and filters) that would otherwise
+// have to parse descriptions.
+//
+// Pointers and interfaces are followed transparently. Structs, arrays, slices
+// and maps have a child per field, element or entry, and everything else is a
+// leaf whose Value is its regular (single line) description. Values described
+// by user code (custom describers, String() methods) are also leaves.
+func DescribeTree(v interface{}, opts ...Option) (root *Node) {
+	context := describer{}
+	context.applyOptions(opts)
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.describeTree(rv)
+}
+
+func (this *describer) describeTree(rv reflect.Value) (root *Node) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				root = &Node{Value: notifyRecoveredLibraryBug(e)}
+			}
+		}
+	}()
+
+	this.referenceNames = this.findDuplicates(rv)
+	this.seenReferences = make(map[duplicates.TypedPointer]bool)
+	return this.describeTreeNode("", rv)
+}
+
+func (this *describer) describeTreeNode(key string, v reflect.Value) *Node {
+	v = followPointers(v, this.isDescribedByUserCode)
+
+	node := &Node{Key: key}
+	if !v.IsValid() {
+		node.Value = this.describeWithUserCode(v)
+		return node
+	}
+	node.Kind = v.Kind()
+	node.TypeName = this.typeName(v.Type())
+	if isNil(v) || this.isDescribedByUserCode(v) {
+		node.Value = this.describeWithUserCode(v)
+		return node
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+	default:
+		node.Value = this.describeWithUserCode(v)
+		return node
+	}
+
+	if ptr, ok := getReferencePointer(v); ok {
+		if referenceName, isReferenced := this.referenceNames[ptr]; isReferenced {
+			if this.seenReferences[ptr] {
+				node.ReferenceID = referenceName
+				node.IsReference = true
+				return node
+			}
+			this.seenReferences[ptr] = true
+			node.ReferenceID = this.assignReferenceName(ptr)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, i := range getVisibleFieldIndices(v) {
+			node.Children = append(node.Children, this.describeTreeNode(getFieldName(v.Type(), i), this.getDescribedField(v, i)))
+		}
+	case reflect.Array, reflect.Slice:
+		count := this.getElementCountToDescribe(v.Len())
+		for i := 0; i < count; i++ {
+			node.Children = append(node.Children, this.describeTreeNode(fmt.Sprintf("[%v]", i), v.Index(i)))
+		}
+		node.Omitted = v.Len() - count
+	case reflect.Map:
+		iter, omittedCount := this.iterateMap(v)
+		for iter.Next() {
+			key := "[" + this.describeWithUserCode(iter.Key()) + "]"
+			node.Children = append(node.Children, this.describeTreeNode(key, this.getDescribedMapValue(iter.Key(), iter.Value())))
+		}
+		node.Omitted = omittedCount
+	}
+	return node
+}
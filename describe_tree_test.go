@@ -0,0 +1,71 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TreeNode struct {
+	Name    string
+	Next    *TreeNode
+	Scores  map[string]int
+	Timeout time.Duration
+}
+
+func flattenTree(node *Node) string {
+	s := fmt.Sprintf("%v(%v %v)", node.Key, node.Kind, node.TypeName)
+	if node.ReferenceID != 0 {
+		s += fmt.Sprintf("#%v", node.ReferenceID)
+		if node.IsReference {
+			s += "ref"
+		}
+	}
+	if node.Value != "" {
+		s += "=" + node.Value
+	}
+	if len(node.Children) > 0 {
+		var children []string
+		for _, child := range node.Children {
+			children = append(children, flattenTree(child))
+		}
+		s += "{" + strings.Join(children, " ") + "}"
+	}
+	if node.Omitted > 0 {
+		s += fmt.Sprintf("+%v", node.Omitted)
+	}
+	return s
+}
+
+func TestDescribeTree(t *testing.T) {
+	v := &TreeNode{Name: "a", Scores: map[string]int{"x": 1}, Timeout: time.Second}
+	v.Next = v
+
+	expected := `(struct describe.TreeNode)#1{Name(string string)="a" Next(struct describe.TreeNode)#1ref ` +
+		`Scores(map map[string]int){["x"](int int)=1} Timeout(int64 time.Duration)=time.Duration<1s>}`
+	actual := flattenTree(DescribeTree(v))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeTreeLeaves(t *testing.T) {
+	expected := `(invalid )=invalid`
+	actual := flattenTree(DescribeTree(nil))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `(slice []interface {}){[0](int int)=1 [1](ptr *int)=nil}+1`
+	actual = flattenTree(DescribeTree([]interface{}{1, (*int)(nil), 3}, WithMaxElements(2)))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	root := DescribeTree(reflect.ValueOf(struct{ A []int }{}))
+	if root.Kind != reflect.Struct || len(root.Children) != 1 || root.Children[0].Value != "nil" {
+		t.Errorf("Unexpected tree %v", flattenTree(root))
+	}
+}
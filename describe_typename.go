@@ -0,0 +1,189 @@
+package describe
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// How package names are shown in type names.
+type TypeNameStyle int
+
+const (
+	// Use the package name only, as reflect does: `billing.Invoice`
+	TypeNamesPackage TypeNameStyle = iota
+	// Use the full import path: `github.com/acme/shop/internal/billing.Invoice`
+	TypeNamesFull
+	// Use the last two segments of the import path, followed by a short hash
+	// of the full import path so that different packages that abbreviate to
+	// the same thing can be told apart: `…/internal/billing#1f0c4a.Invoice`
+	//
+	// The hashes are the same across builds and runs. They're left out if
+	// WithTypeHashes() is enabled, since the type hashes already tell the
+	// packages apart.
+	TypeNamesAbbreviated
+)
+
+const (
+	tokAbbreviatedPath   = "…/"
+	tokPackageHashPrefix = "#"
+	tokTypeHashPrefix    = "#"
+	abbreviatedPathDepth = 2
+	typeHashDigits       = 6
+)
+
+func (this *describer) typeName(t reflect.Type) string {
+	if this.typeNames == TypeNamesPackage && !this.typeHashes {
+		return getTypeName(t)
+	}
+	return getQualifiedTypeName(t, this.qualifyTypeName)
+}
+
+// Get the name of a named type from a package, according to the type name
+// style.
+func (this *describer) qualifyTypeName(t reflect.Type) string {
+	var name string
+	switch this.typeNames {
+	case TypeNamesFull:
+		// Already unambiguous
+		return fmt.Sprintf("%v.%v", t.PkgPath(), t.Name())
+	case TypeNamesAbbreviated:
+		pkgPath := abbreviatePackagePath(t.PkgPath())
+		if pkgPath != t.PkgPath() && !this.typeHashes {
+			pkgPath += tokPackageHashPrefix + getPackageHash(t.PkgPath())
+		}
+		name = fmt.Sprintf("%v.%v", pkgPath, t.Name())
+	default:
+		name = t.String()
+	}
+	if this.typeHashes {
+		name += tokTypeHashPrefix + getTypeHash(t)
+	}
+	return name
+}
+
+// Get a short hash of a named type's identity (its import path and name),
+// which is the same across builds and runs.
+func getTypeHash(t reflect.Type) string {
+	return getShortHash(t.PkgPath() + "." + t.Name())
+}
+
+// Get a short hash of a package's import path, which is the same across builds
+// and runs.
+func getPackageHash(pkgPath string) string {
+	return getShortHash(pkgPath)
+}
+
+func getShortHash(s string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(s))
+	return fmt.Sprintf("%08x", hash.Sum32())[:typeHashDigits]
+}
+
+// Get a type name, with named types from packages named by qualify.
+func getQualifiedTypeName(t reflect.Type, qualify func(t reflect.Type) string) string {
+	if t == emptyInterfaceType {
+		return tokEmptyInterface
+	}
+
+	if t.Kind() == reflect.Chan {
+		return getChanTypeName(t, getQualifiedTypeName(t.Elem(), qualify))
+	}
+
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		return qualify(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + getQualifiedTypeName(t.Elem(), qualify)
+	case reflect.Slice:
+		return "[]" + getQualifiedTypeName(t.Elem(), qualify)
+	case reflect.Array:
+		return fmt.Sprintf("[%v]%v", t.Len(), getQualifiedTypeName(t.Elem(), qualify))
+	case reflect.Map:
+		return fmt.Sprintf("map[%v]%v", getQualifiedTypeName(t.Key(), qualify), getQualifiedTypeName(t.Elem(), qualify))
+	case reflect.Func:
+		return "func" + getQualifiedSignature(t, qualify)
+	case reflect.Struct:
+		return getQualifiedStructTypeName(t, qualify)
+	case reflect.Interface:
+		return getQualifiedInterfaceTypeName(t, qualify)
+	}
+
+	return getTypeName(t)
+}
+
+// Get a function's signature (without the `func` keyword) in the same form as
+// reflect does: `(int, ...string) (bool, error)`
+func getQualifiedSignature(t reflect.Type, qualify func(t reflect.Type) string) string {
+	in := make([]string, t.NumIn())
+	for i := range in {
+		if t.IsVariadic() && i == len(in)-1 {
+			in[i] = "..." + getQualifiedTypeName(t.In(i).Elem(), qualify)
+		} else {
+			in[i] = getQualifiedTypeName(t.In(i), qualify)
+		}
+	}
+	out := make([]string, t.NumOut())
+	for i := range out {
+		out[i] = getQualifiedTypeName(t.Out(i), qualify)
+	}
+
+	signature := "(" + strings.Join(in, ", ") + ")"
+	switch len(out) {
+	case 0:
+		return signature
+	case 1:
+		return signature + " " + out[0]
+	default:
+		return signature + " (" + strings.Join(out, ", ") + ")"
+	}
+}
+
+// Get a struct literal type's name in the same form as reflect does:
+// `struct { A int; B string "json:\"b\"" }`
+func getQualifiedStructTypeName(t reflect.Type, qualify func(t reflect.Type) string) string {
+	if t.NumField() == 0 {
+		return "struct {}"
+	}
+	fields := make([]string, t.NumField())
+	for i := range fields {
+		field := t.Field(i)
+		fields[i] = getQualifiedTypeName(field.Type, qualify)
+		if !field.Anonymous {
+			fields[i] = field.Name + " " + fields[i]
+		}
+		if field.Tag != "" {
+			fields[i] += " " + strconv.Quote(string(field.Tag))
+		}
+	}
+	return "struct { " + strings.Join(fields, "; ") + " }"
+}
+
+// Get an interface literal type's name in the same form as reflect does:
+// `interface { Read([]uint8) (int, error) }`
+func getQualifiedInterfaceTypeName(t reflect.Type, qualify func(t reflect.Type) string) string {
+	if t.NumMethod() == 0 {
+		return "interface {}"
+	}
+	methods := make([]string, t.NumMethod())
+	for i := range methods {
+		method := t.Method(i)
+		methods[i] = method.Name + getQualifiedSignature(method.Type, qualify)
+	}
+	return "interface { " + strings.Join(methods, "; ") + " }"
+}
+
+func abbreviatePackagePath(pkgPath string) string {
+	segments := strings.Split(pkgPath, "/")
+	if len(segments) <= abbreviatedPathDepth {
+		return pkgPath
+	}
+	return tokAbbreviatedPath + strings.Join(segments[len(segments)-abbreviatedPathDepth:], "/")
+}
@@ -0,0 +1,84 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type taggedUnion struct {
+	discriminator int
+	arms          map[int]bool
+}
+
+// Register a struct type as a tagged union: a discriminator field plus several
+// optional payload fields ("arms"), of which only one is normally set.
+//
+// When describing a tagged union, the discriminator is printed first, and arms
+// that are nil are omitted, so that only the active arm is shown. Example:
+// `api.Event<Type="Created" Created=*api.Created<ID=5>>`
+//
+// If no arms are given, all pointer, interface, map, and slice fields other
+// than the discriminator are treated as arms.
+//
+// Passing an empty discriminator will unregister the type.
+//
+// Note: This function panics if t is not a struct type or if any of the
+// named fields don't exist.
+func SetTaggedUnion(t reflect.Type, discriminator string, arms ...string) {
+	if discriminator == "" {
+		taggedUnions.Delete(t)
+		return
+	}
+
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("%v is not a struct type", t))
+	}
+	getFieldIndex := func(name string) int {
+		field, ok := t.FieldByName(name)
+		if !ok || len(field.Index) != 1 {
+			panic(fmt.Errorf("%v has no field named %v", t, name))
+		}
+		return field.Index[0]
+	}
+
+	union := &taggedUnion{
+		discriminator: getFieldIndex(discriminator),
+		arms:          make(map[int]bool),
+	}
+	for _, arm := range arms {
+		union.arms[getFieldIndex(arm)] = true
+	}
+	if len(arms) == 0 {
+		for i := 0; i < t.NumField(); i++ {
+			switch t.Field(i).Type.Kind() {
+			case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+				if i != union.discriminator {
+					union.arms[i] = true
+				}
+			}
+		}
+	}
+
+	taggedUnions.Store(t, union)
+}
+
+func getTaggedUnion(t reflect.Type) (union *taggedUnion, ok bool) {
+	if stored, isStored := taggedUnions.Load(t); isStored {
+		return stored.(*taggedUnion), true
+	}
+	return nil, false
+}
+
+func (this *taggedUnion) getActiveFieldIndices(v reflect.Value) []int {
+	indices := []int{this.discriminator}
+	for i := 0; i < v.NumField(); i++ {
+		if i == this.discriminator {
+			continue
+		}
+		if this.arms[i] && isNil(v.Field(i)) {
+			continue
+		}
+		indices = append(indices, i)
+	}
+	return indices
+}
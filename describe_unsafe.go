@@ -63,7 +63,15 @@ func canExposeInterface() bool {
 //
 // Do not use this power for evil; it will consume you and everything you love.
 func exposeInterface(v reflect.Value) interface{} {
+	return exposeValue(v).Interface()
+}
+
+// Expose a value obtained via an unexported field, such that it can be passed
+// to custom describers as if it were exported. Addressability is preserved.
+//
+// The same warnings as for exposeInterface() apply.
+func exposeValue(v reflect.Value) reflect.Value {
 	pFlag := (*flag)(unsafe.Pointer(uintptr(unsafe.Pointer(&v)) + flagOffset))
 	*pFlag &= maskFlagRO
-	return v.Interface()
+	return v
 }
@@ -0,0 +1,212 @@
+package describe
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+const (
+	tokDiffSeparator = " != "
+	tokDiffMissing   = "missing"
+)
+
+// Diff compares two values and describes only the paths where they differ, one
+// per line, with the left (a) and right (b) values in regular (single line)
+// notation:
+//
+//	Server.Port: 80 != 8080
+//	Tags[2]: missing != "z"
+//	Env["OLD"]: "1" != missing
+//
+// Paths are built like Go expressions, as with WithAnnotator(). If the
+// top-level values themselves differ, the line has no path.
+//
+// Both values are walked together, following pointers and interfaces, and
+// are safe to compare even if they contain cycles. Struct fields and array
+// elements are matched by position, and map entries by key (in order of their
+// keys). Values of different types, values described by user code (custom
+// describers, String() methods), and everything that isn't a struct, array,
+// slice or map are compared by their descriptions.
+//
+// Returns an empty string if no differences were found.
+func Diff(a, b interface{}, opts ...Option) string {
+	context := describer{}
+	context.applyOptions(opts)
+	rvA, ok := a.(reflect.Value)
+	if !ok {
+		rvA = reflect.ValueOf(a)
+	}
+	rvB, ok := b.(reflect.Value)
+	if !ok {
+		rvB = reflect.ValueOf(b)
+	}
+	return context.diffValues(rvA, rvB)
+}
+
+type diffPair struct {
+	a duplicates.TypedPointer
+	b duplicates.TypedPointer
+}
+
+type valueDiffer struct {
+	describer *describer
+	buffer    bytes.Buffer
+	compared  map[diffPair]bool
+}
+
+func (this *describer) diffValues(a, b reflect.Value) (description string) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				description = notifyRecoveredLibraryBug(e)
+			}
+		}
+	}()
+
+	differ := valueDiffer{
+		describer: this,
+		compared:  make(map[diffPair]bool),
+	}
+	differ.diff(a, b)
+	return differ.buffer.String()
+}
+
+func (this *valueDiffer) writeDifference(a, b string) {
+	if path := this.describer.currentPath(); path != "" {
+		this.buffer.WriteString(path)
+		this.buffer.WriteString(": ")
+	}
+	this.buffer.WriteString(a)
+	this.buffer.WriteString(tokDiffSeparator)
+	this.buffer.WriteString(b)
+	this.buffer.WriteString("\n")
+}
+
+func (this *valueDiffer) diffChild(segment pathSegment, a, b reflect.Value) {
+	this.describer.path = append(this.describer.path, segment)
+	this.diff(a, b)
+	this.describer.path = this.describer.path[:len(this.describer.path)-1]
+}
+
+// Returns true if the contents of a and b should be compared individually.
+func (this *valueDiffer) isComparedByContents(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+	default:
+		return false
+	}
+	return !isNil(a) && !isNil(b) &&
+		!this.describer.isDescribedByUserCode(a) &&
+		!this.describer.isDescribedByUserCode(b)
+}
+
+func (this *valueDiffer) diff(a, b reflect.Value) {
+	for a.IsValid() && b.IsValid() && a.Type() == b.Type() &&
+		(a.Kind() == reflect.Ptr || a.Kind() == reflect.Interface) &&
+		!a.IsNil() && !b.IsNil() &&
+		!this.describer.isDescribedByUserCode(a) &&
+		!this.describer.isDescribedByUserCode(b) {
+		// Chains of pointers and interfaces can lead back to themselves
+		// (such as `x = &x`).
+		ptrA, okA := getReferencePointer(a)
+		ptrB, okB := getReferencePointer(b)
+		if okA && okB {
+			pair := diffPair{ptrA, ptrB}
+			if this.compared[pair] {
+				return
+			}
+			this.compared[pair] = true
+		}
+		a = a.Elem()
+		b = b.Elem()
+	}
+
+	if !this.isComparedByContents(a, b) {
+		descriptionA := this.describer.describeWithUserCode(a)
+		descriptionB := this.describer.describeWithUserCode(b)
+		if descriptionA != descriptionB {
+			this.writeDifference(descriptionA, descriptionB)
+		}
+		return
+	}
+
+	// Data that's reachable via multiple paths (or cycles) only needs to be
+	// compared once.
+	ptrA, okA := getReferencePointer(a)
+	ptrB, okB := getReferencePointer(b)
+	if okA && okB {
+		pair := diffPair{ptrA, ptrB}
+		if this.compared[pair] {
+			return
+		}
+		this.compared[pair] = true
+	}
+
+	switch a.Kind() {
+	case reflect.Struct:
+		for _, i := range getVisibleFieldIndices(a) {
+			this.diffChild(fieldSegment(a.Type().Field(i).Name), this.describer.getDescribedField(a, i), this.describer.getDescribedField(b, i))
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			switch {
+			case i >= a.Len():
+				this.diffMissing(indexSegment(i), reflect.Value{}, b.Index(i))
+			case i >= b.Len():
+				this.diffMissing(indexSegment(i), a.Index(i), reflect.Value{})
+			default:
+				this.diffChild(indexSegment(i), a.Index(i), b.Index(i))
+			}
+		}
+	case reflect.Map:
+		// Keys that can't be looked up (such as NaN) are only on one side.
+		var keys, valuesA, valuesB []reflect.Value
+		iter := mapRange(a)
+		for iter.Next() {
+			keys = append(keys, iter.Key())
+			valuesA = append(valuesA, iter.Value())
+			valuesB = append(valuesB, b.MapIndex(iter.Key()))
+		}
+		iter = mapRange(b)
+		for iter.Next() {
+			if !a.MapIndex(iter.Key()).IsValid() {
+				keys = append(keys, iter.Key())
+				valuesA = append(valuesA, reflect.Value{})
+				valuesB = append(valuesB, iter.Value())
+			}
+		}
+		for i := range keys {
+			valuesA[i] = this.describer.getDescribedMapValue(keys[i], valuesA[i])
+			valuesB[i] = this.describer.getDescribedMapValue(keys[i], valuesB[i])
+		}
+		for _, i := range getSortedIndices(keys, nil) {
+			if valuesA[i].IsValid() && valuesB[i].IsValid() {
+				this.diffChild(keySegment(keys[i]), valuesA[i], valuesB[i])
+			} else {
+				this.diffMissing(keySegment(keys[i]), valuesA[i], valuesB[i])
+			}
+		}
+	}
+}
+
+// Record an element or entry that's only present on one side (the other
+// being an invalid value).
+func (this *valueDiffer) diffMissing(segment pathSegment, a, b reflect.Value) {
+	descriptionA := tokDiffMissing
+	if a.IsValid() {
+		descriptionA = this.describer.describeWithUserCode(a)
+	}
+	descriptionB := tokDiffMissing
+	if b.IsValid() {
+		descriptionB = this.describer.describeWithUserCode(b)
+	}
+	this.describer.path = append(this.describer.path, segment)
+	this.writeDifference(descriptionA, descriptionB)
+	this.describer.path = this.describer.path[:len(this.describer.path)-1]
+}
@@ -0,0 +1,90 @@
+package describe
+
+import (
+	"math"
+	"testing"
+)
+
+type DiffServer struct {
+	Host string
+	Port int
+}
+
+type DiffConfig struct {
+	Name   string
+	Server *DiffServer
+	Tags   []string
+	Env    map[string]string
+	Next   *DiffConfig
+}
+
+func assertDiff(t *testing.T, a, b interface{}, expected string) {
+	actual := Diff(a, b)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := &DiffConfig{
+		Name:   "a",
+		Server: &DiffServer{Host: "localhost", Port: 80},
+		Tags:   []string{"x", "y"},
+		Env:    map[string]string{"OLD": "1", "SAME": "2"},
+	}
+	b := &DiffConfig{
+		Name:   "a",
+		Server: &DiffServer{Host: "localhost", Port: 8080},
+		Tags:   []string{"x", "y", "z"},
+		Env:    map[string]string{"NEW": "3", "SAME": "2"},
+	}
+	assertDiff(t, a, b, `Server.Port: 80 != 8080
+Tags[2]: missing != "z"
+Env["NEW"]: missing != "3"
+Env["OLD"]: "1" != missing
+`)
+	assertDiff(t, a, a, "")
+}
+
+func TestDiffCycles(t *testing.T) {
+	a := &DiffConfig{Name: "a"}
+	a.Next = a
+	b := &DiffConfig{Name: "a"}
+	b.Next = &DiffConfig{Name: "b"}
+	b.Next.Next = b
+	assertDiff(t, a, b, `Next.Name: "a" != "b"
+`)
+}
+
+func TestDiffTypes(t *testing.T) {
+	assertDiff(t, 1, 2, "1 != 2\n")
+	assertDiff(t, nil, 1, "invalid != 1\n")
+	assertDiff(t, []interface{}{1, "1"}, []interface{}{"1", 1}, `[0]: 1 != "1"
+[1]: "1" != 1
+`)
+	assertDiff(t, &DiffServer{}, (*DiffServer)(nil), "*describe.DiffServer<Host=\"\" Port=0> != nil\n")
+	assertDiff(t, map[float64]int{math.NaN(): 1}, map[float64]int{}, "[NaN]: 1 != missing\n")
+}
+
+func TestDiffHostileValues(t *testing.T) {
+	oldDebugPanics := DebugPanics
+	DebugPanics = false
+	defer func() { DebugPanics = oldDebugPanics }()
+
+	var bugs []string
+	oldHandler := LibraryBugHandler
+	LibraryBugHandler = func(description string) {
+		bugs = append(bugs, description)
+	}
+	defer func() { LibraryBugHandler = oldHandler }()
+
+	values := getHostileValues()
+	for i, value := range values {
+		Diff(value, value)
+		Diff(value, values[(i+1)%len(values)])
+		if len(bugs) > 0 {
+			t.Errorf("Diff hit a library bug on value %v: %v", i, bugs)
+			bugs = nil
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package describe
+
+import (
+	"reflect"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Called by Walk() for each value. Return false to skip the value's contents.
+type WalkFunc func(path string, v reflect.Value, depth int) bool
+
+// Walk an object graph, calling fn for the top-level value (with an empty
+// path and a depth of 0) and for every struct field, array/slice element and
+// map value below it, for implementing custom scans (such as finding all nil
+// pointers, or collecting all strings) without having to deal with cycles.
+//
+// Paths are built like Go expressions, as with WithAnnotator():
+// `Config.Servers[2].Ports["http"]`
+//
+// Pointers and interfaces are followed transparently: fn is called with the
+// pointer or interface, and the fields or elements of what it points to are
+// walked beneath it. Data that's reachable via multiple paths (including via
+// cycles) is passed to fn for every path, but its contents are only walked the
+// first time. Map entries are walked in order of their keys.
+//
+// Values in unexported fields can be examined, but not extracted using
+// Interface().
+func Walk(v interface{}, fn WalkFunc) {
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	walkValue(rv, options{}, fn)
+}
+
+// Walk v, describing map keys in paths using keyOptions.
+func walkValue(v reflect.Value, keyOptions options, fn WalkFunc) {
+	walker := walker{
+		visit:      fn,
+		keyOptions: keyOptions,
+		visited:    make(map[duplicates.TypedPointer]bool),
+	}
+	walker.walk(v, 0)
+}
+
+type walker struct {
+	visit      WalkFunc
+	keyOptions options
+	path       []pathSegment
+	visited    map[duplicates.TypedPointer]bool
+}
+
+func (this *walker) walkChild(segment pathSegment, v reflect.Value, depth int) {
+	this.path = append(this.path, segment)
+	this.walk(v, depth)
+	this.path = this.path[:len(this.path)-1]
+}
+
+func (this *walker) walk(v reflect.Value, depth int) {
+	if !this.visit(buildPath(this.path, &this.keyOptions), v, depth) {
+		return
+	}
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		if v.Type().Implements(reflectTypeType) {
+			break
+		}
+		// Chains of pointers and interfaces can lead back to themselves
+		// (such as `x = &x`).
+		if ptr, ok := getReferencePointer(v); ok {
+			if this.visited[ptr] {
+				return
+			}
+			this.visited[ptr] = true
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Type() == reflectValueType || v.Type().Implements(reflectTypeType) {
+		// Don't wander into reflection internals.
+		return
+	}
+	if ptr, ok := getReferencePointer(v); ok {
+		if this.visited[ptr] {
+			return
+		}
+		this.visited[ptr] = true
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			this.walkChild(fieldSegment(v.Type().Field(i).Name), v.Field(i), depth+1)
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			this.walkChild(indexSegment(i), v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sortMapKeys(keys, WithReflectionOnly(this.keyOptions.reflectionOnly))
+		for _, key := range keys {
+			this.walkChild(keySegment(key), v.MapIndex(key), depth+1)
+		}
+	}
+}
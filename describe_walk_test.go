@@ -0,0 +1,62 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type WalkNode struct {
+	Name  string
+	Next  *WalkNode
+	Tags  []string
+	Attrs map[string]interface{}
+}
+
+func TestWalk(t *testing.T) {
+	v := &WalkNode{Name: "a", Tags: []string{"x"}, Attrs: map[string]interface{}{"b": 1, "a": nil}}
+	v.Next = v
+
+	var visited []string
+	Walk(v, func(path string, v reflect.Value, depth int) bool {
+		visited = append(visited, fmt.Sprintf("%v:%v:%v", depth, path, v.Kind()))
+		return true
+	})
+	expected := `0::ptr 1:Name:string 1:Next:ptr 1:Tags:slice 2:Tags[0]:string 1:Attrs:map 2:Attrs["a"]:interface 2:Attrs["b"]:interface`
+	actual := strings.Join(visited, " ")
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestWalkFindNils(t *testing.T) {
+	v := []*WalkNode{{Name: "a"}, nil}
+
+	var nils []string
+	Walk(v, func(path string, v reflect.Value, depth int) bool {
+		if isNil(v) {
+			nils = append(nils, path)
+		}
+		// Skip the contents of tags.
+		return !strings.HasSuffix(path, ".Tags")
+	})
+	expected := `[0].Next [0].Tags [0].Attrs [1]`
+	actual := strings.Join(nils, " ")
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestWalkHostileValues(t *testing.T) {
+	for i, value := range getHostileValues() {
+		count := 0
+		Walk(value, func(path string, v reflect.Value, depth int) bool {
+			count++
+			return true
+		})
+		if count == 0 {
+			t.Errorf("Walk didn't visit value %v", i)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Returns the current state of each worker in a worker pool (as a slice or
+// array, such as []*WorkerState). See RegisterWorkerPool().
+type WorkerStates func() interface{}
+
+var workerPools sync.Map
+
+// Register a worker pool (or errgroup, or any other group of goroutines) under
+// name, so that DescribeWorkerPools() can show what its workers are doing.
+//
+// workerStates will be called each time the pools are described, and should
+// return the per-worker state objects. They are described as a table (see
+// DescribeTable()), so structs with a field per piece of state work best.
+//
+// Passing a nil workerStates will unregister the pool.
+func RegisterWorkerPool(name string, workerStates WorkerStates) {
+	if workerStates == nil {
+		workerPools.Delete(name)
+		return
+	}
+	workerPools.Store(name, workerStates)
+}
+
+// Describes the workers of every registered worker pool (see
+// RegisterWorkerPool()) as a table per pool, in name order:
+//
+//	downloads (2 workers):
+//	#  ID  State      Task
+//	0  1   "idle"     nil
+//	1  2   "running"  *main.Task<URL="http://x.com">
+//
+// This is meant for debug endpoints and signal handlers that answer "what are
+// my workers doing?"
+func DescribeWorkerPools(opts ...Option) string {
+	var names []string
+	workerPools.Range(func(key, value interface{}) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	sort.Strings(names)
+
+	var sections []string
+	for _, name := range names {
+		value, ok := workerPools.Load(name)
+		if !ok {
+			continue
+		}
+		sections = append(sections, describeWorkerPool(name, value.(WorkerStates), opts))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func describeWorkerPool(name string, workerStates WorkerStates, opts []Option) (description string) {
+	defer func() {
+		// The pool's code might panic.
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				description = fmt.Sprintf("%v: panic(%v)", name, e)
+			}
+		}
+	}()
+
+	states := reflect.ValueOf(workerStates())
+	workerCount := 1
+	switch unwrapInterface(states).Kind() {
+	case reflect.Array, reflect.Slice:
+		workerCount = unwrapInterface(states).Len()
+	}
+	return fmt.Sprintf("%v (%v workers):\n%v", name, workerCount, DescribeTable(states, opts...))
+}
@@ -0,0 +1,231 @@
+package describe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kstenerud/go-duplicates"
+)
+
+// Describes an object as a YAML document with the same structure as
+// DescribeOpts() in multiline mode, for pasting into config-diff tools and
+// review comments:
+//
+//	&1 # main.Node
+//	Name: "a"
+//	Next: *1
+//	Tags: # []string
+//	  - "x"
+//	Scores: # map[string]float64
+//	  "x": 1.5
+//
+// Types are written as comments, and data that occurs more than once is
+// marked using YAML anchors (`&1`) and aliases (`*1`). Values described by
+// user code (custom describers, String() methods) become strings, and map keys
+// that aren't scalars are replaced with their descriptions.
+//
+// The document is indented as per WithIndent(), or 2 spaces by default.
+func DescribeYAML(v interface{}, opts ...Option) (description string) {
+	context := describer{}
+	context.applyOptions(opts)
+	if context.indentStep <= 0 {
+		context.indentStep = 2
+	}
+	rv, ok := v.(reflect.Value)
+	if !ok {
+		rv = reflect.ValueOf(v)
+	}
+	return context.describeYAML(rv)
+}
+
+func (this *describer) describeYAML(rv reflect.Value) (description string) {
+	defer func() {
+		// Allow panic to escape if debugging
+		if !DebugPanics {
+			if e := recover(); e != nil {
+				description = jsonQuote(notifyRecoveredLibraryBug(e))
+			}
+		}
+	}()
+
+	this.referenceNames = this.findDuplicates(rv)
+	this.seenReferences = make(map[duplicates.TypedPointer]bool)
+	this.describeYAMLNode(rv, -1)
+	return strings.TrimSuffix(this.stringBuilder.String(), "\n")
+}
+
+// Describe a YAML node, continuing the current line (after `key:` or `- `,
+// or at the start of the document if level is -1). Collections are continued
+// on the following lines, at the next level of indentation.
+func (this *describer) describeYAMLNode(v reflect.Value, level int) {
+	v = followPointers(v, this.isDescribedByUserCode)
+
+	if !v.IsValid() || isNil(v) {
+		this.writeYAMLScalar(level, "null", "")
+		return
+	}
+	if this.isDescribedByUserCode(v) {
+		this.writeYAMLScalar(level, jsonQuote(this.describeWithUserCode(v)), this.typeName(v.Type()))
+		return
+	}
+	if scalar, ok := this.getYAMLScalar(v); ok {
+		this.writeYAMLScalar(level, scalar, "")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		this.describeYAMLStruct(v, level)
+	case reflect.Array, reflect.Slice:
+		this.describeYAMLSequence(v, level)
+	case reflect.Map:
+		this.describeYAMLMap(v, level)
+	default:
+		this.writeYAMLScalar(level, "{}", this.typeName(v.Type()))
+	}
+}
+
+// Get v's representation if it's a YAML scalar.
+func (this *describer) getYAMLScalar(v reflect.Value) (scalar string, ok bool) {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		value := v.Float()
+		switch {
+		case math.IsNaN(value):
+			return ".nan", true
+		case math.IsInf(value, 1):
+			return ".inf", true
+		case math.IsInf(value, -1):
+			return "-.inf", true
+		}
+		return strconv.FormatFloat(value, 'g', -1, v.Type().Bits()), true
+	case reflect.Complex64, reflect.Complex128:
+		return jsonQuote(fmt.Sprintf("%v", v.Complex())), true
+	case reflect.String:
+		// JSON strings are valid double-quoted YAML scalars.
+		return jsonQuote(v.String()), true
+	case reflect.UnsafePointer:
+		return jsonQuote(stringifyAddress(uint64(v.Pointer()))), true
+	}
+	return
+}
+
+// Write a scalar (plus an optional type comment), ending the current line.
+func (this *describer) writeYAMLScalar(level int, scalar string, typeName string) {
+	if level >= 0 {
+		this.writeString(" ")
+	}
+	this.writeString(scalar)
+	if typeName != "" {
+		this.writeString(" # ")
+		this.writeString(typeName)
+	}
+	this.writeString("\n")
+}
+
+// Write the rest of a collection's first line: its anchor (if it's referenced
+// elsewhere) and type comment, or `emptyForm` if it has no contents. Returns
+// false if there are no contents to write, or if v was already described (in
+// which case an alias has been written in its place).
+func (this *describer) writeYAMLCollectionHeader(v reflect.Value, level int, isEmpty bool, emptyForm string) (shouldWriteContents bool) {
+	anchor := ""
+	if ptr, ok := getReferencePointer(v); ok {
+		if referenceName, isReferenced := this.referenceNames[ptr]; isReferenced {
+			if this.seenReferences[ptr] {
+				this.writeYAMLScalar(level, fmt.Sprintf("*%v", referenceName), "")
+				return false
+			}
+			this.seenReferences[ptr] = true
+			anchor = fmt.Sprintf("&%v", this.assignReferenceName(ptr))
+		}
+	}
+	if isEmpty {
+		if anchor != "" {
+			emptyForm = anchor + " " + emptyForm
+		}
+		this.writeYAMLScalar(level, emptyForm, this.typeName(v.Type()))
+		return false
+	}
+	if level >= 0 {
+		this.writeString(" ")
+	}
+	if anchor != "" {
+		this.writeString(anchor)
+		this.writeString(" ")
+	}
+	this.writeString("# ")
+	this.writeString(this.typeName(v.Type()))
+	this.writeString("\n")
+	return true
+}
+
+func (this *describer) writeYAMLIndent(level int) {
+	this.writeString(strings.Repeat(" ", level*this.indentStep))
+}
+
+func (this *describer) describeYAMLStruct(v reflect.Value, level int) {
+	indices := getVisibleFieldIndices(v)
+	if !this.writeYAMLCollectionHeader(v, level, len(indices) == 0, "{}") {
+		return
+	}
+	for _, i := range indices {
+		this.writeYAMLIndent(level + 1)
+		this.writeString(getFieldName(v.Type(), i))
+		this.writeString(":")
+		this.describeYAMLNode(this.getDescribedField(v, i), level+1)
+	}
+}
+
+func (this *describer) describeYAMLSequence(v reflect.Value, level int) {
+	if !this.writeYAMLCollectionHeader(v, level, v.Len() == 0, "[]") {
+		return
+	}
+	count := this.getElementCountToDescribe(v.Len())
+	for i := 0; i < count; i++ {
+		this.writeYAMLIndent(level + 1)
+		this.writeString("-")
+		this.describeYAMLNode(v.Index(i), level+1)
+	}
+	this.writeYAMLOmitted(v.Len()-count, tokMoreElements, level+1)
+}
+
+func (this *describer) describeYAMLMap(v reflect.Value, level int) {
+	if !this.writeYAMLCollectionHeader(v, level, v.Len() == 0, "{}") {
+		return
+	}
+	iter, omittedCount := this.iterateMap(v)
+	for iter.Next() {
+		this.writeYAMLIndent(level + 1)
+		this.writeString(this.getYAMLKey(iter.Key()))
+		this.writeString(":")
+		this.describeYAMLNode(this.getDescribedMapValue(iter.Key(), iter.Value()), level+1)
+	}
+	this.writeYAMLOmitted(omittedCount, tokMoreEntries, level+1)
+}
+
+// Get a map key as a scalar, or as a quoted description if it's not one.
+func (this *describer) getYAMLKey(v reflect.Value) string {
+	v = unwrapInterface(v)
+	if v.IsValid() && !this.isDescribedByUserCode(v) {
+		if scalar, ok := this.getYAMLScalar(v); ok {
+			return scalar
+		}
+	}
+	return jsonQuote(this.describeWithUserCode(v))
+}
+
+func (this *describer) writeYAMLOmitted(count int, items string, level int) {
+	if count > 0 {
+		this.writeYAMLIndent(level)
+		this.writeFmt("# %v(+%v more %v)\n", tokElided, count, items)
+	}
+}
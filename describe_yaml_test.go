@@ -0,0 +1,63 @@
+package describe
+
+import (
+	"testing"
+	"time"
+)
+
+type YAMLNode struct {
+	Name    string
+	next    *YAMLNode
+	Values  []int
+	Scores  map[string]float64
+	Timeout time.Duration
+	Empty   []string
+	Any     interface{}
+}
+
+func TestDescribeYAML(t *testing.T) {
+	v := &YAMLNode{
+		Name:    "a",
+		Values:  []int{1, 2},
+		Scores:  map[string]float64{"x": 1.5},
+		Timeout: time.Second,
+		Empty:   []string{},
+	}
+	v.next = v
+
+	expected := `&1 # describe.YAMLNode
+Name: "a"
+next: *1
+Values: # []int
+  - 1
+  - 2
+Scores: # map[string]float64
+  "x": 1.5
+Timeout: "time.Duration<1s>" # time.Duration
+Empty: [] # []string
+Any: null`
+	actual := DescribeYAML(v)
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}
+
+func TestDescribeYAMLOptions(t *testing.T) {
+	expected := "# [][]int\n- # []int\n    - 1\n# …(+1 more elements)"
+	actual := DescribeYAML([][]int{{1}, {2, 3}}, WithIndent(4), WithMaxElements(1))
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = "# map[[2]int]bool\n\"int[1 2]\": true"
+	actual = DescribeYAML(map[[2]int]bool{{1, 2}: true})
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	expected = `"hello"`
+	actual = DescribeYAML("hello")
+	if actual != expected {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+}